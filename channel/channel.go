@@ -9,6 +9,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/statechannels/go-nitro/channel/state"
 	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/crypto"
 	"github.com/statechannels/go-nitro/types"
 )
 
@@ -192,6 +193,14 @@ func (c Channel) LatestSupportedState() (state.State, error) {
 	return c.SignedStateForTurnNum[c.latestSupportedStateTurnNum].State(), nil
 }
 
+// LatestSupportedSignedState returns the latest supported state, together with the signatures of all participants.
+func (c Channel) LatestSupportedSignedState() (state.SignedState, error) {
+	if c.latestSupportedStateTurnNum == MaxTurnNum {
+		return state.SignedState{}, errors.New(`no state is yet supported`)
+	}
+	return c.SignedStateForTurnNum[c.latestSupportedStateTurnNum], nil
+}
+
 // LatestSignedState fetches the state with the largest turn number signed by at least one participant.
 func (c Channel) LatestSignedState() (state.SignedState, error) {
 	if len(c.SignedStateForTurnNum) == 0 {
@@ -272,19 +281,19 @@ func (c *Channel) AddSignedState(ss state.SignedState) bool {
 }
 
 // SignAndAddPrefund signs and adds the prefund state for the channel, returning a state.SignedState suitable for sending to peers.
-func (c *Channel) SignAndAddPrefund(sk *[]byte) (state.SignedState, error) {
-	return c.SignAndAddState(c.PreFundState(), sk)
+func (c *Channel) SignAndAddPrefund(signer crypto.Signer) (state.SignedState, error) {
+	return c.SignAndAddState(c.PreFundState(), signer)
 }
 
 // SignAndAddPrefund signs and adds the postfund state for the channel, returning a state.SignedState suitable for sending to peers.
-func (c *Channel) SignAndAddPostfund(sk *[]byte) (state.SignedState, error) {
-	return c.SignAndAddState(c.PostFundState(), sk)
+func (c *Channel) SignAndAddPostfund(signer crypto.Signer) (state.SignedState, error) {
+	return c.SignAndAddState(c.PostFundState(), signer)
 }
 
 // SignAndAddState signs and adds the state to the channel, returning a state.SignedState suitable for sending to peers.
-func (c *Channel) SignAndAddState(s state.State, sk *[]byte) (state.SignedState, error) {
+func (c *Channel) SignAndAddState(s state.State, signer crypto.Signer) (state.SignedState, error) {
 
-	sig, err := s.Sign(*sk)
+	sig, err := s.SignWith(signer)
 	if err != nil {
 		return state.SignedState{}, fmt.Errorf("could not sign prefund %w", err)
 	}
@@ -227,15 +227,14 @@ func (c *ConsensusChannel) Accept(p SignedProposal) error {
 }
 
 // sign constructs a state.State from the given vars, using the ConsensusChannel's constant
-// values. It signs the resulting state using sk.
-func (c *ConsensusChannel) sign(vars Vars, sk []byte) (state.Signature, error) {
-	signer := crypto.GetAddressFromSecretKeyBytes(sk)
-	if c.fp.Participants[c.MyIndex] != signer {
-		return state.Signature{}, fmt.Errorf("attempting to sign from wrong address: %s", signer)
+// values. It signs the resulting state using signer.
+func (c *ConsensusChannel) sign(vars Vars, signer crypto.Signer) (state.Signature, error) {
+	if c.fp.Participants[c.MyIndex] != signer.Address() {
+		return state.Signature{}, fmt.Errorf("attempting to sign from wrong address: %s", signer.Address())
 	}
 
 	state := vars.AsState(c.fp)
-	return state.Sign(sk)
+	return state.SignWith(signer)
 }
 
 // recoverSigner returns the signer of the vars using the given signature.
@@ -167,7 +167,7 @@ func TestConsensusChannel(t *testing.T) {
 			t.Fatalf("unable to construct a new consensus channel: %v", err)
 		}
 
-		_, err = channel.sign(initialVars, bob.PrivateKey)
+		_, err = channel.sign(initialVars, bob.Signer())
 		if err == nil {
 			t.Fatalf("channel should check that signer is participant")
 		}
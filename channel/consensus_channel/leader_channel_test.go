@@ -119,7 +119,7 @@ func TestLeaderChannel(t *testing.T) {
 			latest, _ := channel.latestProposedVars()
 			latestTurnNum := latest.TurnNum
 
-			sp, err := channel.Propose(proposal, alice.PrivateKey)
+			sp, err := channel.Propose(proposal, alice.Signer())
 
 			if err != nil {
 				if expectedErr == nil {
@@ -469,7 +469,7 @@ func TestRestrictedFollowerMethods(t *testing.T) {
 
 	channel, _ := NewLeaderChannel(fp(), 0, ledgerOutcome(), sigs)
 
-	if _, err := channel.SignNextProposal(Proposal{}, alice.PrivateKey); err != ErrNotFollower {
+	if _, err := channel.SignNextProposal(Proposal{}, alice.Signer()); err != ErrNotFollower {
 		t.Errorf("Expected error when calling SignNextProposal as a leader, but found none")
 	}
 
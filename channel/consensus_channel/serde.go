@@ -6,6 +6,7 @@ import (
 	"math/big"
 
 	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/internal/wire"
 	"github.com/statechannels/go-nitro/types"
 )
 
@@ -244,3 +245,47 @@ func (c *ConsensusChannel) UnmarshalJSON(data []byte) error {
 
 	return nil
 }
+
+// MarshalBinary encodes the SignedProposal in go-nitro's compact binary format
+func (sp SignedProposal) MarshalBinary() ([]byte, error) {
+	w := wire.Writer{}
+	w.Signature(sp.Signature)
+	w.Destination(sp.Proposal.LedgerID)
+
+	g := sp.Proposal.ToAdd.Guarantee
+	w.BigInt(g.amount)
+	w.Destination(g.target)
+	w.Destination(g.left)
+	w.Destination(g.right)
+	w.BigInt(sp.Proposal.ToAdd.LeftDeposit)
+
+	w.Destination(sp.Proposal.ToRemove.Target)
+	w.BigInt(sp.Proposal.ToRemove.LeftAmount)
+
+	w.Uvarint(sp.TurnNum)
+	return w.Data(), nil
+}
+
+// UnmarshalBinary populates the receiver with the
+// binary-encoded data
+func (sp *SignedProposal) UnmarshalBinary(data []byte) error {
+	r := wire.NewReader(data)
+	sp.Signature = r.Signature()
+	sp.Proposal.LedgerID = r.Destination()
+
+	g := &sp.Proposal.ToAdd.Guarantee
+	g.amount = r.BigInt()
+	g.target = r.Destination()
+	g.left = r.Destination()
+	g.right = r.Destination()
+	sp.Proposal.ToAdd.LeftDeposit = r.BigInt()
+
+	sp.Proposal.ToRemove.Target = r.Destination()
+	sp.Proposal.ToRemove.LeftAmount = r.BigInt()
+
+	sp.TurnNum = r.Uvarint()
+	if err := r.Done(); err != nil {
+		return fmt.Errorf("error unmarshaling signed proposal data: %w", err)
+	}
+	return nil
+}
@@ -4,8 +4,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 
+	"github.com/statechannels/go-nitro/channel/state/outcome"
 	"github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/internal/wire"
 	"github.com/statechannels/go-nitro/types"
 )
 
@@ -152,6 +155,112 @@ func (ss *SignedState) UnmarshalJSON(j []byte) error {
 
 }
 
+// MarshalBinary encodes the SignedState in go-nitro's compact binary format, implementing the encoding.BinaryMarshaler interface.
+func (ss SignedState) MarshalBinary() ([]byte, error) {
+	w := wire.Writer{}
+	writeState(&w, ss.state)
+
+	indices := make([]uint, 0, len(ss.sigs))
+	for i := range ss.sigs {
+		indices = append(indices, i)
+	}
+	sort.Slice(indices, func(a, b int) bool { return indices[a] < indices[b] })
+
+	w.Len(len(indices), ss.sigs == nil)
+	for _, i := range indices {
+		w.Uvarint(uint64(i))
+		w.Signature(ss.sigs[i])
+	}
+	return w.Data(), nil
+}
+
+// UnmarshalBinary decodes a SignedState encoded by MarshalBinary, implementing the encoding.BinaryUnmarshaler interface.
+// As with UnmarshalJSON, the signatures are not verified.
+func (ss *SignedState) UnmarshalBinary(data []byte) error {
+	r := wire.NewReader(data)
+	ss.state = readState(r)
+
+	n, isNil := r.Len()
+	ss.sigs = nil
+	if !isNil {
+		ss.sigs = make(map[uint]Signature, n)
+	}
+	for i := 0; i < n; i++ {
+		index := uint(r.Uvarint())
+		ss.sigs[index] = r.Signature()
+	}
+	return r.Done()
+}
+
+func writeState(w *wire.Writer, s State) {
+	w.BigInt(s.ChainId)
+	w.Len(len(s.Participants), s.Participants == nil)
+	for _, p := range s.Participants {
+		w.Address(p)
+	}
+	w.Uvarint(s.ChannelNonce)
+	w.Address(s.AppDefinition)
+	w.Uvarint(uint64(s.ChallengeDuration))
+	w.Bytes(s.AppData)
+
+	w.Len(len(s.Outcome), s.Outcome == nil)
+	for _, sae := range s.Outcome {
+		w.Address(sae.Asset)
+		w.Bytes(sae.Metadata)
+		w.Len(len(sae.Allocations), sae.Allocations == nil)
+		for _, a := range sae.Allocations {
+			w.Destination(a.Destination)
+			w.BigInt(a.Amount)
+			w.Byte(byte(a.AllocationType))
+			w.Bytes(a.Metadata)
+		}
+	}
+
+	w.Uvarint(s.TurnNum)
+	w.Bool(s.IsFinal)
+}
+
+func readState(r *wire.Reader) State {
+	s := State{}
+	s.ChainId = r.BigInt()
+	n, isNil := r.Len()
+	if !isNil {
+		s.Participants = make([]types.Address, n)
+	}
+	for i := range s.Participants {
+		s.Participants[i] = r.Address()
+	}
+	s.ChannelNonce = r.Uvarint()
+	s.AppDefinition = r.Address()
+	s.ChallengeDuration = uint32(r.Uvarint())
+	s.AppData = r.Bytes()
+
+	n, isNil = r.Len()
+	if !isNil {
+		s.Outcome = make(outcome.Exit, n)
+	}
+	for i := range s.Outcome {
+		sae := &s.Outcome[i]
+		sae.Asset = r.Address()
+		sae.Metadata = r.Bytes()
+		m, isNil := r.Len()
+		if !isNil {
+			sae.Allocations = make(outcome.Allocations, m)
+		}
+		for j := range sae.Allocations {
+			a := &sae.Allocations[j]
+			a.Destination = r.Destination()
+			a.Amount = r.BigInt()
+			a.AllocationType = outcome.AllocationType(r.Byte())
+			a.Metadata = r.Bytes()
+		}
+	}
+
+	s.TurnNum = r.Uvarint()
+	s.IsFinal = r.Bool()
+	return s
+}
+
 // ChannelId returns the channel id of the state.
 func (ss SignedState) ChannelId() types.Destination {
 	cId := ss.state.ChannelId()
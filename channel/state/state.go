@@ -141,6 +141,15 @@ func (s State) Sign(secretKey []byte) (Signature, error) {
 	return nc.SignEthereumMessage(hash.Bytes(), secretKey)
 }
 
+// SignWith generates an ECDSA signature on the state using the supplied signer.
+func (s State) SignWith(signer nc.Signer) (Signature, error) {
+	hash, err := s.Hash()
+	if err != nil {
+		return Signature{}, err
+	}
+	return signer.SignStateHash(hash)
+}
+
 // RecoverSigner computes the Ethereum address which generated Signature sig on State state
 func (s State) RecoverSigner(sig Signature) (types.Address, error) {
 	stateHash, error := s.Hash()
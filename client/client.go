@@ -25,12 +25,27 @@ type Client struct {
 	engine              engine.Engine // The core business logic of the client
 	Address             *types.Address
 	completedObjectives chan protocols.ObjectiveId
-	failedObjectives    chan protocols.ObjectiveId
+	failedObjectives    chan engine.FailedObjective
 	receivedVouchers    chan payments.Voucher
 }
 
 // New is the constructor for a Client. It accepts a messaging service, a chain service, and a store as injected dependencies.
-func New(messageService messageservice.MessageService, chainservice chainservice.ChainService, store store.Store, logDestination io.Writer, policymaker engine.PolicyMaker, metricsApi engine.MetricsApi) Client {
+func New(messageService messageservice.MessageService, chain chainservice.ChainService, store store.Store, logDestination io.Writer, policymaker engine.PolicyMaker, metricsApi engine.MetricsApi) Client {
+	return NewWithOptions(messageService, chain, store, logDestination, policymaker, metricsApi, engine.Options{})
+}
+
+// NewWithOptions is the constructor for a Client whose engine is configured by opts, for example with deadlines for objectives
+// that stop making progress, or limits on the messages and objectives each peer may send.
+func NewWithOptions(messageService messageservice.MessageService, chain chainservice.ChainService, store store.Store, logDestination io.Writer, policymaker engine.PolicyMaker, metricsApi engine.MetricsApi, opts engine.Options) Client {
+	return NewMultiChain(messageService, []chainservice.ChainService{chain}, store, logDestination, policymaker, metricsApi, opts)
+}
+
+// NewMultiChain is the constructor for a Client which holds channels on several chains, with one chain service per chain.
+// Transactions and events are routed by chain id to the chain service of the channel's chain.
+// The first chain service's chain is the default chain, on which CreateLedgerChannel and CreateVirtualPaymentChannel create channels.
+// Chain services should be constructed with the same store (see chainservice.EthChainServiceOptions.Store), so that adjudicator
+// events emitted while the node was down are replayed when it restarts.
+func NewMultiChain(messageService messageservice.MessageService, chainservices []chainservice.ChainService, store store.Store, logDestination io.Writer, policymaker engine.PolicyMaker, metricsApi engine.MetricsApi, opts engine.Options) Client {
 	c := Client{}
 	c.Address = store.GetAddress()
 	// If a metrics API is not provided we used the no-op version which does nothing.
@@ -38,9 +53,17 @@ func New(messageService messageservice.MessageService, chainservice chainservice
 		metricsApi = &engine.NoOpMetrics{}
 	}
 
-	c.engine = engine.New(messageService, chainservice, store, logDestination, policymaker, metricsApi)
+	// Messages which have not been delivered are persisted alongside the rest of the client's state, so that they survive a restart
+	if persistent, ok := messageService.(messageservice.PersistentMessageService); ok {
+		err := persistent.PersistOutboxes(store)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	c.engine = engine.New(messageService, chainservices, store, logDestination, policymaker, metricsApi, opts)
 	c.completedObjectives = make(chan protocols.ObjectiveId, 100)
-	c.failedObjectives = make(chan protocols.ObjectiveId, 100)
+	c.failedObjectives = make(chan engine.FailedObjective, 100)
 	// Using a larger buffer since payments can be sent frequently.
 	c.receivedVouchers = make(chan payments.Voucher, 1000)
 	// Start the engine in a go routine
@@ -83,8 +106,8 @@ func (c *Client) CompletedObjectives() <-chan protocols.ObjectiveId {
 	return c.completedObjectives
 }
 
-// FailedObjectives returns a chan that receives an objective id whenever that objective has failed
-func (c *Client) FailedObjectives() <-chan protocols.ObjectiveId {
+// FailedObjectives returns a chan that receives a FailedObjective (containing the objective id and the reason for failure) whenever an objective has failed
+func (c *Client) FailedObjectives() <-chan engine.FailedObjective {
 	return c.failedObjectives
 }
 
@@ -93,9 +116,25 @@ func (c *Client) ReceivedVouchers() <-chan payments.Voucher {
 	return c.receivedVouchers
 }
 
+// ChainIds returns the ids of the chains the client can hold channels on. The first is the default chain.
+func (c *Client) ChainIds() []*big.Int {
+	return c.engine.GetChainIds()
+}
+
 // CreateVirtualChannel creates a virtual channel with the counterParty using ledger channels
-// with the supplied intermediaries.
+// with the supplied intermediaries on the default chain.
 func (c *Client) CreateVirtualPaymentChannel(Intermediaries []types.Address, CounterParty types.Address, ChallengeDuration uint32, Outcome outcome.Exit) virtualfund.ObjectiveResponse {
+	response, _ := c.CreateVirtualPaymentChannelOnChain(c.defaultChainId(), Intermediaries, CounterParty, ChallengeDuration, Outcome)
+	return response
+}
+
+// CreateVirtualPaymentChannelOnChain creates a virtual channel with the counterParty using ledger channels
+// with the supplied intermediaries on the given chain. An error is returned if the client has no chain service for the chain.
+func (c *Client) CreateVirtualPaymentChannelOnChain(chainId *big.Int, Intermediaries []types.Address, CounterParty types.Address, ChallengeDuration uint32, Outcome outcome.Exit) (virtualfund.ObjectiveResponse, error) {
+	appDefinition, err := c.engine.GetVirtualPaymentAppAddress(chainId)
+	if err != nil {
+		return virtualfund.ObjectiveResponse{}, err
+	}
 
 	objectiveRequest := virtualfund.ObjectiveRequest{
 		Intermediaries:    Intermediaries,
@@ -103,13 +142,14 @@ func (c *Client) CreateVirtualPaymentChannel(Intermediaries []types.Address, Cou
 		ChallengeDuration: ChallengeDuration,
 		Outcome:           Outcome,
 		Nonce:             rand.Uint64(),
-		AppDefinition:     c.engine.GetVirtualPaymentAppAddress(),
+		AppDefinition:     appDefinition,
+		ChainId:           chainId,
 	}
 
 	// Send the event to the engine
 	c.engine.ObjectiveRequestsFromAPI <- objectiveRequest
 
-	return objectiveRequest.Response(*c.Address)
+	return objectiveRequest.Response(*c.Address), nil
 }
 
 // CloseVirtualChannel attempts to close and defund the given virtually funded channel.
@@ -126,24 +166,40 @@ func (c *Client) CloseVirtualChannel(channelId types.Destination) protocols.Obje
 
 }
 
-// CreateLedgerChannel creates a directly funded ledger channel with the given counterparty.
+// CreateLedgerChannel creates a directly funded ledger channel with the given counterparty on the default chain.
 // The channel will run under full consensus rules (it is not possible to provide a custom AppDefinition or AppData).
 func (c *Client) CreateLedgerChannel(Counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit) directfund.ObjectiveResponse {
+	response, _ := c.CreateLedgerChannelOnChain(c.defaultChainId(), Counterparty, ChallengeDuration, outcome)
+	return response
+}
+
+// CreateLedgerChannelOnChain creates a directly funded ledger channel with the given counterparty on the given chain.
+// An error is returned if the client has no chain service for the chain.
+func (c *Client) CreateLedgerChannelOnChain(chainId *big.Int, Counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit) (directfund.ObjectiveResponse, error) {
+	appDefinition, err := c.engine.GetConsensusAppAddress(chainId)
+	if err != nil {
+		return directfund.ObjectiveResponse{}, err
+	}
 
 	objectiveRequest := directfund.ObjectiveRequest{
 		CounterParty:      Counterparty,
 		ChallengeDuration: ChallengeDuration,
 		Outcome:           outcome,
-		AppDefinition:     c.engine.GetConsensusAppAddress(),
+		AppDefinition:     appDefinition,
 		Nonce:             rand.Uint64(),
+		ChainId:           chainId,
 		// Appdata implicitly zero
 	}
 
 	// Send the event to the engine
 	c.engine.ObjectiveRequestsFromAPI <- objectiveRequest
 
-	return objectiveRequest.Response(*c.Address)
+	return objectiveRequest.Response(*c.Address), nil
+}
 
+// defaultChainId returns the id of the chain of the first chain service.
+func (c *Client) defaultChainId() *big.Int {
+	return c.engine.GetChainIds()[0]
 }
 
 // CloseLedgerChannel attempts to close and defund the given directly funded channel.
@@ -160,6 +216,16 @@ func (c *Client) CloseLedgerChannel(channelId types.Destination) protocols.Objec
 
 }
 
+// CancelObjective attempts to cancel an in-progress virtualfund or directfund objective that this client started or approved.
+// Cancellation is only possible before any funds are committed (on chain or in a ledger channel). When successful, the other
+// participants are notified and the objective is reported on FailedObjectives. A virtualfund objective may only be cancelled by its proposer.
+func (c *Client) CancelObjective(id protocols.ObjectiveId) error {
+	result := make(chan error, 1)
+	// Send the event to the engine
+	c.engine.CancelRequestsFromAPI <- engine.CancelRequest{ObjectiveId: id, Result: result}
+	return <-result
+}
+
 // Pay will send a signed voucher to the payee that they can redeem for the given amount.
 func (c *Client) Pay(channelId types.Destination, amount *big.Int) {
 	// Send the event to the engine
@@ -10,15 +10,16 @@ import (
 )
 
 // SignChallengeMessage generates the special signature required to launch a challenge. This is used to prevent non-participants from launching challenges.
-func SignChallengeMessage(s state.State, privateKey []byte) (state.Signature, error) {
-	challengeHash, err := hashChallengeMessage(s)
+func SignChallengeMessage(s state.State, signer nc.Signer) (state.Signature, error) {
+	challengeHash, err := HashChallengeMessage(s)
 	if err != nil {
 		return state.Signature{}, err
 	}
-	return nc.SignEthereumMessage(challengeHash[:], privateKey)
+	return signer.SignChallenge(challengeHash)
 }
 
-func hashChallengeMessage(s state.State) (types.Bytes32, error) {
+// HashChallengeMessage returns the hash which a challenger signs: the hash of the state together with the string "forceMove".
+func HashChallengeMessage(s state.State) (types.Bytes32, error) {
 	digest, err := s.Hash()
 	if err != nil {
 		return types.Bytes32{}, err
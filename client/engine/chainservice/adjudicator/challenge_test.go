@@ -15,6 +15,7 @@ import (
 	"github.com/statechannels/go-nitro/channel/state"
 	"github.com/statechannels/go-nitro/channel/state/outcome"
 	ConsensusApp "github.com/statechannels/go-nitro/client/engine/chainservice/consensusapp"
+	nc "github.com/statechannels/go-nitro/crypto"
 	"github.com/statechannels/go-nitro/types"
 )
 
@@ -109,7 +110,11 @@ func TestChallenge(t *testing.T) {
 		// Generate Signatures
 		aSig, _ := s.Sign(Actors.Alice.PrivateKey)
 		bSig, _ := s.Sign(Actors.Bob.PrivateKey)
-		challengerSig, err := SignChallengeMessage(s, Actors.Alice.PrivateKey)
+		challenger, err := nc.NewInMemorySigner(Actors.Alice.PrivateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		challengerSig, err := SignChallengeMessage(s, challenger)
 
 		if err != nil {
 			t.Fatal(err)
@@ -56,9 +56,62 @@ func NewAllocationUpdatedEvent(channelId types.Destination, blockNum uint64, ass
 	return AllocationUpdatedEvent{commonEvent{channelId, blockNum}, assetAndAmount{AssetAddress: assetAddress, AssetAmount: assetAmount}}
 }
 
-// todo implement other event types
-// ChallengeRegistered
-// ChallengeCleared
+// TransactionConfirmedEvent is emitted when a transaction submitted on behalf of a channel has been mined.
+type TransactionConfirmedEvent struct {
+	commonEvent
+	TxHash common.Hash
+}
+
+// TransactionFailedEvent is emitted when a transaction submitted on behalf of a channel could not be submitted,
+// reverted, or was never mined. Reason describes the failure.
+type TransactionFailedEvent struct {
+	commonEvent
+	TxHash common.Hash // the zero hash if the transaction was never submitted
+	Reason string
+}
+
+func NewTransactionConfirmedEvent(channelId types.Destination, blockNum uint64, txHash common.Hash) TransactionConfirmedEvent {
+	return TransactionConfirmedEvent{commonEvent{channelId, blockNum}, txHash}
+}
+
+func NewTransactionFailedEvent(channelId types.Destination, blockNum uint64, txHash common.Hash, reason string) TransactionFailedEvent {
+	return TransactionFailedEvent{commonEvent{channelId, blockNum}, txHash, reason}
+}
+
+// RetractedEvent is emitted when a reorg removes the log from which an earlier event was emitted.
+// The retracted event should be disregarded. If it concerned an asset, NowHeld is the channel's holding of that asset after the reorg.
+type RetractedEvent struct {
+	commonEvent
+	Retracted    Event
+	AssetAddress common.Address
+	NowHeld      *big.Int
+}
+
+func NewRetractedEvent(retracted Event, blockNum uint64, assetAddress common.Address, nowHeld *big.Int) RetractedEvent {
+	return RetractedEvent{commonEvent{retracted.ChannelID(), blockNum}, retracted, assetAddress, nowHeld}
+}
+
+// ChallengeRegisteredEvent is an internal representation of the ChallengeRegistered blockchain event
+type ChallengeRegisteredEvent struct {
+	commonEvent
+	TurnNumRecord uint64
+	FinalizesAt   uint64 // the time (in seconds since the Unix epoch) at which the channel finalizes unless the challenge is cleared
+	IsFinal       bool
+}
+
+// ChallengeClearedEvent is an internal representation of the ChallengeCleared blockchain event
+type ChallengeClearedEvent struct {
+	commonEvent
+	NewTurnNumRecord uint64
+}
+
+func NewChallengeRegisteredEvent(channelId types.Destination, blockNum uint64, turnNumRecord uint64, finalizesAt uint64, isFinal bool) ChallengeRegisteredEvent {
+	return ChallengeRegisteredEvent{commonEvent{channelId, blockNum}, turnNumRecord, finalizesAt, isFinal}
+}
+
+func NewChallengeClearedEvent(channelId types.Destination, blockNum uint64, newTurnNumRecord uint64) ChallengeClearedEvent {
+	return ChallengeClearedEvent{commonEvent{channelId, blockNum}, newTurnNumRecord}
+}
 
 // ChainEventHandler describes an objective that can handle chain events
 type ChainEventHandler interface {
@@ -68,10 +121,26 @@ type ChainEventHandler interface {
 type ChainService interface {
 	// EventFeed returns a chan for receiving events from the chain service.
 	EventFeed() <-chan Event
-	// SendTransaction is for sending transactions with the chain service
+	// SendTransaction is for sending transactions with the chain service.
+	// A transaction which fails, whether it is rejected on submission or reverts once mined, is reported on the event feed
+	// with a TransactionFailedEvent, so that the objective which sent it can retry or fail. Errors are only returned for
+	// transactions the chain service does not know how to send.
 	SendTransaction(protocols.ChainTransaction) error
 	// GetConsensusAppAddress returns the address of a deployed ConsensusApp (for ledger channels)
 	GetConsensusAppAddress() types.Address
 	// GetVirtualPaymentAppAddress returns the address of a deployed VirtualPaymentApp
 	GetVirtualPaymentAppAddress() types.Address
+	// RegisterChannel starts the delivery of adjudicator events concerning the channel.
+	// Events reporting the outcome of our own transactions are delivered whether or not their channel is registered.
+	RegisterChannel(types.Destination) error
+	// UnregisterChannel stops the delivery of adjudicator events concerning the channel.
+	UnregisterChannel(types.Destination) error
+	// GetHoldings returns the amount of the asset held on chain for the channel
+	GetHoldings(channelId types.Destination, asset common.Address) (*big.Int, error)
+	// GetAdjudicationStatus returns the channel's adjudication status on chain
+	GetAdjudicationStatus(channelId types.Destination) (protocols.AdjudicationStatus, error)
+	// GetChainTime returns the time (in seconds since the Unix epoch) of the chain's latest block, against which challenges finalize
+	GetChainTime() (uint64, error)
+	// GetChainId returns the id of the chain the chain service submits transactions to
+	GetChainId() *big.Int
 }
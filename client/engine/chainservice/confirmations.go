@@ -0,0 +1,112 @@
+package chainservice
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// RETRACTION_DEPTH is how many blocks deep a log must be before we stop expecting a reorg to remove it.
+// Events emitted from logs which are removed by a reorg within this depth are retracted.
+const RETRACTION_DEPTH = 64
+
+// logKey identifies a log in a particular block.
+type logKey struct {
+	blockHash common.Hash
+	txHash    common.Hash
+	index     uint
+}
+
+func keyOf(l ethTypes.Log) logKey {
+	return logKey{l.BlockHash, l.TxHash, l.Index}
+}
+
+// releasedLog is an event emitted from a log, together with the number of the log's block.
+type releasedLog struct {
+	event    Event
+	blockNum uint64
+}
+
+// logConfirmer holds back logs until enough blocks have been mined on top of them, and remembers the events emitted from
+// recently released logs, so that they can be retracted if a reorg removes the logs.
+type logConfirmer struct {
+	confirmations uint64
+	pending       []ethTypes.Log
+	released      map[logKey]releasedLog
+}
+
+func newLogConfirmer(confirmations uint64) *logConfirmer {
+	return &logConfirmer{confirmations: confirmations, released: make(map[logKey]releasedLog)}
+}
+
+// add records a log delivered by the chain.
+//
+// A log which has already been added is ignored, since overlapping subscriptions may deliver a log twice.
+// If the log has been removed by a reorg, it is forgotten. If its event has already been released, that event is returned
+// so that it can be retracted.
+func (c *logConfirmer) add(l ethTypes.Log) (Event, bool) {
+	key := keyOf(l)
+	if !l.Removed {
+		if !c.seen(key) {
+			c.pending = append(c.pending, l)
+		}
+		return nil, false
+	}
+
+	for i, p := range c.pending {
+		if keyOf(p) == key {
+			c.pending = append(c.pending[:i], c.pending[i+1:]...)
+			return nil, false
+		}
+	}
+	released, ok := c.released[key]
+	if !ok {
+		return nil, false
+	}
+	delete(c.released, key)
+	return released.event, true
+}
+
+// confirmed removes and returns the pending logs which are confirmed when the chain's head is at the given block, in the order they were added.
+// It also forgets released logs which are now too deep to be removed by a reorg.
+func (c *logConfirmer) confirmed(head uint64) []ethTypes.Log {
+	confirmed := []ethTypes.Log{}
+	stillPending := []ethTypes.Log{}
+	for _, l := range c.pending {
+		if l.BlockNumber+c.confirmations <= head {
+			confirmed = append(confirmed, l)
+		} else {
+			stillPending = append(stillPending, l)
+		}
+	}
+	c.pending = stillPending
+
+	for key, r := range c.released {
+		if r.blockNum+RETRACTION_DEPTH < head {
+			delete(c.released, key)
+		}
+	}
+	return confirmed
+}
+
+// markReleased records that the event was emitted from the log.
+func (c *logConfirmer) markReleased(l ethTypes.Log, event Event) {
+	c.released[keyOf(l)] = releasedLog{event, l.BlockNumber}
+}
+
+// seen returns true if the log is pending or has been released.
+func (c *logConfirmer) seen(key logKey) bool {
+	if _, ok := c.released[key]; ok {
+		return true
+	}
+	for _, p := range c.pending {
+		if keyOf(p) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPending returns true if any logs are awaiting confirmation.
+func (c *logConfirmer) hasPending() bool {
+	return len(c.pending) > 0
+}
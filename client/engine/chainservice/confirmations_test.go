@@ -0,0 +1,71 @@
+package chainservice
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestLogConfirmer(t *testing.T) {
+	logAt := func(blockNum uint64) ethTypes.Log {
+		return ethTypes.Log{BlockNumber: blockNum, BlockHash: common.BigToHash(new(big.Int).SetUint64(blockNum))}
+	}
+	removed := func(l ethTypes.Log) ethTypes.Log {
+		l.Removed = true
+		return l
+	}
+	event := NewDepositedEvent(types.Destination{1}, 5, common.Address{}, big.NewInt(1), big.NewInt(1))
+
+	t.Run("holds logs until they have enough confirmations", func(t *testing.T) {
+		c := newLogConfirmer(2)
+		c.add(logAt(5))
+		c.add(logAt(6))
+		if got := c.confirmed(6); len(got) != 0 {
+			t.Fatalf("expected no confirmed logs, got %+v", got)
+		}
+		if got := c.confirmed(7); len(got) != 1 || got[0].BlockNumber != 5 {
+			t.Fatalf("expected the log from block 5 to be confirmed, got %+v", got)
+		}
+		if !c.hasPending() {
+			t.Fatal("expected the log from block 6 to be pending")
+		}
+	})
+
+	t.Run("forgets a pending log which is removed", func(t *testing.T) {
+		c := newLogConfirmer(2)
+		c.add(logAt(5))
+		if _, ok := c.add(removed(logAt(5))); ok {
+			t.Fatal("expected nothing to be retracted")
+		}
+		if c.hasPending() {
+			t.Fatal("expected no pending logs")
+		}
+	})
+
+	t.Run("retracts the event of a released log which is removed", func(t *testing.T) {
+		c := newLogConfirmer(0)
+		c.add(logAt(5))
+		for _, l := range c.confirmed(5) {
+			c.markReleased(l, event)
+		}
+		retracted, ok := c.add(removed(logAt(5)))
+		if !ok || retracted != event {
+			t.Fatalf("expected %+v to be retracted, got %+v", event, retracted)
+		}
+	})
+
+	t.Run("stops tracking released logs beyond the retraction depth", func(t *testing.T) {
+		c := newLogConfirmer(0)
+		c.add(logAt(5))
+		for _, l := range c.confirmed(5) {
+			c.markReleased(l, event)
+		}
+		c.confirmed(5 + RETRACTION_DEPTH + 1)
+		if _, ok := c.add(removed(logAt(5))); ok {
+			t.Fatal("expected nothing to be retracted")
+		}
+	})
+}
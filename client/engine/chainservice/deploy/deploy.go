@@ -0,0 +1,236 @@
+// Package deploy deterministically deploys the Nitro contracts through a Create2Deployer.
+package deploy // import "github.com/statechannels/go-nitro/client/engine/chainservice/deploy"
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	NitroAdjudicator "github.com/statechannels/go-nitro/client/engine/chainservice/adjudicator"
+	ConsensusApp "github.com/statechannels/go-nitro/client/engine/chainservice/consensusapp"
+	Create2Deployer "github.com/statechannels/go-nitro/client/engine/chainservice/create2deployer"
+	VirtualPaymentApp "github.com/statechannels/go-nitro/client/engine/chainservice/virtualpaymentapp"
+)
+
+var (
+	ErrBytecodeMismatch  = errors.New("deployed bytecode does not match the compiled contract")
+	ErrNoDeployer        = errors.New("no Create2Deployer is deployed at the supplied address")
+	ErrMissingAddress    = errors.New("the config does not hold the address of every Nitro contract")
+	ErrDeployerNonceUsed = errors.New("the deploying account has already sent transactions on this chain, so the Create2Deployer cannot be deployed at its deterministic address")
+)
+
+// Backend is the chain to deploy to.
+type Backend interface {
+	bind.ContractBackend
+	bind.DeployBackend
+}
+
+// Addresses are the addresses of the deployed Nitro contracts.
+type Addresses struct {
+	NitroAdjudicator  common.Address `json:"nitroAdjudicatorAddress"`
+	ConsensusApp      common.Address `json:"consensusAppAddress"`
+	VirtualPaymentApp common.Address `json:"virtualPaymentAppAddress"`
+}
+
+// contract is a contract to deploy, with its compiled creation bytecode.
+type contract struct {
+	name string
+	bin  string
+}
+
+var contracts = []contract{
+	{"NitroAdjudicator", NitroAdjudicator.NitroAdjudicatorMetaData.Bin},
+	{"ConsensusApp", ConsensusApp.ConsensusAppMetaData.Bin},
+	{"VirtualPaymentApp", VirtualPaymentApp.VirtualPaymentAppMetaData.Bin},
+}
+
+// Create2DeployerAddress returns the address at which DeployCreate2Deployer deploys the Create2Deployer for the deploying account:
+// the address of the account's first contract creation, which is the same on every chain.
+func Create2DeployerAddress(account common.Address) common.Address {
+	return crypto.CreateAddress(account, 0)
+}
+
+// DeployCreate2Deployer deploys a Create2Deployer at Create2DeployerAddress of the deploying account and waits for it to be mined,
+// unless it is already deployed there. The code at the address is checked against the compiled contract.
+//
+// Since the deployment must be the account's first transaction on the chain, an account which is only used to deploy the Nitro contracts
+// gets the same Create2Deployer, and so the same Nitro contracts, on every chain. ErrDeployerNonceUsed is returned if the account has
+// already sent transactions on a chain where the Create2Deployer is not deployed.
+func DeployCreate2Deployer(ctx context.Context, backend Backend, txOpts *bind.TransactOpts) (common.Address, error) {
+	address := Create2DeployerAddress(txOpts.From)
+	code, err := backend.CodeAt(ctx, address, nil)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(code) == 0 {
+		nonce, err := backend.PendingNonceAt(ctx, txOpts.From)
+		if err != nil {
+			return common.Address{}, err
+		}
+		if nonce != 0 {
+			return common.Address{}, fmt.Errorf("%w: %s has nonce %d", ErrDeployerNonceUsed, txOpts.From, nonce)
+		}
+		opts := *txOpts
+		opts.Nonce = big.NewInt(0)
+		_, tx, _, err := Create2Deployer.DeployCreate2Deployer(&opts, backend)
+		if err != nil {
+			return common.Address{}, err
+		}
+		if _, err = bind.WaitDeployed(ctx, backend, tx); err != nil {
+			return common.Address{}, err
+		}
+		code, err = backend.CodeAt(ctx, address, nil)
+		if err != nil {
+			return common.Address{}, err
+		}
+	}
+	err = checkCode(ctx, backend, txOpts.From, address, code, Create2Deployer.Create2DeployerMetaData.Bin)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return address, nil
+}
+
+// Deploy deploys the NitroAdjudicator, ConsensusApp and VirtualPaymentApp through the Create2Deployer at deployer, with the supplied salt.
+// The addresses only depend on the deployer, the salt and the compiled bytecode, so contracts which are already deployed are not
+// deployed again. The code at each address is checked against the compiled contract.
+func Deploy(ctx context.Context, backend Backend, txOpts *bind.TransactOpts, deployer common.Address, salt [32]byte) (Addresses, error) {
+	code, err := backend.CodeAt(ctx, deployer, nil)
+	if err != nil {
+		return Addresses{}, err
+	}
+	if len(code) == 0 {
+		return Addresses{}, ErrNoDeployer
+	}
+	c2d, err := Create2Deployer.NewCreate2Deployer(deployer, backend)
+	if err != nil {
+		return Addresses{}, err
+	}
+
+	deployed := make([]common.Address, len(contracts))
+	for i, c := range contracts {
+		deployed[i], err = deployContract(ctx, backend, txOpts, c2d, deployer, salt, c)
+		if err != nil {
+			return Addresses{}, fmt.Errorf("could not deploy %s: %w", c.name, err)
+		}
+	}
+	return Addresses{NitroAdjudicator: deployed[0], ConsensusApp: deployed[1], VirtualPaymentApp: deployed[2]}, nil
+}
+
+// deployContract deploys the contract unless it is already deployed, and checks the deployed bytecode.
+func deployContract(ctx context.Context, backend Backend, txOpts *bind.TransactOpts, c2d *Create2Deployer.Create2Deployer, deployer common.Address, salt [32]byte, c contract) (common.Address, error) {
+	bin, err := hex.DecodeString(c.bin[2:])
+	if err != nil {
+		return common.Address{}, err
+	}
+	address := crypto.CreateAddress2(deployer, salt, crypto.Keccak256(bin))
+
+	code, err := backend.CodeAt(ctx, address, nil)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(code) == 0 {
+		tx, err := c2d.Deploy(txOpts, big.NewInt(0), salt, bin)
+		if err != nil {
+			return common.Address{}, err
+		}
+		receipt, err := bind.WaitMined(ctx, backend, tx)
+		if err != nil {
+			return common.Address{}, err
+		}
+		if receipt.Status != ethTypes.ReceiptStatusSuccessful {
+			return common.Address{}, fmt.Errorf("deployment transaction %s reverted", tx.Hash())
+		}
+		code, err = backend.CodeAt(ctx, address, nil)
+		if err != nil {
+			return common.Address{}, err
+		}
+	}
+
+	err = checkCode(ctx, backend, deployer, address, code, c.bin)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return address, nil
+}
+
+// checkCode checks that the code deployed at address is the runtime bytecode of the contract with the supplied hex encoded creation bytecode.
+func checkCode(ctx context.Context, backend Backend, from common.Address, address common.Address, code []byte, creationBin string) error {
+	bin, err := hex.DecodeString(creationBin[2:])
+	if err != nil {
+		return err
+	}
+	// Running the creation bytecode in a call returns the runtime bytecode it would deploy
+	expected, err := backend.CallContract(ctx, ethereum.CallMsg{From: from, Data: bin}, nil)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(code, expected) {
+		return fmt.Errorf("%w at %s", ErrBytecodeMismatch, address)
+	}
+	return nil
+}
+
+// ReadConfig reads the addresses of the Nitro contracts from the JSON file at path, as written by WriteConfig, so that they can be
+// passed to chainservice.NewEthChainService. Other keys in the file are ignored. ErrMissingAddress is returned if an address is missing.
+func ReadConfig(path string) (Addresses, error) {
+	config, err := os.ReadFile(path)
+	if err != nil {
+		return Addresses{}, err
+	}
+	var addresses Addresses
+	err = json.Unmarshal(config, &addresses)
+	if err != nil {
+		return Addresses{}, fmt.Errorf("could not parse config %s: %w", path, err)
+	}
+	for _, address := range []common.Address{addresses.NitroAdjudicator, addresses.ConsensusApp, addresses.VirtualPaymentApp} {
+		if address == (common.Address{}) {
+			return Addresses{}, fmt.Errorf("%w: %s", ErrMissingAddress, path)
+		}
+	}
+	return addresses, nil
+}
+
+// WriteConfig writes the addresses to the JSON file at path, keeping any other keys in the file, so that a node can read them with ReadConfig.
+// The file is created if it does not exist.
+func WriteConfig(path string, addresses Addresses) error {
+	config := make(map[string]json.RawMessage)
+	existing, err := os.ReadFile(path)
+	if err == nil {
+		err = json.Unmarshal(existing, &config)
+		if err != nil {
+			return fmt.Errorf("could not parse config %s: %w", path, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	encoded, err := json.Marshal(addresses)
+	if err != nil {
+		return err
+	}
+	fields := make(map[string]json.RawMessage)
+	err = json.Unmarshal(encoded, &fields)
+	if err != nil {
+		return err
+	}
+	for key, value := range fields {
+		config[key] = value
+	}
+
+	updated, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(updated, '\n'), 0o644)
+}
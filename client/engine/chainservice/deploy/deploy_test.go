@@ -0,0 +1,218 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// autoMiningBackend mines a block for every transaction, so that deployments can be waited for.
+type autoMiningBackend struct {
+	*backends.SimulatedBackend
+}
+
+func (b autoMiningBackend) SendTransaction(ctx context.Context, tx *ethTypes.Transaction) error {
+	err := b.SimulatedBackend.SendTransaction(ctx, tx)
+	if err != nil {
+		return err
+	}
+	b.Commit()
+	return nil
+}
+
+func TestDeploy(t *testing.T) {
+	ctx := context.Background()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	txOpts, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337)) // 1337 according to docs on SimulatedBackend
+	if err != nil {
+		t.Fatal(err)
+	}
+	balance, _ := new(big.Int).SetString("10000000000000000000", 10) // 10 eth in wei
+	sim := backends.NewSimulatedBackend(core.GenesisAlloc{txOpts.From: {Balance: balance}}, 15_000_000)
+	defer sim.Close()
+	backend := autoMiningBackend{sim}
+
+	_, err = Deploy(ctx, backend, txOpts, txOpts.From, [32]byte{})
+	if !errors.Is(err, ErrNoDeployer) {
+		t.Fatalf("expected %v, got %v", ErrNoDeployer, err)
+	}
+
+	deployer, err := DeployCreate2Deployer(ctx, backend, txOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deployer != Create2DeployerAddress(txOpts.From) {
+		t.Fatalf("expected the Create2Deployer at %s, got %s", Create2DeployerAddress(txOpts.From), deployer)
+	}
+	salt := [32]byte{1}
+	addresses, err := Deploy(ctx, backend, txOpts, deployer, salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, address := range []common.Address{addresses.NitroAdjudicator, addresses.ConsensusApp, addresses.VirtualPaymentApp} {
+		code, err := sim.CodeAt(ctx, address, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(code) == 0 {
+			t.Fatalf("expected a contract at %s", address)
+		}
+	}
+
+	// Deploying again finds the existing contracts, without sending any transactions
+	nonce, err := sim.PendingNonceAt(ctx, txOpts.From)
+	if err != nil {
+		t.Fatal(err)
+	}
+	redeployed, err := Deploy(ctx, backend, txOpts, deployer, salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if redeployed != addresses {
+		t.Fatalf("expected the same addresses %+v, got %+v", addresses, redeployed)
+	}
+	if after, _ := sim.PendingNonceAt(ctx, txOpts.From); after != nonce {
+		t.Fatalf("expected no transactions to be sent, but the nonce increased from %d to %d", nonce, after)
+	}
+
+	// The Create2Deployer is found rather than deployed again, although the account has since sent transactions
+	found, err := DeployCreate2Deployer(ctx, backend, txOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found != deployer {
+		t.Fatalf("expected the existing Create2Deployer at %s, got %s", deployer, found)
+	}
+
+	// A different salt gives different addresses
+	salted, err := Deploy(ctx, backend, txOpts, deployer, [32]byte{2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if salted.NitroAdjudicator == addresses.NitroAdjudicator {
+		t.Fatal("expected a different salt to give a different address")
+	}
+}
+
+func TestDeployCreate2DeployerOnAnotherChain(t *testing.T) {
+	ctx := context.Background()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	txOpts, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+	if err != nil {
+		t.Fatal(err)
+	}
+	balance, _ := new(big.Int).SetString("10000000000000000000", 10) // 10 eth in wei
+	newChain := func() (*backends.SimulatedBackend, autoMiningBackend) {
+		sim := backends.NewSimulatedBackend(core.GenesisAlloc{txOpts.From: {Balance: balance}}, 15_000_000)
+		return sim, autoMiningBackend{sim}
+	}
+
+	// The same account deploys the Create2Deployer, and so the Nitro contracts, at the same addresses on every chain
+	want := [2]Addresses{}
+	for i := range want {
+		sim, backend := newChain()
+		defer sim.Close()
+		deployer, err := DeployCreate2Deployer(ctx, backend, txOpts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want[i], err = Deploy(ctx, backend, txOpts, deployer, [32]byte{1})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want[0] != want[1] {
+		t.Fatalf("expected the same addresses on both chains, got %+v and %+v", want[0], want[1])
+	}
+
+	// An account which has already sent a transaction cannot deploy the Create2Deployer at its deterministic address
+	sim, backend := newChain()
+	defer sim.Close()
+	transfer, err := txOpts.Signer(txOpts.From, ethTypes.NewTransaction(0, common.Address{1}, big.NewInt(1), 21000, big.NewInt(1_000_000_000), nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = backend.SendTransaction(ctx, transfer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = DeployCreate2Deployer(ctx, backend, txOpts)
+	if !errors.Is(err, ErrDeployerNonceUsed) {
+		t.Fatalf("expected %v, got %v", ErrDeployerNonceUsed, err)
+	}
+}
+
+func TestWriteConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(path, []byte(`{"chainUrl":"ws://127.0.0.1:8545","nitroAdjudicatorAddress":"0x0000000000000000000000000000000000000001"}`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addresses := Addresses{
+		NitroAdjudicator:  common.HexToAddress("0xa"),
+		ConsensusApp:      common.HexToAddress("0xb"),
+		VirtualPaymentApp: common.HexToAddress("0xc"),
+	}
+	err = WriteConfig(path, addresses)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	written, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Addresses
+	err = json.Unmarshal(written, &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != addresses {
+		t.Fatalf("expected addresses %+v, got %+v", addresses, got)
+	}
+	var other struct{ ChainUrl string }
+	err = json.Unmarshal(written, &other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.ChainUrl != "ws://127.0.0.1:8545" {
+		t.Fatalf("expected other settings to be kept, got %s", written)
+	}
+
+	read, err := ReadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read != addresses {
+		t.Fatalf("expected to read addresses %+v, got %+v", addresses, read)
+	}
+}
+
+func TestReadConfigWithMissingAddress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(path, []byte(`{"nitroAdjudicatorAddress":"0x0000000000000000000000000000000000000001"}`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ReadConfig(path)
+	if !errors.Is(err, ErrMissingAddress) {
+		t.Fatalf("expected %v, got %v", ErrMissingAddress, err)
+	}
+}
@@ -1,18 +1,23 @@
 package chainservice
 
 import (
+	"errors"
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
 	NitroAdjudicator "github.com/statechannels/go-nitro/client/engine/chainservice/adjudicator"
 )
 
+var ErrUnsupportedPayout = errors.New("cannot determine the asset of an allocation update made by this transaction")
+
 // getChainHolding reads on-chain holdings for a channel and an asset address given a transaction and an event generated by the transaction.
 func getChainHolding(na *NitroAdjudicator.NitroAdjudicator, tx *types.Transaction, event *NitroAdjudicator.NitroAdjudicatorAllocationUpdated) (common.Address, *big.Int, error) {
-	assetAddress, err := assetAddressForIndex(na, tx, event.AssetIndex)
+	assetAddress, err := assetAddressForIndex(tx, event)
 	if err != nil {
 		return assetAddress, &big.Int{}, err
 	}
@@ -23,55 +28,80 @@ func getChainHolding(na *NitroAdjudicator.NitroAdjudicator, tx *types.Transactio
 	return assetAddress, amount, nil
 }
 
-// assetAddressForIndex uses the input parameters of a transaction to map an asset index to an asset address
-func assetAddressForIndex(na *NitroAdjudicator.NitroAdjudicator, tx *types.Transaction, index *big.Int) (common.Address, error) {
-	abi, err := NitroAdjudicator.NitroAdjudicatorMetaData.GetAbi()
+// assetAddressForIndex uses the input parameters of the adjudicator call which emitted an AllocationUpdated event to map the event's
+// asset index to an asset address. The call may be concludeAndTransferAllAssets, transferAllAssets, transfer or reclaim, made by any account.
+//
+// The asset cannot be determined when the adjudicator was called by another contract rather than directly by the transaction,
+// in which case ErrUnsupportedPayout is returned.
+func assetAddressForIndex(tx *types.Transaction, event *NitroAdjudicator.NitroAdjudicatorAllocationUpdated) (common.Address, error) {
+	nitroAbi, err := NitroAdjudicator.NitroAdjudicatorMetaData.GetAbi()
 	if err != nil {
 		return common.Address{}, err
 	}
-	params, err := decodeTxParams(abi, tx.Data())
+	method, args, err := decodeTxParams(nitroAbi, tx.Data())
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: %v", ErrUnsupportedPayout, err)
+	}
+
+	var assets []common.Address
+	switch method.Name {
+	case "concludeAndTransferAllAssets":
+		candidate := *abi.ConvertType(args[2], new(NitroAdjudicator.INitroTypesSignedVariablePart)).(*NitroAdjudicator.INitroTypesSignedVariablePart)
+		for _, exit := range candidate.VariablePart.Outcome {
+			assets = append(assets, exit.Asset)
+		}
+	case "transferAllAssets":
+		exits := *abi.ConvertType(args[1], new([]NitroAdjudicator.ExitFormatSingleAssetExit)).(*[]NitroAdjudicator.ExitFormatSingleAssetExit)
+		for _, exit := range exits {
+			assets = append(assets, exit.Asset)
+		}
+	case "transfer":
+		assets, err = decodeAssets(args[2].([]byte))
+	case "reclaim":
+		reclaimArgs := *abi.ConvertType(args[0], new(NitroAdjudicator.IMultiAssetHolderReclaimArgs)).(*NitroAdjudicator.IMultiAssetHolderReclaimArgs)
+		if event.ChannelId == reclaimArgs.SourceChannelId {
+			assets, err = decodeAssets(reclaimArgs.SourceOutcomeBytes)
+		} else {
+			assets, err = decodeAssets(reclaimArgs.TargetOutcomeBytes)
+		}
+	default:
+		return common.Address{}, fmt.Errorf("%w: %s", ErrUnsupportedPayout, method.Name)
+	}
 	if err != nil {
 		return common.Address{}, err
 	}
-	// TODO remove the assumption that the tx incudes a candidate parameter
-	// 	concludeAndTransferAllAssets includes this parameter, but transferAllAssets, transfer, and claim do not.
-	//  https://github.com/statechannels/go-nitro/issues/759
-	candidate := params["candidate"].(struct {
-		VariablePart struct {
-			Outcome []struct {
-				Asset       common.Address "json:\"asset\""
-				Metadata    []uint8        "json:\"metadata\""
-				Allocations []struct {
-					Destination    [32]uint8 "json:\"destination\""
-					Amount         *big.Int  "json:\"amount\""
-					AllocationType uint8     "json:\"allocationType\""
-					Metadata       []uint8   "json:\"metadata\""
-				} "json:\"allocations\""
-			} "json:\"outcome\""
-			AppData []uint8  "json:\"appData\""
-			TurnNum *big.Int "json:\"turnNum\""
-			IsFinal bool     "json:\"isFinal\""
-		} "json:\"variablePart\""
-		Sigs []struct {
-			V uint8     "json:\"v\""
-			R [32]uint8 "json:\"r\""
-			S [32]uint8 "json:\"s\""
-		} "json:\"sigs\""
-	})
-	return candidate.VariablePart.Outcome[index.Int64()].Asset, nil
 
+	if !event.AssetIndex.IsInt64() || event.AssetIndex.Int64() >= int64(len(assets)) {
+		return common.Address{}, fmt.Errorf("asset index %v is out of range for an outcome with %d assets", event.AssetIndex, len(assets))
+	}
+	return assets[event.AssetIndex.Int64()], nil
 }
 
-func decodeTxParams(abi *abi.ABI, data []byte) (map[string]interface{}, error) {
-	m, err := abi.MethodById(data[:4])
+// decodeAssets returns the assets of an abi encoded outcome.
+func decodeAssets(outcomeBytes []byte) ([]common.Address, error) {
+	exit, err := outcome.Decode(outcomeBytes)
 	if err != nil {
-		return map[string]interface{}{}, err
-
+		return nil, fmt.Errorf("could not decode outcome: %w", err)
 	}
-	v := map[string]interface{}{}
-	if err := m.Inputs.UnpackIntoMap(v, data[4:]); err != nil {
-		return map[string]interface{}{}, err
+	assets := make([]common.Address, len(exit))
+	for i, sae := range exit {
+		assets[i] = sae.Asset
 	}
+	return assets, nil
+}
 
-	return v, nil
+// decodeTxParams returns the method called by the transaction data, and the arguments it was called with.
+func decodeTxParams(abi *abi.ABI, data []byte) (*abi.Method, []interface{}, error) {
+	if len(data) < 4 {
+		return nil, nil, errors.New("transaction data has no method id")
+	}
+	m, err := abi.MethodById(data[:4])
+	if err != nil {
+		return nil, nil, err
+	}
+	args, err := m.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, nil, err
+	}
+	return m, args, nil
 }
@@ -0,0 +1,87 @@
+package chainservice
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	NitroAdjudicator "github.com/statechannels/go-nitro/client/engine/chainservice/adjudicator"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestAssetAddressForIndex(t *testing.T) {
+	nitroAbi, err := NitroAdjudicator.NitroAdjudicatorMetaData.GetAbi()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := common.HexToAddress(`0x1234`)
+	exit := outcome.Exit{concludeOutcome[0], outcome.SingleAssetExit{Asset: token, Allocations: concludeOutcome[0].Allocations}}
+	outcomeBytes := encode(t, exit)
+	variablePart := NitroAdjudicator.ConvertVariablePart(state.VariablePart{Outcome: exit, TurnNum: 2, IsFinal: true})
+	fixedPart := NitroAdjudicator.ConvertFixedPart(state.State{ChainId: big.NewInt(1337), Participants: []types.Address{Alice.Address(), Bob.Address()}}.FixedPart())
+	channelId := types.Destination{1}
+	otherChannelId := types.Destination{2}
+
+	pack := func(method string, args ...interface{}) []byte {
+		data, err := nitroAbi.Pack(method, args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	reclaimArgs := NitroAdjudicator.IMultiAssetHolderReclaimArgs{
+		SourceChannelId:       channelId,
+		SourceOutcomeBytes:    outcomeBytes,
+		SourceAssetIndex:      big.NewInt(1),
+		IndexOfTargetInSource: big.NewInt(0),
+		TargetOutcomeBytes:    encode(t, concludeOutcome),
+		TargetAssetIndex:      big.NewInt(0),
+	}
+
+	testCases := []struct {
+		name       string
+		data       []byte
+		channelId  types.Destination
+		assetIndex int64
+		want       common.Address
+	}{
+		{"concludeAndTransferAllAssets", pack("concludeAndTransferAllAssets", fixedPart, []NitroAdjudicator.INitroTypesSignedVariablePart{}, NitroAdjudicator.INitroTypesSignedVariablePart{VariablePart: variablePart}), channelId, 1, token},
+		{"transferAllAssets", pack("transferAllAssets", channelId, variablePart.Outcome, [32]byte{}), channelId, 1, token},
+		{"transfer", pack("transfer", big.NewInt(1), channelId, outcomeBytes, [32]byte{}, []*big.Int{}), channelId, 1, token},
+		{"reclaim of the source channel", pack("reclaim", reclaimArgs), channelId, 1, token},
+		{"reclaim of the target channel", pack("reclaim", reclaimArgs), otherChannelId, 0, concludeOutcome[0].Asset},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tx := ethTypes.NewTx(&ethTypes.LegacyTx{Data: tc.data})
+			got, err := assetAddressForIndex(tx, &NitroAdjudicator.NitroAdjudicatorAllocationUpdated{ChannelId: tc.channelId, AssetIndex: big.NewInt(tc.assetIndex)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Fatalf("expected asset %s, got %s", tc.want, got)
+			}
+		})
+	}
+
+	t.Run("a call through another contract", func(t *testing.T) {
+		tx := ethTypes.NewTx(&ethTypes.LegacyTx{Data: []byte{0xde, 0xad, 0xbe, 0xef}})
+		_, err := assetAddressForIndex(tx, &NitroAdjudicator.NitroAdjudicatorAllocationUpdated{ChannelId: channelId, AssetIndex: big.NewInt(0)})
+		if !errors.Is(err, ErrUnsupportedPayout) {
+			t.Fatalf("expected %v, got %v", ErrUnsupportedPayout, err)
+		}
+	})
+}
+
+// encode returns the abi encoding of the outcome, as the adjudicator expects it.
+func encode(t *testing.T, exit outcome.Exit) []byte {
+	encoded, err := exit.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []byte(encoded)
+}
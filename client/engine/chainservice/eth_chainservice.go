@@ -2,9 +2,12 @@ package chainservice
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"math/big"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
@@ -12,6 +15,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	ethTypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/statechannels/go-nitro/channel/state"
 	NitroAdjudicator "github.com/statechannels/go-nitro/client/engine/chainservice/adjudicator"
 	Token "github.com/statechannels/go-nitro/client/engine/chainservice/erc20"
 	"github.com/statechannels/go-nitro/protocols"
@@ -21,12 +25,21 @@ import (
 var allocationUpdatedTopic = crypto.Keccak256Hash([]byte("AllocationUpdated(bytes32,uint256,uint256)"))
 var concludedTopic = crypto.Keccak256Hash([]byte("Concluded(bytes32,uint48)"))
 var depositedTopic = crypto.Keccak256Hash([]byte("Deposited(bytes32,address,uint256,uint256)"))
+var challengeClearedTopic = crypto.Keccak256Hash([]byte("ChallengeCleared(bytes32,uint48)"))
+
+// challengeRegisteredTopic is the hash of the ChallengeRegistered event's signature, whose tuple parameters make it too long to spell out
+var challengeRegisteredTopic = common.HexToHash("0x11f9d0a22f085bac11f2dd6b9e2efa2dbe0f826da2fe74ac2be2410dbeefa559")
 
 type ethChain interface {
 	bind.ContractBackend
 	ethereum.TransactionReader
 }
 
+// chainIdReader is implemented by nodes which report their chain id, such as an ethclient.Client
+type chainIdReader interface {
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
 type EthChainService struct {
 	chain                    ethChain
 	na                       *NitroAdjudicator.NitroAdjudicator
@@ -34,8 +47,47 @@ type EthChainService struct {
 	consensusAppAddress      common.Address
 	virtualPaymentAppAddress common.Address
 	txSigner                 *bind.TransactOpts
+	txManager                *txManager
+	chainId                  *big.Int
+	confirmer                *logConfirmer
 	out                      chan Event
 	logger                   *log.Logger
+
+	mu       sync.Mutex
+	channels map[types.Destination]bool // the channels whose events are delivered
+	sub      ethereum.Subscription      // the subscription to logs concerning the registered channels
+	logs     chan ethTypes.Log
+
+	pollInterval time.Duration // how often logs are polled for, or zero if they are subscribed to
+	pollRange    uint64
+	polledTo     uint64 // the last block whose logs have been polled for
+}
+
+// EthChainServiceOptions configures an EthChainService. The zero value is suitable for a development chain.
+type EthChainServiceOptions struct {
+	// Confirmations is the number of blocks which must be mined on top of the block containing an adjudicator log
+	// before an event is emitted for the log. Logs which are removed by a reorg before then are never emitted.
+	// Events for logs which are removed later are retracted with a RetractedEvent.
+	Confirmations uint64
+	// StartBlock is the block from which adjudicator logs emitted before the service started are replayed.
+	// Logs from StartBlock itself are replayed, since the events of that block may not all have been handled.
+	// If StartBlock is zero, it is read from Store, and if that is nil too no logs are replayed.
+	StartBlock uint64
+	// Store, if not nil, supplies the StartBlock: the last block of the chain from which the node handled an adjudicator event.
+	// A node should pass the store it passes to the client, so that events emitted while the node was down are replayed.
+	Store BlockNumStore
+	// PollInterval, if not zero, is how often the node is polled for adjudicator logs with eth_getLogs. Polling suits nodes
+	// which are reached over HTTP, and so do not support subscriptions. If PollInterval is zero, logs are subscribed to.
+	PollInterval time.Duration
+	// PollRange is the largest number of blocks whose logs are requested in one poll. If it is zero, BACKFILL_BATCH_SIZE is used.
+	PollRange uint64
+	// ChainId is the id of the chain. If it is nil, it is read from the node with eth_chainId.
+	ChainId *big.Int
+}
+
+// BlockNumStore records the last block of each chain from which an adjudicator event has been handled. store.Store implements it.
+type BlockNumStore interface {
+	GetLastBlockNumSeen(chainId *big.Int) uint64
 }
 
 // RESUB_INTERVAL is how often we resubscribe to log events.
@@ -44,58 +96,87 @@ type EthChainService struct {
 // See https://github.com/ethereum/go-ethereum/blob/e14164d516600e9ac66f9060892e078f5c076229/eth/filters/filter_system.go#L43
 const RESUB_INTERVAL = 2*time.Minute + 30*time.Second
 
+// HEAD_POLL_INTERVAL is how often we check whether logs awaiting confirmation have been confirmed.
+const HEAD_POLL_INTERVAL = 4 * time.Second
+
+// INITIAL_REPLAY_RETRY_DELAY and MAX_REPLAY_RETRY_DELAY bound the exponential backoff between attempts to replay historical logs.
+const (
+	INITIAL_REPLAY_RETRY_DELAY = time.Second
+	MAX_REPLAY_RETRY_DELAY     = time.Minute
+)
+
+// BACKFILL_BATCH_SIZE is the number of blocks whose logs are requested at once when replaying historical logs.
+// Many providers limit the range of blocks which may be queried with eth_getLogs.
+const BACKFILL_BATCH_SIZE = 2000
+
 // NewEthChainService constructs a chain service that submits transactions to a NitroAdjudicator
 // and listens to events from an eventSource
 func NewEthChainService(chain ethChain, na *NitroAdjudicator.NitroAdjudicator,
-	naAddress, caAddress, vpaAddress common.Address, txSigner *bind.TransactOpts, logDestination io.Writer) (*EthChainService, error) {
+	naAddress, caAddress, vpaAddress common.Address, txSigner *bind.TransactOpts, logDestination io.Writer, opts EthChainServiceOptions) (*EthChainService, error) {
 	logPrefix := "chainservice " + txSigner.From.String() + ": "
 	logger := log.New(logDestination, logPrefix, log.Lmicroseconds|log.Lshortfile)
 	// Use a buffered channel so we don't have to worry about blocking on writing to the channel.
-	ecs := EthChainService{chain, na, naAddress, caAddress, vpaAddress, txSigner, make(chan Event, 10), logger}
-
-	err := ecs.subcribeToEvents()
-	return &ecs, err
-}
+	ecs := EthChainService{
+		chain:                    chain,
+		na:                       na,
+		naAddress:                naAddress,
+		consensusAppAddress:      caAddress,
+		virtualPaymentAppAddress: vpaAddress,
+		txSigner:                 txSigner,
+		txManager:                newTxManager(chain, txSigner, logger),
+		confirmer:                newLogConfirmer(opts.Confirmations),
+		out:                      make(chan Event, 10),
+		logger:                   logger,
+		channels:                 make(map[types.Destination]bool),
+		pollInterval:             opts.PollInterval,
+		pollRange:                opts.PollRange,
+		chainId:                  opts.ChainId,
+	}
+	if ecs.pollRange == 0 {
+		ecs.pollRange = BACKFILL_BATCH_SIZE
+	}
+	if ecs.chainId == nil {
+		reader, ok := chain.(chainIdReader)
+		if !ok {
+			return &ecs, errors.New("the chain id must be supplied for a node which cannot report it")
+		}
+		chainId, err := reader.ChainID(context.Background())
+		if err != nil {
+			return &ecs, fmt.Errorf("could not read chain id: %w", err)
+		}
+		ecs.chainId = chainId
+	}
 
-// defaultTxOpts returns transaction options suitable for most transaction submissions
-func (ecs *EthChainService) defaultTxOpts() *bind.TransactOpts {
-	return &bind.TransactOpts{
-		From:     ecs.txSigner.From,
-		Nonce:    ecs.txSigner.Nonce,
-		Signer:   ecs.txSigner.Signer,
-		GasPrice: ecs.txSigner.GasPrice,
-		GasLimit: ecs.txSigner.GasLimit,
+	startBlock := opts.StartBlock
+	if startBlock == 0 && opts.Store != nil {
+		startBlock = opts.Store.GetLastBlockNumSeen(ecs.chainId)
 	}
+	err := ecs.subcribeToEvents(startBlock)
+	return &ecs, err
 }
 
 // SendTransaction sends the transaction and blocks until it has been submitted.
+// ERC20 deposits are submitted in the background instead, since the token approval must be mined before the deposit is sent.
+//
+// Submitted transactions are monitored in the background, and resubmitted with higher fees if they are not mined promptly.
+// The outcome of each transaction is reported on the event feed with a TransactionConfirmedEvent or a TransactionFailedEvent.
+// Transactions which cannot be submitted, for example because they would revert, are also reported with a TransactionFailedEvent.
 func (ecs *EthChainService) SendTransaction(tx protocols.ChainTransaction) error {
+	ctx := context.Background()
 	switch tx := tx.(type) {
 	case protocols.DepositTransaction:
 		for tokenAddress, amount := range tx.Deposit {
-			txOpts := ecs.defaultTxOpts()
 			ethTokenAddress := common.Address{}
 			if tokenAddress == ethTokenAddress {
-				txOpts.Value = amount
-			} else {
-				tokenTransactor, err := Token.NewTokenTransactor(tokenAddress, ecs.chain)
-				if err != nil {
-					return err
-				}
-				_, err = tokenTransactor.Approve(ecs.defaultTxOpts(), ecs.naAddress, amount)
-				if err != nil {
-					return err
-				}
-			}
-			holdings, err := ecs.na.Holdings(&bind.CallOpts{}, tokenAddress, tx.ChannelId())
-			if err != nil {
-				return err
+				ecs.deposit(ctx, tx.ChannelId(), tokenAddress, amount)
+				continue
 			}
-
-			_, err = ecs.na.Deposit(txOpts, tokenAddress, tx.ChannelId(), holdings, amount)
+			tokenTransactor, err := Token.NewTokenTransactor(tokenAddress, ecs.chain)
 			if err != nil {
-				return err
+				ecs.reportFailure(tx.ChannelId(), common.Hash{}, fmt.Errorf("could not bind token %s: %w", tokenAddress, err))
+				continue
 			}
+			go ecs.approveAndDeposit(ctx, tx.ChannelId(), tokenTransactor, tokenAddress, amount)
 		}
 		return nil
 	case protocols.WithdrawAllTransaction:
@@ -109,96 +190,463 @@ func (ecs *EthChainService) SendTransaction(tx protocols.ChainTransaction) error
 			VariablePart: nitroVariablePart,
 			Sigs:         nitroSignatures,
 		}
-		_, err := ecs.na.ConcludeAndTransferAllAssets(ecs.defaultTxOpts(), nitroFixedPart, proof, candidate)
-		return err
-
+		concludeTx, err := ecs.txManager.send(ctx, func(opts *bind.TransactOpts) (*ethTypes.Transaction, error) {
+			return ecs.na.ConcludeAndTransferAllAssets(opts, nitroFixedPart, proof, candidate)
+		})
+		if err != nil {
+			ecs.reportFailure(tx.ChannelId(), common.Hash{}, fmt.Errorf("could not conclude channel: %w", err))
+			return nil
+		}
+		ecs.monitor(tx.ChannelId(), concludeTx)
+		return nil
+	case protocols.ChallengeTransaction:
+		fixedPart, proof, candidate := convertSupport(tx.Candidate, tx.Proof)
+		challengeTx, err := ecs.txManager.send(ctx, func(opts *bind.TransactOpts) (*ethTypes.Transaction, error) {
+			return ecs.na.Challenge(opts, fixedPart, proof, candidate, NitroAdjudicator.ConvertSignature(tx.ChallengerSig))
+		})
+		if err != nil {
+			ecs.reportFailure(tx.ChannelId(), common.Hash{}, fmt.Errorf("could not challenge: %w", err))
+			return nil
+		}
+		ecs.monitor(tx.ChannelId(), challengeTx)
+		return nil
+	case protocols.CheckpointTransaction:
+		fixedPart, proof, candidate := convertSupport(tx.Candidate, tx.Proof)
+		checkpointTx, err := ecs.txManager.send(ctx, func(opts *bind.TransactOpts) (*ethTypes.Transaction, error) {
+			return ecs.na.Checkpoint(opts, fixedPart, proof, candidate)
+		})
+		if err != nil {
+			ecs.reportFailure(tx.ChannelId(), common.Hash{}, fmt.Errorf("could not checkpoint: %w", err))
+			return nil
+		}
+		ecs.monitor(tx.ChannelId(), checkpointTx)
+		return nil
+	case protocols.TransferAllTransaction:
+		stateHash, err := tx.State.Hash()
+		if err != nil {
+			ecs.reportFailure(tx.ChannelId(), common.Hash{}, fmt.Errorf("could not hash the state to transfer assets with: %w", err))
+			return nil
+		}
+		nitroOutcome := NitroAdjudicator.ConvertVariablePart(tx.State.VariablePart()).Outcome
+		transferTx, err := ecs.txManager.send(ctx, func(opts *bind.TransactOpts) (*ethTypes.Transaction, error) {
+			return ecs.na.TransferAllAssets(opts, tx.ChannelId(), nitroOutcome, stateHash)
+		})
+		if err != nil {
+			ecs.reportFailure(tx.ChannelId(), common.Hash{}, fmt.Errorf("could not transfer assets: %w", err))
+			return nil
+		}
+		ecs.monitor(tx.ChannelId(), transferTx)
+		return nil
 	default:
 		return fmt.Errorf("unexpected transaction type %T", tx)
 	}
 }
-func (ecs *EthChainService) subcribeToEvents() error {
 
-	go ecs.listenForLogEvents()
+// convertSupport converts a candidate state, and the states which prove it is supported, to the types expected by the adjudicator.
+func convertSupport(candidate state.SignedState, proof []state.SignedState) (NitroAdjudicator.INitroTypesFixedPart, []NitroAdjudicator.INitroTypesSignedVariablePart, NitroAdjudicator.INitroTypesSignedVariablePart) {
+	convert := func(ss state.SignedState) NitroAdjudicator.INitroTypesSignedVariablePart {
+		sigs := make([]NitroAdjudicator.INitroTypesSignature, 0, len(ss.Signatures()))
+		for _, sig := range ss.Signatures() {
+			sigs = append(sigs, NitroAdjudicator.ConvertSignature(sig))
+		}
+		return NitroAdjudicator.INitroTypesSignedVariablePart{VariablePart: NitroAdjudicator.ConvertVariablePart(ss.State().VariablePart()), Sigs: sigs}
+	}
+	nitroProof := make([]NitroAdjudicator.INitroTypesSignedVariablePart, 0, len(proof))
+	for _, ss := range proof {
+		nitroProof = append(nitroProof, convert(ss))
+	}
+	return NitroAdjudicator.ConvertFixedPart(candidate.State().FixedPart()), nitroProof, convert(candidate)
+}
+
+// deposit submits a deposit of the asset into the channel, and monitors it (see monitor). Failures are reported on the event feed.
+func (ecs *EthChainService) deposit(ctx context.Context, channelId types.Destination, asset common.Address, amount *big.Int) {
+	holdings, err := ecs.na.Holdings(&bind.CallOpts{}, asset, channelId)
+	if err != nil {
+		ecs.reportFailure(channelId, common.Hash{}, fmt.Errorf("could not read holdings of asset %s: %w", asset, err))
+		return
+	}
+	depositTx, err := ecs.txManager.send(ctx, func(opts *bind.TransactOpts) (*ethTypes.Transaction, error) {
+		if asset == (common.Address{}) {
+			opts.Value = amount
+		}
+		return ecs.na.Deposit(opts, asset, channelId, holdings, amount)
+	})
+	if err != nil {
+		ecs.reportFailure(channelId, common.Hash{}, fmt.Errorf("could not deposit asset %s: %w", asset, err))
+		return
+	}
+	ecs.monitor(channelId, depositTx)
+}
+
+// approveAndDeposit approves the adjudicator to spend the amount of the token, and deposits it once the approval has been mined,
+// since the deposit would revert if it were mined before the approval. It blocks while the approval is mined, so it is run in
+// the background. Failures are reported on the event feed.
+func (ecs *EthChainService) approveAndDeposit(ctx context.Context, channelId types.Destination, tokenTransactor *Token.TokenTransactor, token common.Address, amount *big.Int) {
+	approval, err := ecs.txManager.send(ctx, func(opts *bind.TransactOpts) (*ethTypes.Transaction, error) {
+		return tokenTransactor.Approve(opts, ecs.naAddress, amount)
+	})
+	if err != nil {
+		ecs.reportFailure(channelId, common.Hash{}, fmt.Errorf("could not approve deposit of token %s: %w", token, err))
+		return
+	}
+	_, err = ecs.txManager.waitMined(ctx, approval)
+	if err != nil {
+		ecs.reportFailure(channelId, approval.Hash(), fmt.Errorf("could not approve deposit of token %s: %w", token, err))
+		return
+	}
+	ecs.deposit(ctx, channelId, token, amount)
+}
+
+// monitor waits in the background for the transaction to be mined, resubmitting it if necessary,
+// and reports the outcome on the event feed.
+func (ecs *EthChainService) monitor(channelId types.Destination, tx *ethTypes.Transaction) {
+	go func() {
+		receipt, err := ecs.txManager.waitMined(context.Background(), tx)
+		if err != nil {
+			ecs.reportFailure(channelId, tx.Hash(), err)
+			return
+		}
+		ecs.out <- NewTransactionConfirmedEvent(channelId, receipt.BlockNumber.Uint64(), receipt.TxHash)
+	}()
+}
+
+// reportFailure reports a failed transaction on the event feed.
+// The event is sent from a new goroutine, since the consumer of the feed may be the caller of SendTransaction.
+func (ecs *EthChainService) reportFailure(channelId types.Destination, txHash common.Hash, err error) {
+	ecs.logger.Printf("transaction for channel %s failed: %v", channelId, err)
+	go func() {
+		ecs.out <- NewTransactionFailedEvent(channelId, 0, txHash, err.Error())
+	}()
+}
+
+// subcribeToEvents subscribes to adjudicator logs and, if startBlock is not zero, replays the logs emitted since startBlock.
+// The subscription is made before the replay, so that no logs are missed in between.
+func (ecs *EthChainService) subcribeToEvents(startBlock uint64) error {
+	ecs.logs = make(chan ethTypes.Log)
+	if ecs.pollInterval > 0 {
+		// Polling starts from the next block, as a subscription would
+		head, err := ecs.chain.HeaderByNumber(context.Background(), nil)
+		if err != nil {
+			return err
+		}
+		ecs.polledTo = head.Number.Uint64()
+	}
+	sub, err := ecs.subscribeLogs(ecs.filterQuery())
+	if err != nil {
+		return err
+	}
+	ecs.sub = sub
+
+	go ecs.listenForLogEvents(startBlock)
 	return nil
 }
 
-func (ecs *EthChainService) listenForLogEvents() {
-	// Subsribe to Adjudicator events
-	query := ethereum.FilterQuery{
+// RegisterChannel subscribes to adjudicator events concerning the channel.
+// Events for other channels are not delivered, except those replayed when the service starts.
+func (ecs *EthChainService) RegisterChannel(channelId types.Destination) error {
+	ecs.mu.Lock()
+	if ecs.channels[channelId] {
+		ecs.mu.Unlock()
+		return nil
+	}
+	ecs.channels[channelId] = true
+	ecs.mu.Unlock()
+	return ecs.resubscribe()
+}
+
+// UnregisterChannel unsubscribes from adjudicator events concerning the channel.
+func (ecs *EthChainService) UnregisterChannel(channelId types.Destination) error {
+	ecs.mu.Lock()
+	if !ecs.channels[channelId] {
+		ecs.mu.Unlock()
+		return nil
+	}
+	delete(ecs.channels, channelId)
+	ecs.mu.Unlock()
+	return ecs.resubscribe()
+}
+
+// filterQuery returns a query for the adjudicator logs concerning the registered channels.
+// Every adjudicator event has the channel id as its first indexed argument.
+func (ecs *EthChainService) filterQuery() ethereum.FilterQuery {
+	ecs.mu.Lock()
+	defer ecs.mu.Unlock()
+
+	// An empty list of topics would match every channel, so the zero channel id (which no channel has) is used when none are registered
+	channelIds := []common.Hash{{}}
+	for id := range ecs.channels {
+		channelIds = append(channelIds, common.Hash(id))
+	}
+	return ethereum.FilterQuery{
 		Addresses: []common.Address{ecs.naAddress},
+		Topics:    [][]common.Hash{nil, channelIds},
 	}
-	logs := make(chan ethTypes.Log)
-	sub, err := ecs.chain.SubscribeFilterLogs(context.Background(), query, logs)
+}
+
+// resubscribe replaces the subscription with one for the currently registered channels.
+// The new subscription is made before the old one is closed, so that no logs are missed; any logs delivered by both are handled once.
+func (ecs *EthChainService) resubscribe() error {
+	sub, err := ecs.subscribeLogs(ecs.filterQuery())
+	if err != nil {
+		return err
+	}
+	ecs.mu.Lock()
+	old := ecs.sub
+	ecs.sub = sub
+	ecs.mu.Unlock()
+
+	// The old subscription may be delivering a log, so it is closed without holding the lock the listener needs to receive it
+	old.Unsubscribe()
+	return nil
+}
+
+// subscription returns the current subscription.
+func (ecs *EthChainService) subscription() ethereum.Subscription {
+	ecs.mu.Lock()
+	defer ecs.mu.Unlock()
+	return ecs.sub
+}
+
+// replayLogs handles the adjudicator logs emitted between startBlock and the chain's current head, and returns the number of the head.
+// Logs are replayed for every channel, since channels are registered after the service starts.
+// If a request to the chain fails, the error is returned along with the number of the last block whose logs were handled (startBlock-1 if none),
+// so that the replay can be resumed from the following block.
+func (ecs *EthChainService) replayLogs(startBlock uint64) (uint64, error) {
+	head, err := ecs.chain.HeaderByNumber(context.Background(), nil)
 	if err != nil {
-		panic(err)
+		return startBlock - 1, fmt.Errorf("error in HeaderByNumber: %w", err)
+	}
+	headNum := head.Number.Uint64()
+
+	query := ethereum.FilterQuery{Addresses: []common.Address{ecs.naAddress}}
+	for from := startBlock; from <= headNum; from += BACKFILL_BATCH_SIZE {
+		to := from + BACKFILL_BATCH_SIZE - 1
+		if to > headNum {
+			to = headNum
+		}
+		query.FromBlock = new(big.Int).SetUint64(from)
+		query.ToBlock = new(big.Int).SetUint64(to)
+		logs, err := ecs.chain.FilterLogs(context.Background(), query)
+		if err != nil {
+			return from - 1, fmt.Errorf("error in FilterLogs: %w", err)
+		}
+		for _, l := range logs {
+			ecs.confirmer.add(l)
+		}
+		ecs.releaseConfirmedLogs(to)
+	}
+	ecs.logger.Printf("replayed logs from block %d to block %d", startBlock, headNum)
+	return headNum, nil
+}
+
+// replayLogsWithRetry replays the logs emitted since startBlock (see replayLogs). If a request to the chain fails, it is logged and
+// the replay resumes from the first block whose logs were not handled, after a delay which doubles with each consecutive failure.
+func (ecs *EthChainService) replayLogsWithRetry(startBlock uint64) uint64 {
+	delay := INITIAL_REPLAY_RETRY_DELAY
+	for {
+		replayedTo, err := ecs.replayLogs(startBlock)
+		if err == nil {
+			return replayedTo
+		}
+		if replayedTo >= startBlock {
+			delay = INITIAL_REPLAY_RETRY_DELAY
+		}
+		startBlock = replayedTo + 1
+		ecs.logger.Printf("could not replay logs from block %d, retrying in %s: %v", startBlock, delay, err)
+		time.Sleep(delay)
+		delay *= 2
+		if delay > MAX_REPLAY_RETRY_DELAY {
+			delay = MAX_REPLAY_RETRY_DELAY
+		}
+	}
+}
+
+// listenForLogEvents replays the logs emitted since startBlock, if it is not zero, and then handles logs delivered by the subscription.
+// Logs which the subscription delivers from replayed blocks are skipped, unless they have since been removed by a reorg.
+func (ecs *EthChainService) listenForLogEvents(startBlock uint64) {
+	replayedTo := uint64(0)
+	if startBlock > 0 {
+		replayedTo = ecs.replayLogsWithRetry(startBlock)
+	}
+
+	// Due to https://github.com/ethereum/go-ethereum/issues/23845 we can't rely on a long running subscription.
+	// Polling does not suffer from this, so the periodic resubscription is only needed when subscribing.
+	var resubscribe <-chan time.Time
+	if ecs.pollInterval == 0 {
+		ticker := time.NewTicker(RESUB_INTERVAL)
+		defer ticker.Stop()
+		resubscribe = ticker.C
 	}
+	checkHead := time.NewTicker(HEAD_POLL_INTERVAL)
+	defer checkHead.Stop()
 	for {
+		sub := ecs.subscription()
 		select {
 		case err := <-sub.Err():
 			if err != nil {
 				panic(err)
 			}
+			if sub != ecs.subscription() {
+				// The subscription was replaced when a channel was registered or unregistered
+				continue
+			}
 
 			// If the error is nil then the subscription was closed and we need to re-subscribe.
 			// This is a workaround for https://github.com/ethereum/go-ethereum/issues/23845
-			var sErr error
-			sub, sErr = ecs.chain.SubscribeFilterLogs(context.Background(), query, logs)
-			if sErr != nil {
+			err = ecs.resubscribe()
+			if err != nil {
 				panic(err)
 			}
 			ecs.logger.Println("resubscribed to filtered logs")
 
-		case <-time.After(RESUB_INTERVAL):
-			// Due to https://github.com/ethereum/go-ethereum/issues/23845 we can't rely on a long running subscription.
-			// We unsub here and recreate the subscription in the next iteration of the select.
-			sub.Unsubscribe()
-		case chainEvent := <-logs:
-			switch chainEvent.Topics[0] {
-			case depositedTopic:
-				nad, err := ecs.na.ParseDeposited(chainEvent)
-				if err != nil {
-					ecs.logger.Printf("error in ParseDeposited: %v", err)
-				}
-
-				event := NewDepositedEvent(nad.Destination, chainEvent.BlockNumber, nad.Asset, nad.AmountDeposited, nad.DestinationHoldings)
-				ecs.out <- event
-			case allocationUpdatedTopic:
-				au, err := ecs.na.ParseAllocationUpdated(chainEvent)
-				if err != nil {
-					ecs.logger.Printf("error in ParseAllocationUpdated: %v", err)
-				}
-
-				tx, pending, err := ecs.chain.TransactionByHash(context.Background(), chainEvent.TxHash)
-				if pending {
-					ecs.logger.Printf("Expected transacion to be part of the chain, but the transaction is pending")
-				}
-				if err != nil {
-					ecs.logger.Printf("error in TransactoinByHash: %v", err)
-				}
-
-				assetAddress, amount, err := getChainHolding(ecs.na, tx, au)
-				if err != nil {
-					ecs.logger.Printf("error in getChainHoldings: %v", err)
-				}
-				event := NewAllocationUpdatedEvent(au.ChannelId, chainEvent.BlockNumber, assetAddress, amount)
-				ecs.out <- event
-			case concludedTopic:
-				ce, err := ecs.na.ParseConcluded(chainEvent)
-				if err != nil {
-					ecs.logger.Printf("error in ParseConcluded: %v", err)
-				}
-
-				event := ConcludedEvent{commonEvent: commonEvent{channelID: ce.ChannelId, BlockNum: chainEvent.BlockNumber}}
-				ecs.out <- event
-
-			default:
-				ecs.logger.Printf("Unknown chain event")
+		case <-resubscribe:
+			err := ecs.resubscribe()
+			if err != nil {
+				panic(err)
+			}
+		case <-checkHead.C:
+			if ecs.confirmer.hasPending() {
+				ecs.releaseConfirmedLogs(0)
+			}
+		case chainEvent := <-ecs.logs:
+			if chainEvent.BlockNumber <= replayedTo && !chainEvent.Removed {
+				continue
+			}
+			if retracted, ok := ecs.confirmer.add(chainEvent); ok {
+				ecs.retract(retracted, chainEvent.BlockNumber)
 			}
+			ecs.releaseConfirmedLogs(chainEvent.BlockNumber)
 		}
 	}
 
 }
 
+// releaseConfirmedLogs emits events for the logs which have enough confirmations.
+// When no confirmations are required, latestSeen (the number of the latest block from which a log has been received) is taken as the chain's head.
+func (ecs *EthChainService) releaseConfirmedLogs(latestSeen uint64) {
+	head := latestSeen
+	if ecs.confirmer.confirmations > 0 {
+		header, err := ecs.chain.HeaderByNumber(context.Background(), nil)
+		if err != nil {
+			ecs.logger.Printf("error in HeaderByNumber: %v", err)
+			return
+		}
+		head = header.Number.Uint64()
+	}
+
+	for _, l := range ecs.confirmer.confirmed(head) {
+		event, err := ecs.eventFromLog(l)
+		if err != nil {
+			ecs.logger.Printf("error handling log %+v: %v", l, err)
+			continue
+		}
+		ecs.confirmer.markReleased(l, event)
+		ecs.out <- event
+	}
+}
+
+// eventFromLog converts an adjudicator log into an event.
+func (ecs *EthChainService) eventFromLog(chainEvent ethTypes.Log) (Event, error) {
+	switch chainEvent.Topics[0] {
+	case depositedTopic:
+		nad, err := ecs.na.ParseDeposited(chainEvent)
+		if err != nil {
+			return nil, fmt.Errorf("error in ParseDeposited: %w", err)
+		}
+		return NewDepositedEvent(nad.Destination, chainEvent.BlockNumber, nad.Asset, nad.AmountDeposited, nad.DestinationHoldings), nil
+	case allocationUpdatedTopic:
+		au, err := ecs.na.ParseAllocationUpdated(chainEvent)
+		if err != nil {
+			return nil, fmt.Errorf("error in ParseAllocationUpdated: %w", err)
+		}
+
+		tx, pending, err := ecs.chain.TransactionByHash(context.Background(), chainEvent.TxHash)
+		if pending {
+			ecs.logger.Printf("Expected transacion to be part of the chain, but the transaction is pending")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error in TransactionByHash: %w", err)
+		}
+
+		assetAddress, amount, err := getChainHolding(ecs.na, tx, au)
+		if err != nil {
+			return nil, fmt.Errorf("error in getChainHoldings: %w", err)
+		}
+		return NewAllocationUpdatedEvent(au.ChannelId, chainEvent.BlockNumber, assetAddress, amount), nil
+	case concludedTopic:
+		ce, err := ecs.na.ParseConcluded(chainEvent)
+		if err != nil {
+			return nil, fmt.Errorf("error in ParseConcluded: %w", err)
+		}
+		return ConcludedEvent{commonEvent: commonEvent{channelID: ce.ChannelId, BlockNum: chainEvent.BlockNumber}}, nil
+	case challengeRegisteredTopic:
+		cr, err := ecs.na.ParseChallengeRegistered(chainEvent)
+		if err != nil {
+			return nil, fmt.Errorf("error in ParseChallengeRegistered: %w", err)
+		}
+		return NewChallengeRegisteredEvent(cr.ChannelId, chainEvent.BlockNumber, cr.TurnNumRecord.Uint64(), cr.FinalizesAt.Uint64(), cr.IsFinal), nil
+	case challengeClearedTopic:
+		cc, err := ecs.na.ParseChallengeCleared(chainEvent)
+		if err != nil {
+			return nil, fmt.Errorf("error in ParseChallengeCleared: %w", err)
+		}
+		return NewChallengeClearedEvent(cc.ChannelId, chainEvent.BlockNumber, cc.NewTurnNumRecord.Uint64()), nil
+	default:
+		return nil, fmt.Errorf("unknown chain event with topic %s", chainEvent.Topics[0])
+	}
+}
+
+// retract emits a RetractedEvent for an event whose log, from the given block, has been removed by a reorg.
+func (ecs *EthChainService) retract(event Event, blockNum uint64) {
+	retraction := NewRetractedEvent(event, blockNum, common.Address{}, nil)
+
+	switch e := event.(type) {
+	case DepositedEvent:
+		retraction.AssetAddress = e.AssetAddress
+	case AllocationUpdatedEvent:
+		retraction.AssetAddress = e.AssetAddress
+	default:
+		ecs.out <- retraction
+		return
+	}
+
+	holdings, err := ecs.na.Holdings(&bind.CallOpts{}, retraction.AssetAddress, event.ChannelID())
+	if err != nil {
+		// The retraction is still delivered, without holdings, so that the objective stops relying on the retracted event
+		ecs.logger.Printf("error reading holdings after a reorg: %v", err)
+	} else {
+		retraction.NowHeld = holdings
+	}
+	ecs.out <- retraction
+}
+
+// GetHoldings returns the amount of the asset held by the adjudicator for the channel.
+func (ecs *EthChainService) GetHoldings(channelId types.Destination, asset common.Address) (*big.Int, error) {
+	return ecs.na.Holdings(&bind.CallOpts{}, asset, channelId)
+}
+
+// GetChainTime returns the timestamp of the latest block.
+func (ecs *EthChainService) GetChainTime() (uint64, error) {
+	header, err := ecs.chain.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		return 0, err
+	}
+	return header.Time, nil
+}
+
+// GetAdjudicationStatus returns the channel's adjudication status, as stored by the adjudicator.
+func (ecs *EthChainService) GetAdjudicationStatus(channelId types.Destination) (protocols.AdjudicationStatus, error) {
+	status, err := ecs.na.UnpackStatus(&bind.CallOpts{}, channelId)
+	if err != nil {
+		return protocols.AdjudicationStatus{}, err
+	}
+	return protocols.AdjudicationStatus{
+		TurnNumRecord: status.TurnNumRecord.Uint64(),
+		FinalizesAt:   status.FinalizesAt.Uint64(),
+		Fingerprint:   status.Fingerprint,
+	}, nil
+}
+
 // EventFeed returns the out chan, and narrows the type so that external consumers may only receive on it.
 func (ecs *EthChainService) EventFeed() <-chan Event {
 	return ecs.out
@@ -211,3 +659,8 @@ func (ecs *EthChainService) GetConsensusAppAddress() types.Address {
 func (ecs *EthChainService) GetVirtualPaymentAppAddress() types.Address {
 	return ecs.virtualPaymentAppAddress
 }
+
+// GetChainId returns the id of the chain
+func (ecs *EthChainService) GetChainId() *big.Int {
+	return ecs.chainId
+}
@@ -61,7 +61,7 @@ func TestEthChainService(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	cs, err := NewEthChainService(client, na, naAddress, caAddress, vpaAddress, txSubmitter, NoopLogger{})
+	cs, err := NewEthChainService(client, na, naAddress, caAddress, vpaAddress, txSubmitter, NoopLogger{}, EthChainServiceOptions{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -73,6 +73,10 @@ func TestEthChainService(t *testing.T) {
 	}
 	channelID := types.Destination(common.HexToHash(`4ebd366d014a173765ba1e50f284c179ade31f20441bec41664712aac6cc461d`))
 	testTx := protocols.NewDepositTransaction(channelID, testDeposit)
+	err = cs.RegisterChannel(channelID)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	out := cs.EventFeed()
 	// Submit transactiom
@@ -0,0 +1,84 @@
+package chainservice
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// subscribeLogs subscribes to the logs matching the query, delivering them on ecs.logs.
+// When a poll interval is configured, the logs are polled for with eth_getLogs rather than subscribed to.
+func (ecs *EthChainService) subscribeLogs(query ethereum.FilterQuery) (ethereum.Subscription, error) {
+	if ecs.pollInterval == 0 {
+		return ecs.chain.SubscribeFilterLogs(context.Background(), query, ecs.logs)
+	}
+	return ecs.pollLogs(query), nil
+}
+
+// pollLogs returns a subscription which polls for the logs matching the query every poll interval, in ranges of at most
+// pollRange blocks. Polling starts from the block after the last one polled by any earlier subscription, so replacing one
+// polling subscription with another leaves no gap. Errors from the node are logged, and the poll is retried at the next interval.
+//
+// Polling cannot observe logs being removed by a reorg, so the Confirmations option should be set when polling.
+func (ecs *EthChainService) pollLogs(query ethereum.FilterQuery) ethereum.Subscription {
+	from := ecs.lastPolledBlock() + 1
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		ticker := time.NewTicker(ecs.pollInterval)
+		defer ticker.Stop()
+		for {
+			head, err := ecs.chain.HeaderByNumber(context.Background(), nil)
+			if err != nil {
+				ecs.logger.Printf("error in HeaderByNumber: %v", err)
+			}
+			for err == nil && from <= head.Number.Uint64() {
+				to := from + ecs.pollRange - 1
+				if to > head.Number.Uint64() {
+					to = head.Number.Uint64()
+				}
+				query.FromBlock = new(big.Int).SetUint64(from)
+				query.ToBlock = new(big.Int).SetUint64(to)
+				var logs []ethTypes.Log
+				logs, err = ecs.chain.FilterLogs(context.Background(), query)
+				if err != nil {
+					ecs.logger.Printf("error in FilterLogs: %v", err)
+					break
+				}
+				for _, l := range logs {
+					select {
+					case ecs.logs <- l:
+					case <-quit:
+						return nil
+					}
+				}
+				ecs.setLastPolledBlock(to)
+				from = to + 1
+			}
+
+			select {
+			case <-ticker.C:
+			case <-quit:
+				return nil
+			}
+		}
+	})
+}
+
+// lastPolledBlock returns the number of the last block whose logs have been polled for.
+func (ecs *EthChainService) lastPolledBlock() uint64 {
+	ecs.mu.Lock()
+	defer ecs.mu.Unlock()
+	return ecs.polledTo
+}
+
+// setLastPolledBlock records that the logs of the block have been polled for.
+func (ecs *EthChainService) setLastPolledBlock(blockNum uint64) {
+	ecs.mu.Lock()
+	defer ecs.mu.Unlock()
+	if blockNum > ecs.polledTo {
+		ecs.polledTo = blockNum
+	}
+}
@@ -2,69 +2,332 @@ package chainservice
 
 import (
 	"fmt"
+	"math/big"
+	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	NitroAdjudicator "github.com/statechannels/go-nitro/client/engine/chainservice/adjudicator"
 	"github.com/statechannels/go-nitro/client/engine/store/safesync"
+	nc "github.com/statechannels/go-nitro/crypto"
 	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/types"
 )
 
-// MockChain mimicks the Ethereum blockchain by keeping track of block numbers and account balances in memory
+// MockChain mimicks the Ethereum blockchain by keeping track of block numbers, account balances and adjudication statuses in memory
 // MockChain accepts transactions and broadcasts events.
+//
+// MockChain keeps its own clock, which starts at the current time and only moves when advanced by AdvanceTime,
+// so that tests can let challenges time out without waiting.
 type MockChain struct {
+	chainId  *big.Int
+	mu       sync.Mutex
 	blockNum uint64
+	// now is the time (in seconds since the Unix epoch) according to the chain.
+	now uint64
 	// holdings tracks funds for each channel.
 	holdings map[types.Destination]types.Funds
-	// out maps addresses to an Event channel. Given that MockChainServices only subscribe
+	// status tracks the adjudication status of each channel which has been challenged, checkpointed or concluded.
+	status map[types.Destination]protocols.AdjudicationStatus
+	// out maps addresses to a subscriber. Given that MockChainServices only subscribe
 	// (and never unsubscribe) to events, this can be converted to a list.
-	out safesync.Map[chan Event]
+	out safesync.Map[*mockSubscriber]
 }
 
-// NewMockChain creates a new MockChain
+// mockSubscriber is an Event channel, together with the channels whose events are sent to it.
+type mockSubscriber struct {
+	events   chan Event
+	channels safesync.Map[bool]
+}
+
+// NewMockChain creates a new MockChain with the chain id of a SimulatedBackend, 1337
 func NewMockChain() *MockChain {
+	return NewMockChainWithChainId(big.NewInt(1337))
+}
+
+// NewMockChainWithChainId creates a new MockChain with the supplied chain id
+func NewMockChainWithChainId(chainId *big.Int) *MockChain {
 	chain := MockChain{}
+	chain.chainId = chainId
 	chain.blockNum = 1
+	chain.now = uint64(time.Now().Unix())
 	chain.holdings = make(map[types.Destination]types.Funds)
-	chain.out = safesync.Map[chan Event]{}
+	chain.status = make(map[types.Destination]protocols.AdjudicationStatus)
+	chain.out = safesync.Map[*mockSubscriber]{}
 	return &chain
 }
 
+// ChainId returns the id of the chain.
+func (mc *MockChain) ChainId() *big.Int {
+	return mc.chainId
+}
+
+// Now returns the time (in seconds since the Unix epoch) according to the chain.
+func (mc *MockChain) Now() uint64 {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	return mc.now
+}
+
+// AdvanceTime moves the chain's clock forward by d.
+func (mc *MockChain) AdvanceTime(d time.Duration) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.now += uint64(d / time.Second)
+}
+
 // SubmitTransaction updates internal state and brodcasts events
 // unlike an ethereum blockchain, Mockhain accepts go-nitro protocols.ChainTransaction
 func (mc *MockChain) SubmitTransaction(tx protocols.ChainTransaction) error {
-	mc.blockNum++
+	events, err := mc.execute(tx)
+	if err != nil {
+		return err
+	}
+	// Events are broadcast without holding the lock, since subscribers may query the chain while handling earlier events
+	for _, event := range events {
+		mc.broadcastEvent(event)
+	}
+	return nil
+}
+
+// execute updates internal state according to the transaction, and returns the events it generates.
+// As the adjudicator does, it rejects transactions which would change the status of a finalized channel.
+func (mc *MockChain) execute(tx protocols.ChainTransaction) ([]Event, error) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	events := []Event{}
+	status := mc.status[tx.ChannelId()]
 	switch tx := tx.(type) {
 	case protocols.DepositTransaction:
+		mc.blockNum++
 		if tx.Deposit.IsNonZero() {
 			mc.holdings[tx.ChannelId()] = mc.holdings[tx.ChannelId()].Add(tx.Deposit)
 		}
 		for address, amount := range tx.Deposit {
-			event := NewDepositedEvent(tx.ChannelId(), mc.blockNum, address, amount, mc.holdings[tx.ChannelId()][address])
-			mc.broadcastEvent(event)
+			events = append(events, NewDepositedEvent(tx.ChannelId(), mc.blockNum, address, amount, mc.holdings[tx.ChannelId()][address]))
+		}
+	case protocols.ChallengeTransaction:
+		if err := mc.checkSupported(tx.ChannelId(), tx.Candidate, status); err != nil {
+			return nil, err
+		}
+		candidate := tx.Candidate.State()
+		if candidate.TurnNum < status.TurnNumRecord {
+			return nil, fmt.Errorf("turn number %d is below the turn number record %d", candidate.TurnNum, status.TurnNumRecord)
+		}
+		challengeHash, err := NitroAdjudicator.HashChallengeMessage(candidate)
+		if err != nil {
+			return nil, err
+		}
+		challenger, err := nc.RecoverEthereumMessageSigner(challengeHash[:], tx.ChallengerSig)
+		if err != nil {
+			return nil, err
+		}
+		if !isParticipant(candidate.Participants, challenger) {
+			return nil, fmt.Errorf("challenger %s is not a participant", challenger)
+		}
+		stateHash, err := candidate.Hash()
+		if err != nil {
+			return nil, err
+		}
+		fingerprint, err := mockFingerprint(stateHash, candidate.Outcome)
+		if err != nil {
+			return nil, err
+		}
+		mc.blockNum++
+		finalizesAt := mc.now + uint64(candidate.ChallengeDuration)
+		mc.status[tx.ChannelId()] = protocols.AdjudicationStatus{TurnNumRecord: candidate.TurnNum, FinalizesAt: finalizesAt, Fingerprint: fingerprint}
+		events = append(events, NewChallengeRegisteredEvent(tx.ChannelId(), mc.blockNum, candidate.TurnNum, finalizesAt, candidate.IsFinal))
+	case protocols.CheckpointTransaction:
+		if err := mc.checkSupported(tx.ChannelId(), tx.Candidate, status); err != nil {
+			return nil, err
+		}
+		turnNum := tx.Candidate.State().TurnNum
+		if turnNum <= status.TurnNumRecord {
+			return nil, fmt.Errorf("turn number %d does not exceed the turn number record %d", turnNum, status.TurnNumRecord)
+		}
+		mc.blockNum++
+		// A checkpoint stores only the turn number record, with zero state and outcome hashes
+		mc.status[tx.ChannelId()] = protocols.AdjudicationStatus{TurnNumRecord: turnNum, Fingerprint: fingerprint(types.Bytes32{}, types.Bytes32{})}
+		if status.FinalizesAt != 0 {
+			events = append(events, NewChallengeClearedEvent(tx.ChannelId(), mc.blockNum, turnNum))
 		}
 	case protocols.WithdrawAllTransaction:
-		for assetAddress := range mc.holdings[tx.ChannelId()] {
-			event := NewAllocationUpdatedEvent(tx.ChannelId(), mc.blockNum, assetAddress, common.Big0)
-			mc.broadcastEvent(event)
+		if status.IsFinalized(mc.now) {
+			return nil, fmt.Errorf("channel %s is finalized", tx.ChannelId())
+		}
+		mc.blockNum++
+		events = append(events, ConcludedEvent{commonEvent{tx.ChannelId(), mc.blockNum}})
+		// As the adjudicator does, record the channel as finalized at the current time, with a turn number record of zero
+		// and a zero state hash
+		payoutEvents, err := mc.transferAll(tx.ChannelId(), types.Bytes32{}, tx.SignedState.State().Outcome, protocols.AdjudicationStatus{FinalizesAt: mc.now})
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, payoutEvents...)
+	case protocols.TransferAllTransaction:
+		if !status.IsFinalized(mc.now) {
+			return nil, fmt.Errorf("channel %s is not finalized", tx.ChannelId())
+		}
+		stateHash, err := tx.State.Hash()
+		if err != nil {
+			return nil, err
+		}
+		fingerprint, err := mockFingerprint(stateHash, tx.State.Outcome)
+		if err != nil {
+			return nil, err
+		}
+		if fingerprint.Cmp(status.Fingerprint) != 0 {
+			return nil, fmt.Errorf("state does not match the finalized state of channel %s", tx.ChannelId())
+		}
+		mc.blockNum++
+		events, err = mc.transferAll(tx.ChannelId(), stateHash, tx.State.Outcome, status)
+		if err != nil {
+			return nil, err
 		}
-		mc.holdings[tx.ChannelId()] = types.Funds{}
 	default:
-		return fmt.Errorf("unexpected transaction type %T", tx)
+		return nil, fmt.Errorf("unexpected transaction type %T", tx)
+	}
+	return events, nil
+}
+
+// checkSupported returns an error unless the candidate state belongs to the channel, is signed by every participant,
+// and the channel has not finalized.
+// Unlike the adjudicator, MockChain does not run the channel's application rules, so proofs are ignored.
+func (mc *MockChain) checkSupported(channelId types.Destination, candidate state.SignedState, status protocols.AdjudicationStatus) error {
+	if candidate.ChannelId() != channelId {
+		return fmt.Errorf("candidate state belongs to channel %s, not %s", candidate.ChannelId(), channelId)
+	}
+	if !candidate.HasAllSignatures() {
+		return fmt.Errorf("candidate state is not signed by every participant")
+	}
+	if status.IsFinalized(mc.now) {
+		return fmt.Errorf("channel %s is finalized", channelId)
 	}
 	return nil
 }
 
+// transferAll pays out the channel's holdings according to the outcome, and returns an AllocationUpdatedEvent for each asset.
+// The channel's status is replaced by the supplied status with the fingerprint of the outcome which remains after the payouts,
+// or cleared if everything has been paid out.
+func (mc *MockChain) transferAll(channelId types.Destination, stateHash types.Bytes32, o outcome.Exit, status protocols.AdjudicationStatus) ([]Event, error) {
+	events := []Event{}
+	remaining := o.Clone()
+	for i, sae := range remaining {
+		held := big.NewInt(0)
+		if h, ok := mc.holdings[channelId][sae.Asset]; ok {
+			held = h
+		}
+		var payouts outcome.Allocations
+		remaining[i].Allocations, payouts = outcome.ComputeTransferEffectsAndInteractions(*held, sae.Allocations, nil)
+		nowHeld := new(big.Int).Sub(held, payouts.Total())
+		if nowHeld.Sign() == 0 {
+			delete(mc.holdings[channelId], sae.Asset)
+		} else {
+			mc.holdings[channelId][sae.Asset] = nowHeld
+		}
+		events = append(events, NewAllocationUpdatedEvent(channelId, mc.blockNum, sae.Asset, nowHeld))
+	}
+	fingerprint, err := mockFingerprint(stateHash, remaining)
+	if err != nil {
+		return nil, err
+	}
+	status.Fingerprint = fingerprint
+	mc.status[channelId] = status
+	if !remaining.TotalAllocated().IsNonZero() {
+		// The adjudicator clears the status of a channel once everything has been paid out
+		delete(mc.status, channelId)
+	}
+	return events, nil
+}
+
+// isParticipant returns true if the address is one of the participants.
+func isParticipant(participants []types.Address, a types.Address) bool {
+	for _, p := range participants {
+		if p == a {
+			return true
+		}
+	}
+	return false
+}
+
+// mockFingerprint returns the fingerprint the adjudicator stores for a channel with the supplied state hash and outcome.
+func mockFingerprint(stateHash types.Bytes32, o outcome.Exit) (*big.Int, error) {
+	outcomeHash, err := o.Hash()
+	if err != nil {
+		return nil, err
+	}
+	return fingerprint(stateHash, outcomeHash), nil
+}
+
+// fingerprint returns the last 160 bits of the hash of the state hash and the outcome hash.
+func fingerprint(stateHash types.Bytes32, outcomeHash types.Bytes32) *big.Int {
+	hash := crypto.Keccak256(stateHash[:], outcomeHash[:])
+	return new(big.Int).SetBytes(hash[12:])
+}
+
+// GetHoldings returns the amount of the asset held for the channel.
+func (mc *MockChain) GetHoldings(channelId types.Destination, asset common.Address) *big.Int {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	if held, ok := mc.holdings[channelId][asset]; ok {
+		return new(big.Int).Set(held)
+	}
+	return big.NewInt(0)
+}
+
+// GetAdjudicationStatus returns the channel's adjudication status, which is zero unless the channel has been challenged, checkpointed or concluded.
+func (mc *MockChain) GetAdjudicationStatus(channelId types.Destination) protocols.AdjudicationStatus {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	status, ok := mc.status[channelId]
+	if !ok {
+		return protocols.AdjudicationStatus{Fingerprint: big.NewInt(0)}
+	}
+	return status
+}
+
+// broadcastEvent sends the event to the subscribers which have registered its channel.
 func (mc *MockChain) broadcastEvent(event Event) {
-	mc.out.Range(func(_ string, channel chan Event) bool {
-		channel <- event
+	mc.out.Range(func(_ string, subscriber *mockSubscriber) bool {
+		if _, registered := subscriber.channels.Load(event.ChannelID().String()); registered {
+			subscriber.events <- event
+		}
 		return true
 	})
 }
 
-// SubscribeToEvents creates, stores, and returns a new Event channel that produces all chain Events
+// reportFailure sends the event to the subscriber, whether or not it has registered the channel, since the subscriber submitted the failed transaction.
+// The event is sent from a new goroutine, since the subscriber may be the caller of SubmitTransaction.
+func (mc *MockChain) reportFailure(a types.Address, event TransactionFailedEvent) {
+	if subscriber, ok := mc.out.Load(a.String()); ok {
+		go func() {
+			subscriber.events <- event
+		}()
+	}
+}
+
+// SubscribeToEvents creates, stores, and returns a new Event channel that produces the chain Events of the channels registered by the subscriber
 func (mc *MockChain) SubscribeToEvents(a types.Address) <-chan Event {
 	// Use a buffered channel so we don't have to worry about blocking on writing to the channel.
-	c := make(chan Event, 10)
-	mc.out.Store(a.String(), c)
-	return c
+	subscriber := &mockSubscriber{events: make(chan Event, 10)}
+	mc.out.Store(a.String(), subscriber)
+	return subscriber.events
+}
+
+// RegisterChannel starts sending the events of the channel to the subscriber.
+func (mc *MockChain) RegisterChannel(a types.Address, channelId types.Destination) {
+	if subscriber, ok := mc.out.Load(a.String()); ok {
+		subscriber.channels.Store(channelId.String(), true)
+	}
+}
+
+// UnregisterChannel stops sending the events of the channel to the subscriber.
+func (mc *MockChain) UnregisterChannel(a types.Address, channelId types.Destination) {
+	if subscriber, ok := mc.out.Load(a.String()); ok {
+		subscriber.channels.Delete(channelId.String())
+	}
 }
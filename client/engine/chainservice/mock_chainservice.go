@@ -1,6 +1,8 @@
 package chainservice
 
 import (
+	"math/big"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/types"
@@ -9,13 +11,14 @@ import (
 // MockChainService adheres to the ChainService interface. The constructor accepts a MockChain, which allows multiple clients to share the same, in-memory chain.
 type MockChainService struct {
 	chain      *MockChain
+	address    common.Address
 	txListener chan protocols.ChainTransaction // this is used to broadcast transactions that have been received
 	eventFeed  <-chan Event
 }
 
 // NewMockChainService returns a new MockChainService.
 func NewMockChainService(chain *MockChain, address common.Address) *MockChainService {
-	mc := MockChainService{chain: chain}
+	mc := MockChainService{chain: chain, address: address}
 	mc.eventFeed = chain.SubscribeToEvents(address)
 	return &mc
 }
@@ -29,12 +32,18 @@ func NewMockChainWithTransactionListener(chain *MockChain, address common.Addres
 }
 
 // SendTransaction responds to the given tx.
+// As with the EthChainService, a transaction which the chain rejects is reported on the event feed with a TransactionFailedEvent,
+// rather than returned as an error.
 func (mc *MockChainService) SendTransaction(tx protocols.ChainTransaction) error {
 	if mc.txListener != nil {
 		mc.txListener <- tx
 	}
 
-	return mc.chain.SubmitTransaction(tx)
+	err := mc.chain.SubmitTransaction(tx)
+	if err != nil {
+		mc.chain.reportFailure(mc.address, NewTransactionFailedEvent(tx.ChannelId(), 0, common.Hash{}, err.Error()))
+	}
+	return nil
 }
 
 // GetConsensusAppAddress returns the zero address, since the mock chain will not run any application logic.
@@ -47,6 +56,38 @@ func (mc *MockChainService) GetVirtualPaymentAppAddress() types.Address {
 	return types.Address{}
 }
 
+// GetChainId returns the id of the mock chain.
+func (mc *MockChainService) GetChainId() *big.Int {
+	return mc.chain.ChainId()
+}
+
+// RegisterChannel starts the delivery of events concerning the channel.
+func (mc *MockChainService) RegisterChannel(channelId types.Destination) error {
+	mc.chain.RegisterChannel(mc.address, channelId)
+	return nil
+}
+
+// UnregisterChannel stops the delivery of events concerning the channel.
+func (mc *MockChainService) UnregisterChannel(channelId types.Destination) error {
+	mc.chain.UnregisterChannel(mc.address, channelId)
+	return nil
+}
+
+// GetHoldings returns the amount of the asset held by the mock chain for the channel.
+func (mc *MockChainService) GetHoldings(channelId types.Destination, asset common.Address) (*big.Int, error) {
+	return mc.chain.GetHoldings(channelId, asset), nil
+}
+
+// GetAdjudicationStatus returns the channel's adjudication status on the mock chain.
+func (mc *MockChainService) GetAdjudicationStatus(channelId types.Destination) (protocols.AdjudicationStatus, error) {
+	return mc.chain.GetAdjudicationStatus(channelId), nil
+}
+
+// GetChainTime returns the time according to the mock chain's clock.
+func (mc *MockChainService) GetChainTime() (uint64, error) {
+	return mc.chain.Now(), nil
+}
+
 func (mc *MockChainService) EventFeed() <-chan Event {
 	return mc.eventFeed
 }
@@ -3,8 +3,10 @@ package chainservice
 import (
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/internal/testactors"
 	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/types"
 )
@@ -27,6 +29,11 @@ func TestDeposit(t *testing.T) {
 		common.HexToAddress("0x00"): big.NewInt(1),
 	}
 	testTx := protocols.NewDepositTransaction(types.Destination(common.HexToHash(`4ebd366d014a173765ba1e50f284c179ade31f20441bec41664712aac6cc461d`)), testDeposit)
+	for _, cs := range []*MockChainService{chainServiceA, chainServiceB} {
+		if err := cs.RegisterChannel(testTx.ChannelId()); err != nil {
+			t.Fatal(err)
+		}
+	}
 
 	// Send one transaction and receive one event from it.
 	err := chainServiceA.SendTransaction(testTx)
@@ -69,3 +76,138 @@ func checkReceivedEventIsValid(t *testing.T, receivedEvent Event, holdings types
 		t.Fatalf(`holdings mismatch: expected %v but got %v`, holdings[depositEvent.AssetAddress], depositEvent.NowHeld)
 	}
 }
+
+func TestRegisterChannel(t *testing.T) {
+	chain := NewMockChain()
+	chainService := NewMockChainService(chain, types.Address(common.HexToAddress(`a`)))
+	registered := types.Destination(common.HexToHash(`a`))
+	unregistered := types.Destination(common.HexToHash(`b`))
+	deposit := types.Funds{common.HexToAddress("0x00"): big.NewInt(1)}
+
+	err := chainService.RegisterChannel(registered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, channelId := range []types.Destination{unregistered, registered} {
+		err = chainService.SendTransaction(protocols.NewDepositTransaction(channelId, deposit))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := (<-chainService.EventFeed()).ChannelID(); got != registered {
+		t.Fatalf("expected only events for channel %s, got an event for %s", registered, got)
+	}
+
+	err = chainService.UnregisterChannel(registered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = chainService.SendTransaction(protocols.NewDepositTransaction(registered, deposit))
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case event := <-chainService.EventFeed():
+		t.Fatalf("expected no events after unregistering the channel, got %+v", event)
+	default:
+	}
+}
+
+func TestChallenge(t *testing.T) {
+	chain := NewMockChain()
+	chainService := NewMockChainService(chain, Alice.Address())
+	challenged := signedChallengeState(t, types.Address{}, 5)
+	cId := challenged.ChannelId()
+	err := chainService.RegisterChannel(cId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = chainService.SendTransaction(protocols.NewDepositTransaction(cId, types.Funds{common.Address{}: big.NewInt(2)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-chainService.EventFeed()
+
+	// Only participants can challenge
+	expectTransactionFailure(t, chainService, protocols.NewChallengeTransaction(cId, challenged, nil, challengerSig(t, testactors.Irene, challenged.State())), "a challenge by a non-participant")
+
+	err = chainService.SendTransaction(protocols.NewChallengeTransaction(cId, challenged, nil, challengerSig(t, Alice, challenged.State())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	registered, ok := (<-chainService.EventFeed()).(ChallengeRegisteredEvent)
+	if !ok || registered.TurnNumRecord != 5 || registered.FinalizesAt != chain.Now()+60 {
+		t.Fatalf("expected a challenge with turn number 5 finalizing in 60 seconds, got %+v", registered)
+	}
+	status, err := chainService.GetAdjudicationStatus(cId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.TurnNumRecord != 5 || status.FinalizesAt != registered.FinalizesAt {
+		t.Fatalf("expected the status to record the challenge, got %+v", status)
+	}
+
+	// A checkpoint must increase the turn number record, and clears the challenge
+	expectTransactionFailure(t, chainService, protocols.NewCheckpointTransaction(cId, challenged, nil), "a checkpoint which does not increase the turn number record")
+	checkpointed := signedChallengeState(t, types.Address{}, 6)
+	err = chainService.SendTransaction(protocols.NewCheckpointTransaction(cId, checkpointed, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cleared, ok := (<-chainService.EventFeed()).(ChallengeClearedEvent)
+	if !ok || cleared.NewTurnNumRecord != 6 {
+		t.Fatalf("expected the challenge to be cleared with turn number 6, got %+v", cleared)
+	}
+
+	// A challenge which is not cleared finalizes the channel once its challenge duration has passed
+	err = chainService.SendTransaction(protocols.NewChallengeTransaction(cId, checkpointed, nil, challengerSig(t, Bob, checkpointed.State())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-chainService.EventFeed()
+	chain.AdvanceTime(59 * time.Second)
+	expectTransactionFailure(t, chainService, protocols.NewTransferAllTransaction(cId, checkpointed.State()), "a transfer from a channel which has not finalized")
+	chain.AdvanceTime(time.Second)
+	status, err = chainService.GetAdjudicationStatus(cId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.IsFinalized(chain.Now()) {
+		t.Fatalf("expected the channel to be finalized, got %+v", status)
+	}
+	expectTransactionFailure(t, chainService, protocols.NewCheckpointTransaction(cId, signedChallengeState(t, types.Address{}, 7), nil), "a checkpoint of a finalized channel")
+
+	expectTransactionFailure(t, chainService, protocols.NewTransferAllTransaction(cId, challenged.State()), "a transfer with a state other than the finalized one")
+	err = chainService.SendTransaction(protocols.NewTransferAllTransaction(cId, checkpointed.State()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated, ok := (<-chainService.EventFeed()).(AllocationUpdatedEvent)
+	if !ok || updated.AssetAmount.Sign() != 0 {
+		t.Fatalf("expected the holdings to be paid out, got %+v", updated)
+	}
+	status, err = chainService.GetAdjudicationStatus(cId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.FinalizesAt != 0 || status.Fingerprint.Sign() != 0 {
+		t.Fatalf("expected the status to be cleared once everything is paid out, got %+v", status)
+	}
+}
+
+// expectTransactionFailure submits a transaction which the chain should reject, and checks that the rejection is reported on the event feed.
+func expectTransactionFailure(t *testing.T, chainService *MockChainService, tx protocols.ChainTransaction, description string) {
+	t.Helper()
+	err := chainService.SendTransaction(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case event := <-chainService.EventFeed():
+		if _, ok := event.(TransactionFailedEvent); !ok {
+			t.Fatalf("expected %s to fail, got %+v", description, event)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected %s to fail", description)
+	}
+}
@@ -1,6 +1,7 @@
 package chainservice
 
 import (
+	"context"
 	"errors"
 	"io"
 	"math/big"
@@ -9,12 +10,12 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	NitroAdjudicator "github.com/statechannels/go-nitro/client/engine/chainservice/adjudicator"
 	ConsensusApp "github.com/statechannels/go-nitro/client/engine/chainservice/consensusapp"
 	Token "github.com/statechannels/go-nitro/client/engine/chainservice/erc20"
 	VirtualPaymentApp "github.com/statechannels/go-nitro/client/engine/chainservice/virtualpaymentapp"
-	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/types"
 )
 
@@ -47,13 +48,27 @@ type SimulatedBackendChainService struct {
 // and listens to events from an eventSource
 func NewSimulatedBackendChainService(sim simulatedChain, bindings bindings,
 	txSigner *bind.TransactOpts, logDestination io.Writer) (ChainService, error) {
-	ethChainService, err := NewEthChainService(sim,
-		bindings.Adjudicator.Contract,
+	return NewSimulatedBackendChainServiceWithStore(sim, bindings, txSigner, logDestination, nil)
+}
+
+// NewSimulatedBackendChainServiceWithStore is like NewSimulatedBackendChainService, but replays the events emitted since the
+// store's last block (see EthChainServiceOptions.Store), as a node restarting with a persisted store would.
+func NewSimulatedBackendChainServiceWithStore(sim simulatedChain, bindings bindings,
+	txSigner *bind.TransactOpts, logDestination io.Writer, store BlockNumStore) (ChainService, error) {
+	chain := autoMiningChain{sim}
+	// Bind the adjudicator to the auto-mining chain, so that transactions sent to it are mined
+	na, err := NitroAdjudicator.NewNitroAdjudicator(bindings.Adjudicator.Address, chain)
+	if err != nil {
+		return &SimulatedBackendChainService{}, err
+	}
+	ethChainService, err := NewEthChainService(chain,
+		na,
 		bindings.Adjudicator.Address,
 		bindings.ConsensusApp.Address,
 		bindings.VirtualPaymentApp.Address,
 		txSigner,
-		logDestination)
+		logDestination,
+		EthChainServiceOptions{ChainId: big.NewInt(1337), Store: store}) // 1337 according to docs on SimulatedBackend
 
 	if err != nil {
 		return &SimulatedBackendChainService{}, err
@@ -61,13 +76,19 @@ func NewSimulatedBackendChainService(sim simulatedChain, bindings bindings,
 	return &SimulatedBackendChainService{sim: sim, EthChainService: ethChainService}, nil
 }
 
-// SendTransaction sends the transaction and blocks until it has been mined.
-func (sbcs *SimulatedBackendChainService) SendTransaction(tx protocols.ChainTransaction) error {
-	err := sbcs.EthChainService.SendTransaction(tx)
+// autoMiningChain mines a block for every transaction sent to the simulated chain, so that transactions which depend
+// on earlier ones (such as a deposit of tokens which depends on their approval) can be sent in turn.
+type autoMiningChain struct {
+	simulatedChain
+}
+
+// SendTransaction sends the transaction and mines a block containing it.
+func (c autoMiningChain) SendTransaction(ctx context.Context, tx *ethTypes.Transaction) error {
+	err := c.simulatedChain.SendTransaction(ctx, tx)
 	if err != nil {
 		return err
 	}
-	sbcs.sim.Commit()
+	c.Commit()
 	return nil
 }
 
@@ -2,14 +2,23 @@ package chainservice
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"math/big"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/statechannels/go-nitro/channel/state"
 	"github.com/statechannels/go-nitro/channel/state/outcome"
+	NitroAdjudicator "github.com/statechannels/go-nitro/client/engine/chainservice/adjudicator"
+	nc "github.com/statechannels/go-nitro/crypto"
 	"github.com/statechannels/go-nitro/internal/testactors"
 	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/types"
@@ -36,6 +45,20 @@ var concludeOutcome = outcome.Exit{
 	},
 }
 
+// nextLogEvent returns the next event on the feed which was emitted by the adjudicator, skipping events
+// which report the outcome of our own transactions.
+func nextLogEvent(out <-chan Event) Event {
+	for {
+		event := <-out
+		switch event.(type) {
+		case TransactionConfirmedEvent, TransactionFailedEvent:
+			continue
+		default:
+			return event
+		}
+	}
+}
+
 type NoopLogger struct{}
 
 func (l NoopLogger) Write(p []byte) (n int, err error) {
@@ -61,6 +84,10 @@ func TestDepositSimulatedBackendChainService(t *testing.T) {
 	}
 	channelID := types.Destination(common.HexToHash(`4ebd366d014a173765ba1e50f284c179ade31f20441bec41664712aac6cc461d`))
 	testTx := protocols.NewDepositTransaction(channelID, testDeposit)
+	err = cs.RegisterChannel(channelID)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	out := cs.EventFeed()
 	// Submit transactiom
@@ -70,11 +97,20 @@ func TestDepositSimulatedBackendChainService(t *testing.T) {
 	}
 
 	// Check that the recieved events matches the expected event
-	for i := 0; i < 2; i++ {
+	confirmations := 0
+	for i := 0; i < 2; {
 		receivedEvent := <-out
+		if _, ok := receivedEvent.(TransactionConfirmedEvent); ok {
+			confirmations++
+			continue
+		}
+		i++
 		dEvent := receivedEvent.(DepositedEvent)
 		expectedEvent := NewDepositedEvent(channelID, 2, dEvent.AssetAddress, testDeposit[dEvent.AssetAddress], testDeposit[dEvent.AssetAddress])
-		if diff := cmp.Diff(expectedEvent, dEvent, cmp.AllowUnexported(DepositedEvent{}, commonEvent{}, big.Int{})); diff != "" {
+		// Each transaction is mined in its own block, and the token deposit waits for the token approval to be mined,
+		// so the block in which each deposit lands depends on the order in which the assets are deposited
+		ignoreBlockNum := cmpopts.IgnoreFields(commonEvent{}, "BlockNum")
+		if diff := cmp.Diff(expectedEvent, dEvent, cmp.AllowUnexported(DepositedEvent{}, commonEvent{}, big.Int{}), ignoreBlockNum); diff != "" {
 			t.Fatalf("Received event did not match expectation; (-want +got):\n%s", diff)
 		}
 		delete(testDeposit, dEvent.AssetAddress)
@@ -84,6 +120,15 @@ func TestDepositSimulatedBackendChainService(t *testing.T) {
 		t.Fatalf("Mismatch between the deposit transaction and the received events")
 	}
 
+	// Each deposit transaction is confirmed
+	for confirmations < 2 {
+		confirmed, ok := (<-out).(TransactionConfirmedEvent)
+		if !ok || confirmed.ChannelID() != channelID {
+			t.Fatalf("expected a confirmation of a deposit into channel %s, got %+v", channelID, confirmed)
+		}
+		confirmations++
+	}
+
 	sim.Close()
 }
 
@@ -123,13 +168,17 @@ func TestConcludeSimulatedBackendChainService(t *testing.T) {
 		common.HexToAddress("0x00"): big.NewInt(3),
 	}
 	cId := concludeState.ChannelId()
+	err = cs.RegisterChannel(cId)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	depositTx := protocols.NewDepositTransaction(cId, testDeposit)
 	err = cs.SendTransaction(depositTx)
 	if err != nil {
 		t.Fatal(err)
 	}
-	<-out
+	nextLogEvent(out)
 
 	signedConcludeState := state.NewSignedState(concludeState)
 	err = signedConcludeState.AddSignature(aSig)
@@ -146,14 +195,14 @@ func TestConcludeSimulatedBackendChainService(t *testing.T) {
 		t.Fatal(err)
 	}
 	// Check that the recieved event matches the expected event
-	concludedEvent := <-out
+	concludedEvent := nextLogEvent(out)
 	expectedEvent := ConcludedEvent{commonEvent: commonEvent{channelID: cId, BlockNum: 3}}
 	if diff := cmp.Diff(expectedEvent, concludedEvent, cmp.AllowUnexported(ConcludedEvent{}, commonEvent{})); diff != "" {
 		t.Fatalf("Received event did not match expectation; (-want +got):\n%s", diff)
 	}
 
 	// Check that the recieved event matches the expected event
-	allocationUpdatedEvent := <-out
+	allocationUpdatedEvent := nextLogEvent(out)
 	expectedEvent2 := NewAllocationUpdatedEvent(cId, 3, common.Address{}, new(big.Int).SetInt64(1))
 
 	if diff := cmp.Diff(expectedEvent2, allocationUpdatedEvent, cmp.AllowUnexported(AllocationUpdatedEvent{}, commonEvent{}, big.Int{})); diff != "" {
@@ -175,3 +224,457 @@ func TestConcludeSimulatedBackendChainService(t *testing.T) {
 	// Not sure if this is necessary
 	sim.Close()
 }
+
+func TestFailedDepositSimulatedBackendChainService(t *testing.T) {
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Close()
+
+	// The second account holds no tokens, so its deposit would revert
+	cs, err := NewSimulatedBackendChainService(sim, bindings, ethAccounts[1], NoopLogger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	channelID := types.Destination(common.HexToHash(`4ebd366d014a173765ba1e50f284c179ade31f20441bec41664712aac6cc461d`))
+	testTx := protocols.NewDepositTransaction(channelID, types.Funds{bindings.Token.Address: big.NewInt(1)})
+
+	err = cs.SendTransaction(testTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed, ok := (<-cs.EventFeed()).(TransactionFailedEvent)
+	if !ok || failed.ChannelID() != channelID || failed.Reason == "" {
+		t.Fatalf("expected the deposit into channel %s to fail with a reason, got %+v", channelID, failed)
+	}
+}
+
+func TestReplayEventsSimulatedBackendChainService(t *testing.T) {
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Close()
+
+	cs, err := NewSimulatedBackendChainService(sim, bindings, ethAccounts[0], NoopLogger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	channelID := types.Destination(common.HexToHash(`4ebd366d014a173765ba1e50f284c179ade31f20441bec41664712aac6cc461d`))
+	deposit := protocols.NewDepositTransaction(channelID, types.Funds{common.Address{}: big.NewInt(1)})
+	err = cs.RegisterChannel(channelID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cs.SendTransaction(deposit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := nextLogEvent(cs.EventFeed()).(DepositedEvent)
+
+	// A chain service started later, from the checkpointed block of the first deposit, replays that deposit
+	chain := autoMiningChain{sim}
+	na, err := NitroAdjudicator.NewNitroAdjudicator(bindings.Adjudicator.Address, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restarted, err := NewEthChainService(chain, na, bindings.Adjudicator.Address, bindings.ConsensusApp.Address, bindings.VirtualPaymentApp.Address,
+		ethAccounts[0], NoopLogger{}, EthChainServiceOptions{ChainId: big.NewInt(1337), StartBlock: first.BlockNum})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := restarted.EventFeed()
+	replayed := nextLogEvent(out)
+	if diff := cmp.Diff(first, replayed, cmp.AllowUnexported(DepositedEvent{}, commonEvent{}, big.Int{})); diff != "" {
+		t.Fatalf("Replayed event did not match the original; (-want +got):\n%s", diff)
+	}
+	err = restarted.RegisterChannel(channelID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Later events are delivered live, without duplicating the replayed event
+	err = restarted.SendTransaction(deposit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second := nextLogEvent(out).(DepositedEvent)
+	if second.BlockNum <= first.BlockNum || second.NowHeld.Cmp(big.NewInt(2)) != 0 {
+		t.Fatalf("expected the second deposit in a later block, got %+v", second)
+	}
+}
+
+// flakyLogsChain fails the first request for logs, as a provider which is briefly unavailable might.
+type flakyLogsChain struct {
+	autoMiningChain
+	failed *int32
+}
+
+func (c flakyLogsChain) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]ethTypes.Log, error) {
+	if atomic.CompareAndSwapInt32(c.failed, 0, 1) {
+		return nil, errors.New("provider unavailable")
+	}
+	return c.autoMiningChain.FilterLogs(ctx, query)
+}
+
+func TestReplayEventsAfterFailureSimulatedBackendChainService(t *testing.T) {
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Close()
+
+	cs, err := NewSimulatedBackendChainService(sim, bindings, ethAccounts[0], NoopLogger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	channelID := types.Destination(common.HexToHash(`4ebd366d014a173765ba1e50f284c179ade31f20441bec41664712aac6cc461d`))
+	err = cs.RegisterChannel(channelID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cs.SendTransaction(protocols.NewDepositTransaction(channelID, types.Funds{common.Address{}: big.NewInt(1)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := nextLogEvent(cs.EventFeed()).(DepositedEvent)
+
+	// The replay is retried, rather than abandoned, when the chain fails to return the logs
+	chain := flakyLogsChain{autoMiningChain{sim}, new(int32)}
+	na, err := NitroAdjudicator.NewNitroAdjudicator(bindings.Adjudicator.Address, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restarted, err := NewEthChainService(chain, na, bindings.Adjudicator.Address, bindings.ConsensusApp.Address, bindings.VirtualPaymentApp.Address,
+		ethAccounts[0], NoopLogger{}, EthChainServiceOptions{ChainId: big.NewInt(1337), StartBlock: first.BlockNum})
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed := nextLogEvent(restarted.EventFeed())
+	if diff := cmp.Diff(first, replayed, cmp.AllowUnexported(DepositedEvent{}, commonEvent{}, big.Int{})); diff != "" {
+		t.Fatalf("Replayed event did not match the original; (-want +got):\n%s", diff)
+	}
+}
+
+func TestRegisterChannelSimulatedBackendChainService(t *testing.T) {
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Close()
+
+	cs, err := NewSimulatedBackendChainService(sim, bindings, ethAccounts[0], NoopLogger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	registered := types.Destination(common.HexToHash(`4ebd366d014a173765ba1e50f284c179ade31f20441bec41664712aac6cc461d`))
+	unregistered := types.Destination(common.HexToHash(`e0fa6e0b5bf4b4dd7e1ac14dd3e4f2ea64fe2b9e4b3c2cd0ed2dc3c2e2c1e3d4`))
+	err = cs.RegisterChannel(registered)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, channelID := range []types.Destination{unregistered, registered} {
+		err = cs.SendTransaction(protocols.NewDepositTransaction(channelID, types.Funds{common.Address{}: big.NewInt(1)}))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := nextLogEvent(cs.EventFeed()).ChannelID(); got != registered {
+		t.Fatalf("expected only events for channel %s, got an event for %s", registered, got)
+	}
+
+	// Once unregistered, the channel's events are no longer delivered
+	err = cs.UnregisterChannel(registered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cs.RegisterChannel(unregistered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, channelID := range []types.Destination{registered, unregistered} {
+		err = cs.SendTransaction(protocols.NewDepositTransaction(channelID, types.Funds{common.Address{}: big.NewInt(1)}))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := nextLogEvent(cs.EventFeed()).ChannelID(); got != unregistered {
+		t.Fatalf("expected only events for channel %s, got an event for %s", unregistered, got)
+	}
+}
+
+func TestPollingSimulatedBackendChainService(t *testing.T) {
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Close()
+
+	chain := autoMiningChain{sim}
+	na, err := NitroAdjudicator.NewNitroAdjudicator(bindings.Adjudicator.Address, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Logs are requested one block at a time
+	cs, err := NewEthChainService(chain, na, bindings.Adjudicator.Address, bindings.ConsensusApp.Address, bindings.VirtualPaymentApp.Address,
+		ethAccounts[0], NoopLogger{}, EthChainServiceOptions{ChainId: big.NewInt(1337), PollInterval: 10 * time.Millisecond, PollRange: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	channelID := types.Destination(common.HexToHash(`4ebd366d014a173765ba1e50f284c179ade31f20441bec41664712aac6cc461d`))
+	err = cs.RegisterChannel(channelID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deposit := protocols.NewDepositTransaction(channelID, types.Funds{common.Address{}: big.NewInt(1)})
+	for i := 0; i < 2; i++ {
+		err = cs.SendTransaction(deposit)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := int64(1); i <= 2; i++ {
+		deposited := nextLogEvent(cs.EventFeed()).(DepositedEvent)
+		if deposited.ChannelID() != channelID || deposited.NowHeld.Cmp(big.NewInt(i)) != 0 {
+			t.Fatalf("expected a deposit into channel %s leaving %d held, got %+v", channelID, i, deposited)
+		}
+	}
+}
+
+func TestAdjudicationStatusSimulatedBackendChainService(t *testing.T) {
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Close()
+	cs, err := NewSimulatedBackendChainService(sim, bindings, ethAccounts[0], NoopLogger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	concluded := signedConcludeState(t, bindings.ConsensusApp.Address)
+	cId := concluded.State().ChannelId()
+	status, err := cs.GetAdjudicationStatus(cId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.FinalizesAt != 0 || status.Fingerprint.Sign() != 0 {
+		t.Fatalf("expected an empty status for a channel which has not been concluded, got %+v", status)
+	}
+
+	// Only the first allocation of the outcome can be paid out
+	err = cs.SendTransaction(protocols.NewDepositTransaction(cId, types.Funds{common.Address{}: big.NewInt(1)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	held, err := cs.GetHoldings(cId, common.Address{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if held.Cmp(big.NewInt(1)) != 0 {
+		t.Fatalf("expected holdings of 1, got %v", held)
+	}
+	err = cs.SendTransaction(protocols.NewWithdrawAllTransaction(cId, concluded))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	held, err = cs.GetHoldings(cId, common.Address{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if held.Sign() != 0 {
+		t.Fatalf("expected the holdings to be paid out, got %v", held)
+	}
+	status, err = cs.GetAdjudicationStatus(cId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remaining := concluded.State().Outcome.Clone()
+	remaining[0].Allocations[0].Amount = big.NewInt(0)
+	fingerprint, err := mockFingerprint(types.Bytes32{}, remaining)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.TurnNumRecord != 0 || status.FinalizesAt == 0 || status.Fingerprint.Cmp(fingerprint) != 0 {
+		t.Fatalf("expected a finalized status with fingerprint %v, got %+v", fingerprint, status)
+	}
+
+	// The MockChain reports the same status
+	mockChain := NewMockChainService(NewMockChain(), Alice.Address())
+	err = mockChain.SendTransaction(protocols.NewDepositTransaction(cId, types.Funds{common.Address{}: big.NewInt(1)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mockChain.SendTransaction(protocols.NewWithdrawAllTransaction(cId, concluded))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mockStatus, err := mockChain.GetAdjudicationStatus(cId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mockStatus.TurnNumRecord != 0 || mockStatus.FinalizesAt == 0 || mockStatus.Fingerprint.Cmp(fingerprint) != 0 {
+		t.Fatalf("expected the mock chain to report a finalized status with fingerprint %v, got %+v", fingerprint, mockStatus)
+	}
+}
+
+func TestChallengeSimulatedBackendChainService(t *testing.T) {
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Close()
+	cs, err := NewSimulatedBackendChainService(sim, bindings, ethAccounts[0], NoopLogger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mockChain := NewMockChain()
+	mock := NewMockChainService(mockChain, Alice.Address())
+
+	challenged := signedChallengeState(t, bindings.ConsensusApp.Address, 5)
+	checkpointed := signedChallengeState(t, bindings.ConsensusApp.Address, 6)
+	cId := challenged.ChannelId()
+
+	// expectSameStatus checks that the MockChain records the same turn number and fingerprint as the adjudicator
+	expectSameStatus := func() protocols.AdjudicationStatus {
+		t.Helper()
+		status, err := cs.GetAdjudicationStatus(cId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mockStatus, err := mock.GetAdjudicationStatus(cId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status.TurnNumRecord != mockStatus.TurnNumRecord || status.Fingerprint.Cmp(mockStatus.Fingerprint) != 0 {
+			t.Fatalf("expected the mock chain to report status %+v, got %+v", status, mockStatus)
+		}
+		return status
+	}
+
+	for _, chainService := range []ChainService{cs, mock} {
+		err = chainService.RegisterChannel(cId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tx := range []protocols.ChainTransaction{
+			protocols.NewDepositTransaction(cId, types.Funds{common.Address{}: big.NewInt(2)}),
+			protocols.NewChallengeTransaction(cId, challenged, nil, challengerSig(t, Alice, challenged.State())),
+		} {
+			err = chainService.SendTransaction(tx)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	nextLogEvent(cs.EventFeed())
+	registered, ok := nextLogEvent(cs.EventFeed()).(ChallengeRegisteredEvent)
+	if !ok || registered.TurnNumRecord != 5 {
+		t.Fatalf("expected a challenge with turn number 5, got %+v", registered)
+	}
+	if status := expectSameStatus(); status.FinalizesAt != registered.FinalizesAt {
+		t.Fatalf("expected the channel to finalize at %d, got %+v", registered.FinalizesAt, status)
+	}
+
+	for _, chainService := range []ChainService{cs, mock} {
+		err = chainService.SendTransaction(protocols.NewCheckpointTransaction(cId, checkpointed, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	cleared, ok := nextLogEvent(cs.EventFeed()).(ChallengeClearedEvent)
+	if !ok || cleared.NewTurnNumRecord != 6 {
+		t.Fatalf("expected the challenge to be cleared with turn number 6, got %+v", cleared)
+	}
+	expectSameStatus()
+
+	// Challenge again, and pay out once the challenge has timed out
+	for _, chainService := range []ChainService{cs, mock} {
+		err = chainService.SendTransaction(protocols.NewChallengeTransaction(cId, checkpointed, nil, challengerSig(t, Bob, checkpointed.State())))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	nextLogEvent(cs.EventFeed())
+	expectSameStatus()
+	err = sim.AdjustTime(time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sim.Commit()
+	mockChain.AdvanceTime(time.Minute)
+
+	for _, chainService := range []ChainService{cs, mock} {
+		err = chainService.SendTransaction(protocols.NewTransferAllTransaction(cId, checkpointed.State()))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	updated, ok := nextLogEvent(cs.EventFeed()).(AllocationUpdatedEvent)
+	if !ok || updated.AssetAmount.Sign() != 0 {
+		t.Fatalf("expected the holdings to be paid out, got %+v", updated)
+	}
+	expectSameStatus()
+}
+
+// signedConcludeState returns a final state with the concludeOutcome, signed by Alice and Bob.
+func signedConcludeState(t *testing.T, appDefinition types.Address) state.SignedState {
+	return signedByAll(t, state.State{
+		ChainId:           big.NewInt(1337),
+		Participants:      []types.Address{Alice.Address(), Bob.Address()},
+		ChannelNonce:      37140676581,
+		AppDefinition:     appDefinition,
+		ChallengeDuration: 0,
+		AppData:           []byte{},
+		Outcome:           concludeOutcome.Clone(),
+		TurnNum:           uint64(2),
+		IsFinal:           true,
+	})
+}
+
+// signedChallengeState returns a state with the concludeOutcome and a challenge duration of a minute, signed by Alice and Bob.
+func signedChallengeState(t *testing.T, appDefinition types.Address, turnNum uint64) state.SignedState {
+	return signedByAll(t, state.State{
+		ChainId:           big.NewInt(1337),
+		Participants:      []types.Address{Alice.Address(), Bob.Address()},
+		ChannelNonce:      37140676582,
+		AppDefinition:     appDefinition,
+		ChallengeDuration: 60,
+		AppData:           []byte{},
+		Outcome:           concludeOutcome.Clone(),
+		TurnNum:           turnNum,
+		IsFinal:           false,
+	})
+}
+
+func signedByAll(t *testing.T, s state.State) state.SignedState {
+	signed := state.NewSignedState(s)
+	for _, key := range [][]byte{Alice.PrivateKey, Bob.PrivateKey} {
+		sig, err := s.Sign(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = signed.AddSignature(sig)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return signed
+}
+
+// challengerSig returns the actor's signature for a challenge with the state.
+func challengerSig(t *testing.T, actor testactors.Actor, s state.State) state.Signature {
+	signer, err := nc.NewInMemorySigner(actor.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := NitroAdjudicator.SignChallengeMessage(s, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sig
+}
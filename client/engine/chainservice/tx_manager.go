@@ -0,0 +1,292 @@
+package chainservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// RECEIPT_POLL_INTERVAL is how often we check whether a submitted transaction has been mined.
+const RECEIPT_POLL_INTERVAL = time.Second
+
+// RESUBMIT_INTERVAL is how long a transaction may remain unmined before it is resubmitted with higher fees.
+const RESUBMIT_INTERVAL = 2 * time.Minute
+
+// MAX_RESUBMISSIONS is the number of times a transaction is resubmitted before we give up waiting for it.
+const MAX_RESUBMISSIONS = 10
+
+// MAX_SEND_ATTEMPTS is the number of times we try to submit a new transaction which is rejected because of its nonce or fees.
+const MAX_SEND_ATTEMPTS = 3
+
+var (
+	ErrTxReverted = errors.New("transaction reverted")
+	ErrTxNotMined = errors.New("transaction was not mined")
+)
+
+// txBackend is the subset of an Ethereum client used to submit transactions and wait for them to be mined.
+type txBackend interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*ethTypes.Header, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	SendTransaction(ctx context.Context, tx *ethTypes.Transaction) error
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*ethTypes.Receipt, error)
+}
+
+// txManager submits transactions from a single account. It assigns nonces locally, so that several transactions can be
+// pending at once, and resubmits transactions with higher fees when they are not mined promptly.
+type txManager struct {
+	backend txBackend
+	// txSigner supplies the account and signing function. If its GasPrice is set, legacy transactions are sent at that price;
+	// otherwise EIP-1559 fees are estimated for each transaction. Its Nonce is ignored.
+	txSigner *bind.TransactOpts
+	logger   *log.Logger
+
+	pollInterval     time.Duration
+	resubmitInterval time.Duration
+	maxResubmissions int
+
+	mu    sync.Mutex
+	nonce uint64 // the next nonce to use, unless the chain reports a higher one
+}
+
+func newTxManager(backend txBackend, txSigner *bind.TransactOpts, logger *log.Logger) *txManager {
+	return &txManager{
+		backend:          backend,
+		txSigner:         txSigner,
+		logger:           logger,
+		pollInterval:     RECEIPT_POLL_INTERVAL,
+		resubmitInterval: RESUBMIT_INTERVAL,
+		maxResubmissions: MAX_RESUBMISSIONS,
+	}
+}
+
+// send submits a new transaction. The transaction is built, signed and sent by the supplied contract binding call,
+// using transaction options which carry the next nonce and the current fees.
+func (m *txManager) send(ctx context.Context, transact func(*bind.TransactOpts) (*ethTypes.Transaction, error)) (*ethTypes.Transaction, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	feeMultiplier := big.NewInt(1)
+	var err error
+	for attempt := 0; attempt < MAX_SEND_ATTEMPTS; attempt++ {
+		var opts *bind.TransactOpts
+		opts, err = m.transactOpts(ctx, feeMultiplier)
+		if err != nil {
+			return nil, err
+		}
+		var tx *ethTypes.Transaction
+		tx, err = transact(opts)
+		if err == nil {
+			m.nonce = tx.Nonce() + 1
+			return tx, nil
+		}
+
+		switch {
+		case isNonceTooLow(err):
+			// Another transaction from our account was mined: the chain's pending nonce is used for the next attempt
+			m.logger.Printf("nonce %d is too low, retrying: %v", opts.Nonce, err)
+			m.nonce = opts.Nonce.Uint64() + 1
+		case isUnderpriced(err):
+			m.logger.Printf("transaction is underpriced, retrying with higher fees: %v", err)
+			feeMultiplier.Lsh(feeMultiplier, 1)
+		default:
+			return nil, err
+		}
+	}
+	return nil, err
+}
+
+// transactOpts returns transaction options with the next nonce and the current fees multiplied by feeMultiplier.
+func (m *txManager) transactOpts(ctx context.Context, feeMultiplier *big.Int) (*bind.TransactOpts, error) {
+	pending, err := m.backend.PendingNonceAt(ctx, m.txSigner.From)
+	if err != nil {
+		return nil, err
+	}
+	nonce := m.nonce
+	if pending > nonce {
+		nonce = pending
+	}
+
+	opts := &bind.TransactOpts{
+		From:     m.txSigner.From,
+		Nonce:    new(big.Int).SetUint64(nonce),
+		Signer:   m.txSigner.Signer,
+		GasLimit: m.txSigner.GasLimit,
+		Context:  ctx,
+	}
+
+	if m.txSigner.GasPrice != nil {
+		opts.GasPrice = new(big.Int).Mul(m.txSigner.GasPrice, feeMultiplier)
+		return opts, nil
+	}
+	head, err := m.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if head.BaseFee == nil {
+		// The chain does not support EIP-1559
+		price, err := m.backend.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, err
+		}
+		opts.GasPrice = price.Mul(price, feeMultiplier)
+		return opts, nil
+	}
+	tip, err := m.backend.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opts.GasTipCap = tip.Mul(tip, feeMultiplier)
+	opts.GasFeeCap = feeCap(head.BaseFee, opts.GasTipCap)
+	return opts, nil
+}
+
+// waitMined blocks until the transaction, or a resubmission of it, is mined, and returns its receipt.
+// A transaction which is not mined within the resubmit interval is resubmitted with higher fees, in case it is underpriced or was dropped.
+func (m *txManager) waitMined(ctx context.Context, tx *ethTypes.Transaction) (*ethTypes.Receipt, error) {
+	submitted := []*ethTypes.Transaction{tx}
+	lastSubmission := time.Now()
+	for resubmissions := 0; ; {
+		for _, s := range submitted {
+			receipt, err := m.backend.TransactionReceipt(ctx, s.Hash())
+			if errors.Is(err, ethereum.NotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if receipt == nil {
+				// Some backends, such as the simulated backend, report a missing receipt without an error
+				continue
+			}
+			if receipt.Status == ethTypes.ReceiptStatusFailed {
+				return receipt, fmt.Errorf("%w: %s", ErrTxReverted, s.Hash())
+			}
+			return receipt, nil
+		}
+
+		if time.Since(lastSubmission) >= m.resubmitInterval {
+			if resubmissions == m.maxResubmissions {
+				m.releaseNonce(ctx, tx.Nonce())
+				return nil, fmt.Errorf("%w: %s after %d resubmissions", ErrTxNotMined, tx.Hash(), resubmissions)
+			}
+			latest := submitted[len(submitted)-1]
+			bumped, err := m.resubmit(ctx, latest)
+			if err != nil {
+				m.logger.Printf("error resubmitting transaction %s: %v", latest.Hash(), err)
+			} else {
+				m.logger.Printf("resubmitted transaction %s as %s", latest.Hash(), bumped.Hash())
+				submitted = append(submitted, bumped)
+			}
+			resubmissions++
+			lastSubmission = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(m.pollInterval):
+		}
+	}
+}
+
+// releaseNonce is called when we stop waiting for the transaction with the given nonce. If the transaction has been dropped,
+// the chain's pending nonce is at or below it, and every later transaction from our account is stuck behind the gap it leaves.
+// The next nonce is then rewound to the chain's pending nonce, so that the next transaction fills the gap.
+func (m *txManager) releaseNonce(ctx context.Context, nonce uint64) {
+	pending, err := m.backend.PendingNonceAt(ctx, m.txSigner.From)
+	if err != nil {
+		m.logger.Printf("could not read the pending nonce after giving up on nonce %d: %v", nonce, err)
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if pending <= nonce && pending < m.nonce {
+		m.logger.Printf("nonce %d was dropped, rewinding the next nonce from %d to %d", nonce, m.nonce, pending)
+		m.nonce = pending
+	}
+}
+
+// resubmit signs and sends a copy of the transaction with the same nonce and higher fees, which replaces it in the mempool.
+func (m *txManager) resubmit(ctx context.Context, tx *ethTypes.Transaction) (*ethTypes.Transaction, error) {
+	var replacement ethTypes.TxData
+	if tx.Type() == ethTypes.DynamicFeeTxType {
+		head, err := m.backend.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		tip := bump(tx.GasTipCap())
+		replacement = &ethTypes.DynamicFeeTx{
+			Nonce:     tx.Nonce(),
+			GasTipCap: tip,
+			GasFeeCap: maxBig(bump(tx.GasFeeCap()), feeCap(head.BaseFee, tip)),
+			Gas:       tx.Gas(),
+			To:        tx.To(),
+			Value:     tx.Value(),
+			Data:      tx.Data(),
+		}
+	} else {
+		price, err := m.backend.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, err
+		}
+		replacement = &ethTypes.LegacyTx{
+			Nonce:    tx.Nonce(),
+			GasPrice: maxBig(bump(tx.GasPrice()), price),
+			Gas:      tx.Gas(),
+			To:       tx.To(),
+			Value:    tx.Value(),
+			Data:     tx.Data(),
+		}
+	}
+
+	signed, err := m.txSigner.Signer(m.txSigner.From, ethTypes.NewTx(replacement))
+	if err != nil {
+		return nil, err
+	}
+	err = m.backend.SendTransaction(ctx, signed)
+	if err != nil {
+		return nil, err
+	}
+	return signed, nil
+}
+
+// feeCap returns a fee cap which keeps a transaction with the given tip minable while the base fee doubles.
+func feeCap(baseFee *big.Int, tip *big.Int) *big.Int {
+	return new(big.Int).Add(tip, new(big.Int).Mul(baseFee, big.NewInt(2)))
+}
+
+// bump increases a fee by 12.5%, which exceeds the 10% increase nodes require of a replacement transaction.
+func bump(fee *big.Int) *big.Int {
+	increase := new(big.Int).Div(fee, big.NewInt(8))
+	increase.Add(increase, big.NewInt(1))
+	return increase.Add(increase, fee)
+}
+
+func maxBig(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
+
+// isNonceTooLow returns true if the error was returned by a node because the transaction's nonce has already been used.
+func isNonceTooLow(err error) bool {
+	return strings.Contains(err.Error(), "nonce too low")
+}
+
+// isUnderpriced returns true if the error was returned by a node because the transaction's fees are too low.
+func isUnderpriced(err error) bool {
+	return strings.Contains(err.Error(), "underpriced")
+}
@@ -0,0 +1,187 @@
+package chainservice
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// fakeTxBackend is a txBackend which mines a transaction once its tip reaches minTip.
+type fakeTxBackend struct {
+	mu       sync.Mutex
+	baseFee  *big.Int
+	minTip   *big.Int
+	mined    uint64 // the number of mined transactions, which is the next nonce according to the chain
+	stale    bool   // whether the backend reports a pending nonce of zero, as a lagging node might
+	sent     []*ethTypes.Transaction
+	receipts map[common.Hash]*ethTypes.Receipt
+}
+
+func (b *fakeTxBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stale {
+		return 0, nil
+	}
+	return b.mined, nil
+}
+
+func (b *fakeTxBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*ethTypes.Header, error) {
+	return &ethTypes.Header{BaseFee: b.baseFee}, nil
+}
+
+func (b *fakeTxBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+func (b *fakeTxBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+func (b *fakeTxBackend) SendTransaction(ctx context.Context, tx *ethTypes.Transaction) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if tx.Nonce() < b.mined {
+		return errors.New("nonce too low")
+	}
+	b.sent = append(b.sent, tx)
+	if tx.Nonce() == b.mined && tx.GasTipCap().Cmp(b.minTip) >= 0 {
+		b.receipts[tx.Hash()] = &ethTypes.Receipt{Status: ethTypes.ReceiptStatusSuccessful, TxHash: tx.Hash()}
+		b.mined++
+	}
+	return nil
+}
+
+func (b *fakeTxBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*ethTypes.Receipt, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	receipt, ok := b.receipts[txHash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return receipt, nil
+}
+
+func TestTxManager(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	txSigner, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newManager := func(backend *fakeTxBackend) *txManager {
+		m := newTxManager(backend, txSigner, log.New(NoopLogger{}, "", 0))
+		m.pollInterval = time.Millisecond
+		m.resubmitInterval = 5 * time.Millisecond
+		m.maxResubmissions = 20
+		return m
+	}
+	// transfer builds, signs and sends a transaction in the same way as a contract binding
+	transfer := func(backend *fakeTxBackend) func(*bind.TransactOpts) (*ethTypes.Transaction, error) {
+		return func(opts *bind.TransactOpts) (*ethTypes.Transaction, error) {
+			tx, err := opts.Signer(opts.From, ethTypes.NewTx(&ethTypes.DynamicFeeTx{
+				Nonce:     opts.Nonce.Uint64(),
+				GasTipCap: opts.GasTipCap,
+				GasFeeCap: opts.GasFeeCap,
+				Gas:       21000,
+				To:        &common.Address{},
+			}))
+			if err != nil {
+				return nil, err
+			}
+			return tx, backend.SendTransaction(opts.Context, tx)
+		}
+	}
+
+	t.Run("assigns consecutive nonces to pending transactions", func(t *testing.T) {
+		backend := &fakeTxBackend{baseFee: big.NewInt(10), minTip: big.NewInt(100), receipts: make(map[common.Hash]*ethTypes.Receipt)}
+		m := newManager(backend)
+		for i := uint64(0); i < 3; i++ {
+			tx, err := m.send(context.Background(), transfer(backend))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tx.Nonce() != i {
+				t.Fatalf("expected nonce %d, got %d", i, tx.Nonce())
+			}
+			if want := feeCap(backend.baseFee, tx.GasTipCap()); tx.GasFeeCap().Cmp(want) != 0 {
+				t.Fatalf("expected fee cap %d, got %d", want, tx.GasFeeCap())
+			}
+		}
+	})
+
+	t.Run("resubmits a stuck transaction with higher fees until it is mined", func(t *testing.T) {
+		backend := &fakeTxBackend{baseFee: big.NewInt(10), minTip: big.NewInt(5), receipts: make(map[common.Hash]*ethTypes.Receipt)}
+		m := newManager(backend)
+		tx, err := m.send(context.Background(), transfer(backend))
+		if err != nil {
+			t.Fatal(err)
+		}
+		receipt, err := m.waitMined(context.Background(), tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		mined := backend.sent[len(backend.sent)-1]
+		if receipt.TxHash != mined.Hash() || mined.Nonce() != tx.Nonce() {
+			t.Fatalf("expected the receipt of the resubmitted transaction")
+		}
+		if mined.GasTipCap().Cmp(backend.minTip) < 0 || mined.GasFeeCap().Cmp(tx.GasFeeCap()) <= 0 {
+			t.Fatalf("expected the resubmitted transaction to have higher fees, got tip %d and fee cap %d", mined.GasTipCap(), mined.GasFeeCap())
+		}
+	})
+
+	t.Run("retries with a new nonce when the nonce has been used", func(t *testing.T) {
+		backend := &fakeTxBackend{baseFee: big.NewInt(10), minTip: big.NewInt(1), receipts: make(map[common.Hash]*ethTypes.Receipt)}
+		m := newManager(backend)
+		first, err := m.send(context.Background(), transfer(backend))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The manager loses track of the first transaction, and the node reports a stale nonce
+		m.nonce = first.Nonce()
+		backend.stale = true
+		second, err := m.send(context.Background(), transfer(backend))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if second.Nonce() != first.Nonce()+1 {
+			t.Fatalf("expected nonce %d, got %d", first.Nonce()+1, second.Nonce())
+		}
+	})
+
+	t.Run("reuses the nonce of a transaction which was never mined", func(t *testing.T) {
+		backend := &fakeTxBackend{baseFee: big.NewInt(10), minTip: big.NewInt(1_000_000), receipts: make(map[common.Hash]*ethTypes.Receipt)}
+		m := newManager(backend)
+		m.maxResubmissions = 2
+		dropped, err := m.send(context.Background(), transfer(backend))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := m.send(context.Background(), transfer(backend)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := m.waitMined(context.Background(), dropped); !errors.Is(err, ErrTxNotMined) {
+			t.Fatalf("expected the transaction not to be mined, got %v", err)
+		}
+
+		// The next transaction fills the gap left by the dropped one, rather than queueing behind it
+		next, err := m.send(context.Background(), transfer(backend))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next.Nonce() != dropped.Nonce() {
+			t.Fatalf("expected nonce %d, got %d", dropped.Nonce(), next.Nonce())
+		}
+	})
+}
@@ -0,0 +1,142 @@
+package engine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directdefund"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/dispute"
+	"github.com/statechannels/go-nitro/protocols/virtualdefund"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+)
+
+// finalizationCheckInterval is how often the engine reads the time on chain while disputes are waiting for their challenges to finalize.
+const finalizationCheckInterval = time.Second
+
+// startDispute hands each channel holding funds of the failed objective to a dispute objective, which challenges the channel on chain
+// with its latest supported state and pays out its outcome once the challenge finalizes.
+//
+// Channels which are already disputed are left to their dispute, so starting a dispute more than once has no further effect.
+// A channel which cannot be disputed is logged rather than returned, since the objective has already failed.
+func (e *Engine) startDispute(objective protocols.Objective) (EngineEvent, error) {
+	res := EngineEvent{}
+	candidates, err := disputeCandidates(objective)
+	if err != nil {
+		e.logger.Printf("Could not dispute the channels of objective %s: %v", objective.Id(), err)
+		return res, nil
+	}
+	for _, candidate := range candidates {
+		channelId := candidate.ChannelId()
+		if existing, err := e.store.GetObjectiveById(dispute.ObjectiveIdFor(channelId)); err == nil && existing.GetStatus() != protocols.Rejected {
+			e.logger.Printf("Channel %s of objective %s is already disputed by %s", channelId, objective.Id(), existing.Id())
+			continue
+		}
+		d, err := dispute.NewObjective(candidate)
+		if err != nil {
+			e.logger.Printf("Could not dispute channel %s of objective %s: %v", channelId, objective.Id(), err)
+			continue
+		}
+		err = e.store.SetObjective(&d)
+		if err != nil {
+			// The channel is owned by another objective, which must finish with it first
+			e.logger.Printf("Could not dispute channel %s of objective %s: %v", channelId, objective.Id(), err)
+			continue
+		}
+		e.logger.Printf("Disputing channel %s of objective %s with the state of turn %d", channelId, objective.Id(), candidate.State().TurnNum)
+		progress, err := e.attemptProgress(&d)
+		if err != nil {
+			return res, err
+		}
+		res.CompletedObjectives = append(res.CompletedObjectives, progress.CompletedObjectives...)
+		res.FailedObjectives = append(res.FailedObjectives, progress.FailedObjectives...)
+	}
+	return res, nil
+}
+
+// disputeCandidates returns the latest supported state of each channel holding funds of the objective: the directly funded
+// channel of a directfund or directdefund objective, or the ledger channels funding the virtual channel of a virtual objective.
+func disputeCandidates(objective protocols.Objective) ([]state.SignedState, error) {
+	ledgers := []*consensus_channel.ConsensusChannel{}
+	switch o := objective.(type) {
+	case *directfund.Objective:
+		ss, err := o.C.LatestSupportedSignedState()
+		return []state.SignedState{ss}, err
+	case *directdefund.Objective:
+		ss, err := o.C.LatestSupportedSignedState()
+		return []state.SignedState{ss}, err
+	case *virtualfund.Objective:
+		for _, c := range []*virtualfund.Connection{o.ToMyLeft, o.ToMyRight} {
+			if c != nil {
+				ledgers = append(ledgers, c.Channel)
+			}
+		}
+	case *virtualdefund.Objective:
+		ledgers = append(ledgers, o.ToMyLeft, o.ToMyRight)
+	default:
+		return nil, fmt.Errorf("objective %s has no channel to dispute", objective.Id())
+	}
+
+	candidates := []state.SignedState{}
+	for _, ledger := range ledgers {
+		if ledger != nil {
+			candidates = append(candidates, ledger.SupportedSignedState())
+		}
+	}
+	return candidates, nil
+}
+
+// awaitFinalization records whether a dispute objective is waiting for its challenge to finalize, and if so makes sure the time on chain is checked.
+func (e *Engine) awaitFinalization(objective protocols.Objective, waitingFor protocols.WaitingFor) {
+	if _, ok := objective.(*dispute.Objective); !ok {
+		return
+	}
+	if waitingFor != dispute.WaitingForFinalization {
+		delete(e.awaitingFinalization, objective.Id())
+		return
+	}
+	e.awaitingFinalization[objective.Id()] = true
+	if e.finalizationCheck == nil {
+		e.finalizationCheck = time.After(finalizationCheckInterval)
+	}
+}
+
+// checkFinalizations tells each dispute objective waiting for its challenge to finalize the time on its chain, and attempts progress.
+// If the time cannot be read it is logged, and read again at the next check.
+func (e *Engine) checkFinalizations() (EngineEvent, error) {
+	e.finalizationCheck = nil
+	res := EngineEvent{}
+	for id := range e.awaitingFinalization {
+		objective, err := e.store.GetObjectiveById(id)
+		if err != nil {
+			return res, fmt.Errorf("could not retrieve dispute objective %s: %w", id, err)
+		}
+		d, ok := objective.(*dispute.Objective)
+		if !ok || d.GetStatus() != protocols.Approved {
+			delete(e.awaitingFinalization, id)
+			continue
+		}
+		chain, err := e.chainForChannel(d.OwnsChannel())
+		var now uint64
+		if err == nil {
+			now, err = chain.GetChainTime()
+		}
+		if err != nil {
+			e.logger.Printf("could not read the time on the chain of channel %s, will retry: %v", d.OwnsChannel(), err)
+			continue
+		}
+		progress, err := e.attemptProgress(d.UpdateWithChainTime(now))
+		if err != nil {
+			return res, err
+		}
+		res.CompletedObjectives = append(res.CompletedObjectives, progress.CompletedObjectives...)
+		res.FailedObjectives = append(res.FailedObjectives, progress.FailedObjectives...)
+	}
+	if len(e.awaitingFinalization) > 0 && e.finalizationCheck == nil {
+		e.finalizationCheck = time.After(finalizationCheckInterval)
+	}
+	return res, nil
+}
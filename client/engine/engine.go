@@ -7,6 +7,7 @@ import (
 	"io"
 	"log"
 	"math/big"
+	"time"
 
 	"github.com/statechannels/go-nitro/channel/consensus_channel"
 	"github.com/statechannels/go-nitro/client/engine/chainservice"
@@ -21,6 +22,16 @@ import (
 	"github.com/statechannels/go-nitro/types"
 )
 
+var (
+	ErrUnsafeToCancel      = errors.New("objective cannot be cancelled without putting funds at risk")
+	ErrNotProposer         = errors.New("only the proposer of a virtual channel may cancel its funding")
+	ErrUnauthorizedSender  = errors.New("message sender is not a participant")
+	ErrQuotaExceeded       = errors.New("objective quota exceeded")
+	ErrUnsupportedChain    = errors.New("no chain service for chain")
+	ErrInvalidObjective    = errors.New("objective could not be constructed from payload")
+	ErrIncompatibleVersion = errors.New("objective version not supported")
+)
+
 // ErrUnhandledChainEvent is an engine error when the the engine cannot process a chain event
 type ErrUnhandledChainEvent struct {
 	event     chainservice.Event
@@ -39,15 +50,16 @@ type Engine struct {
 	// From API
 	ObjectiveRequestsFromAPI chan protocols.ObjectiveRequest
 	PaymentRequestsFromAPI   chan PaymentRequest
+	CancelRequestsFromAPI    chan CancelRequest
 
-	fromChain  <-chan chainservice.Event
+	fromChain  chan chainEvent
 	fromMsg    <-chan protocols.Message
 	fromLedger chan consensus_channel.Proposal
 
 	toApi chan EngineEvent
 
-	msg   messageservice.MessageService
-	chain chainservice.ChainService
+	msg    messageservice.MessageService
+	chains []chainservice.ChainService // one chain service per chain on which we hold channels
 
 	store       store.Store // A Store for persisting and restoring important data
 	policymaker PolicyMaker // A PolicyMaker decides whether to approve or reject objectives
@@ -57,6 +69,36 @@ type Engine struct {
 	metrics *MetricsRecorder
 
 	vm *payments.VoucherManager
+
+	timeouts   ObjectiveTimeouts                            // deadlines for objectives which have stopped making progress
+	progress   map[protocols.ObjectiveId]*objectiveProgress // progress of approved objectives which are subject to deadlines
+	stallCheck <-chan time.Time                             // ticks whenever stalled objectives should be checked for
+
+	limiter *inboundLimiter // bounds the work each peer can cause us to do
+
+	watchWanted map[types.Destination]bool // whether each channel should be registered with its chain service
+	watched     map[types.Destination]bool // whether each channel is registered with its chain service
+	watchRetry  <-chan time.Time           // fires when failed registrations with chain services should be retried
+
+	heldTransactions map[types.Destination][]protocols.ChainTransaction // transactions waiting for their channel to be registered
+
+	awaitingFinalization map[protocols.ObjectiveId]bool // dispute objectives waiting for their challenge to finalize
+	finalizationCheck    <-chan time.Time               // fires when the time on chain should be read for disputes awaiting finalization
+
+	outgoing []protocols.Message // messages to be batched and sent at the end of the current run loop iteration
+
+	versions          protocols.Versions                   // the protocol and objective versions supported by this engine
+	peerVersions      map[types.Address]protocols.Versions // the versions announced by peers
+	announced         map[types.Address]bool               // peers which have been sent our versions
+	objectiveVersions map[protocols.ObjectiveId]uint       // the negotiated protocol version of each open objective
+	openWith          map[types.Address]int                // the number of open objectives with a version shared with each peer
+	closedObjectives  []protocols.Objective                // objectives whose versions are forgotten once the outgoing messages have been sent
+}
+
+// chainEvent is an event from the chain service of the chain with the given id.
+type chainEvent struct {
+	chainId *big.Int
+	event   chainservice.Event
 }
 
 // PaymentRequest represents a request from the API to make a payment using a channel
@@ -65,16 +107,29 @@ type PaymentRequest struct {
 	Amount    *big.Int
 }
 
+// CancelRequest represents a request from the API to cancel an in-progress objective.
+// The outcome of the request is sent on Result: nil if the objective was cancelled, or an error explaining why it could not be.
+type CancelRequest struct {
+	ObjectiveId protocols.ObjectiveId
+	Result      chan error
+}
+
 // EngineEvent is a struct that contains a list of changes caused by handling a message/chain event/api event
 type EngineEvent struct {
 	// These are objectives that are now completed
 	CompletedObjectives []protocols.Objective
 	// These are objectives that have failed
-	FailedObjectives []protocols.ObjectiveId
+	FailedObjectives []FailedObjective
 	// ReceivedVouchers are vouchers we've received from other participants
 	ReceivedVouchers []payments.Voucher
 }
 
+// FailedObjective describes an objective that has failed, along with the reason for the failure.
+type FailedObjective struct {
+	Id     protocols.ObjectiveId
+	Reason protocols.RejectionReason
+}
+
 type CompletedObjectiveEvent struct {
 	Id protocols.ObjectiveId
 }
@@ -82,8 +137,18 @@ type CompletedObjectiveEvent struct {
 // Response is the return type that asynchronous API calls "resolve to". Such a call returns a go channel of type Response.
 type Response struct{}
 
+// Options configures the optional behaviour of an Engine. The zero value gives the default behaviour.
+type Options struct {
+	// Timeouts are the deadlines for objectives that stop making progress. If nil, objectives are never timed out.
+	Timeouts ObjectiveTimeouts
+	// Limits bound the messages and objectives each peer may send us. If nil, DefaultInboundLimits apply.
+	Limits *InboundLimits
+}
+
 // NewEngine is the constructor for an Engine
-func New(msg messageservice.MessageService, chain chainservice.ChainService, store store.Store, logDestination io.Writer, policymaker PolicyMaker, metricsApi MetricsApi) Engine {
+// Transactions and events are routed to and from the chain service whose chain the channel belongs to, so each of the
+// chain services must be for a different chain.
+func New(msg messageservice.MessageService, chains []chainservice.ChainService, store store.Store, logDestination io.Writer, policymaker PolicyMaker, metricsApi MetricsApi, opts Options) Engine {
 	e := Engine{}
 
 	e.store = store
@@ -91,11 +156,15 @@ func New(msg messageservice.MessageService, chain chainservice.ChainService, sto
 	// bind to inbound chans
 	e.ObjectiveRequestsFromAPI = make(chan protocols.ObjectiveRequest)
 	e.PaymentRequestsFromAPI = make(chan PaymentRequest)
+	e.CancelRequestsFromAPI = make(chan CancelRequest)
 
-	e.fromChain = chain.EventFeed()
+	e.fromChain = make(chan chainEvent, 100)
+	for _, chain := range chains {
+		go forwardChainEvents(chain, e.fromChain)
+	}
 	e.fromMsg = msg.Out()
 
-	e.chain = chain
+	e.chains = chains
 	e.msg = msg
 
 	e.toApi = make(chan EngineEvent, 100)
@@ -114,9 +183,46 @@ func New(msg messageservice.MessageService, chain chainservice.ChainService, sto
 		metricsApi = &NoOpMetrics{}
 	}
 	e.metrics = NewMetricsRecorder(*e.store.GetAddress(), metricsApi)
+
+	e.timeouts = opts.Timeouts
+	e.progress = make(map[protocols.ObjectiveId]*objectiveProgress)
+	if interval := e.timeouts.checkInterval(); interval > 0 {
+		e.stallCheck = time.NewTicker(interval).C
+	}
+
+	limits := opts.Limits
+	if limits == nil {
+		limits = &DefaultInboundLimits
+	}
+	e.limiter = newInboundLimiter(*limits)
+
+	e.watchWanted = make(map[types.Destination]bool)
+	e.watched = make(map[types.Destination]bool)
+	e.heldTransactions = make(map[types.Destination][]protocols.ChainTransaction)
+	e.awaitingFinalization = make(map[protocols.ObjectiveId]bool)
+
+	e.versions = SupportedVersions
+	e.peerVersions = make(map[types.Address]protocols.Versions)
+	e.announced = make(map[types.Address]bool)
+	e.objectiveVersions = make(map[protocols.ObjectiveId]uint)
+	e.openWith = make(map[types.Address]int)
+
+	// Ledger channels funded before a restart are watched again, since they may be challenged at any time
+	err := e.watchConsensusChannels()
+	if err != nil {
+		e.logger.Printf("could not watch the ledger channels in the store: %v", err)
+	}
 	return e
 }
 
+// forwardChainEvents forwards the events of the chain service to out, labelled with the chain service's chain id.
+func forwardChainEvents(chain chainservice.ChainService, out chan<- chainEvent) {
+	chainId := chain.GetChainId()
+	for event := range chain.EventFeed() {
+		out <- chainEvent{chainId, event}
+	}
+}
+
 func (e *Engine) ToApi() <-chan EngineEvent {
 	return e.toApi
 }
@@ -137,13 +243,25 @@ func (e *Engine) Run() {
 		case or := <-e.ObjectiveRequestsFromAPI:
 			res, err = e.handleObjectiveRequest(or)
 		case pr := <-e.PaymentRequestsFromAPI:
-			err = e.handlePaymentRequest(pr)
-		case chainEvent := <-e.fromChain:
-			res, err = e.handleChainEvent(chainEvent)
+			err = e.handlePaymentRequests(pr)
+		case cr := <-e.CancelRequestsFromAPI:
+			res, err = e.handleCancelRequest(cr)
+		case ce := <-e.fromChain:
+			res, err = e.handleChainEvent(ce.chainId, ce.event)
 		case message := <-e.fromMsg:
 			res, err = e.handleMessage(message)
 		case proposal := <-e.fromLedger:
 			res, err = e.handleProposal(proposal)
+		case <-e.stallCheck:
+			res, err = e.checkForStalledObjectives()
+		case <-e.watchRetry:
+			err = e.retryWatches()
+		case <-e.finalizationCheck:
+			res, err = e.checkFinalizations()
+		}
+
+		if err == nil {
+			err = e.sendOutgoingMessages()
 		}
 
 		// Handle errors
@@ -159,6 +277,9 @@ func (e *Engine) Run() {
 				e.logger.Printf("Objective %s is complete & returned to API", obj.Id())
 				e.metrics.RecordObjectiveCompleted(obj.Id())
 			}
+			for _, failed := range res.FailedObjectives {
+				e.logger.Printf("Objective %s has failed (%s) & returned to API", failed.Id, failed.Reason)
+			}
 			e.toApi <- res
 		}
 
@@ -190,16 +311,40 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 	e.logMessage(message, Incoming)
 	allCompleted := EngineEvent{}
 
+	if err := e.authenticateMessage(message); err != nil {
+		e.logger.Printf("Ignoring unauthenticated message: %v", err)
+		return allCompleted, nil
+	}
+	if !e.limiter.allowMessage(message.From) {
+		e.logger.Printf("Dropping message from %s, which is sending messages faster than the allowed rate", message.From)
+		e.metrics.RecordLimitTriggered("message_rate", message.From)
+		return allCompleted, nil
+	}
+	if !e.acceptVersions(message) {
+		return allCompleted, nil
+	}
+
 	for _, payload := range message.ObjectivePayloads {
+		if err := e.checkPayloadVersion(payload); e.rejectPayload(payload, message.From, err) {
+			continue
+		}
 
-		objective, err := e.getOrCreateObjective(payload)
+		objective, err := e.getOrCreateObjective(payload, message.From)
+		if errors.Is(err, ErrUnauthorizedSender) {
+			e.logger.Printf("Ignoring payload for objective %s: %v", payload.ObjectiveId, err)
+			continue
+		}
+		if e.rejectPayload(payload, message.From, err) {
+			continue
+		}
 		if err != nil {
 			return EngineEvent{}, err
 		}
 
 		if objective.GetStatus() == protocols.Unapproved {
 			e.logger.Printf("Policymaker is %+v", e.policymaker)
-			if e.policymaker.ShouldApprove(objective) {
+			approve, reason := e.policymaker.ShouldApprove(objective)
+			if approve {
 				objective = objective.Approve()
 
 				ddfo, ok := objective.(*directdefund.Objective)
@@ -208,17 +353,14 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 					e.store.DestroyConsensusChannel(ddfo.C.Id)
 				}
 			} else {
-				objective, sideEffects := objective.Reject()
-				err = e.store.SetObjective(objective)
+				e.logger.Printf("Rejecting objective %s: %s", objective.Id(), reason)
+				// The rejection notices are queued with the other outgoing messages, and the rest of the message is still handled
+				err = e.rejectObjective(objective, reason)
 				if err != nil {
-					return EngineEvent{}, err
+					return allCompleted, err
 				}
-
-				allCompleted.CompletedObjectives = append(allCompleted.CompletedObjectives, objective)
-				err = e.executeSideEffects(sideEffects)
-				// An error would mean we failed to send a message. But the objective is still "completed".
-				// So, we should return allCompleted even if there was an error.
-				return allCompleted, err
+				allCompleted.FailedObjectives = append(allCompleted.FailedObjectives, FailedObjective{Id: objective.Id(), Reason: reason})
+				continue
 			}
 		}
 
@@ -231,15 +373,18 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 			continue
 		}
 
+		objective = e.recordPayloadVersion(objective, payload)
 		updatedObjective, err := objective.Update(payload)
 		if err != nil {
-			return EngineEvent{}, err
+			e.logger.Printf("Ignoring invalid payload for objective %s: %v", objective.Id(), err)
+			continue
 		}
 		progressEvent, err := e.attemptProgress(updatedObjective)
 		if err != nil {
 			return EngineEvent{}, err
 		}
 		allCompleted.CompletedObjectives = append(allCompleted.CompletedObjectives, progressEvent.CompletedObjectives...)
+		allCompleted.FailedObjectives = append(allCompleted.FailedObjectives, progressEvent.FailedObjectives...)
 
 		if err != nil {
 			return EngineEvent{}, err
@@ -248,23 +393,35 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 	}
 
 	for _, entry := range message.LedgerProposals {
+		ledger, err := e.store.GetConsensusChannelById(entry.Proposal.LedgerID)
+		if err != nil || !includes(ledger.Participants(), message.From) {
+			e.logger.Printf("Ignoring proposal for ledger %s: %v", entry.Proposal.LedgerID, ErrUnauthorizedSender)
+			continue
+		}
 		id := getProposalObjectiveId(entry.Proposal)
 		objective, err := e.store.GetObjectiveById(id)
 		if err != nil {
-			return EngineEvent{}, err
+			e.logger.Printf("Ignoring proposal for ledger %s: %v", entry.Proposal.LedgerID, err)
+			continue
 		}
 		if objective.GetStatus() == protocols.Completed {
 			e.logger.Printf("Ignoring payload for complected objective  %s", objective.Id())
 			continue
 		}
+		if objective.GetStatus() == protocols.Rejected {
+			e.logger.Printf("Ignoring payload for rejected objective  %s", objective.Id())
+			continue
+		}
 		vObjective, isVirtual := objective.(protocols.ProposalReceiver)
 		if !isVirtual {
-			return EngineEvent{}, fmt.Errorf("received a proposal for a non-virtual objective %s", objective.Id())
+			e.logger.Printf("Ignoring proposal for ledger %s: objective %s is not a virtual objective", entry.Proposal.LedgerID, objective.Id())
+			continue
 		}
 
 		updatedObjective, err := vObjective.ReceiveProposal(entry)
 		if err != nil {
-			return EngineEvent{}, err
+			e.logger.Printf("Ignoring invalid proposal for objective %s: %v", objective.Id(), err)
+			continue
 		}
 
 		progressEvent, err := e.attemptProgress(updatedObjective)
@@ -273,6 +430,7 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 		}
 
 		allCompleted.CompletedObjectives = append(allCompleted.CompletedObjectives, progressEvent.CompletedObjectives...)
+		allCompleted.FailedObjectives = append(allCompleted.FailedObjectives, progressEvent.FailedObjectives...)
 
 		if err != nil {
 			return EngineEvent{}, err
@@ -281,10 +439,14 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 	}
 
 	for _, entry := range message.RejectedObjectives {
-		objective, err := e.store.GetObjectiveById(entry)
-
+		objective, err := e.store.GetObjectiveById(entry.ObjectiveId)
 		if err != nil {
-			return EngineEvent{}, err
+			e.logger.Printf("Ignoring rejection notice for objective %s: %v", entry.ObjectiveId, err)
+			continue
+		}
+		if !includes(objective.Participants(), message.From) {
+			e.logger.Printf("Ignoring rejection notice for objective %s: %v", objective.Id(), ErrUnauthorizedSender)
+			continue
 		}
 		if objective.GetStatus() == protocols.Rejected {
 			e.logger.Printf("Ignoring payload for rejected objective  %s", objective.Id())
@@ -294,43 +456,101 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 		// we are rejecting due to a counterparty message notifying us of their rejection. We
 		// do not need to send a message back to that counterparty, and furthermore we assume that
 		// counterparty has already notified all other interested parties. We can therefore ignore the side effects
-		objective, _ = objective.Reject()
-		err = e.store.SetObjective(objective)
+		e.logger.Printf("Objective %s was rejected by a counterparty: %s", objective.Id(), entry.Reason)
+		rejected, _ := objective.Reject(entry.Reason)
+		err = e.store.SetObjective(rejected)
 		if err != nil {
 			return EngineEvent{}, err
 		}
+		err = e.closeObjective(rejected)
+		if err != nil {
+			return EngineEvent{}, err
+		}
+		allCompleted.FailedObjectives = append(allCompleted.FailedObjectives, FailedObjective{Id: objective.Id(), Reason: entry.Reason})
 
-		allCompleted.CompletedObjectives = append(allCompleted.CompletedObjectives, objective)
+		// If we had already committed funds, the counterparty can no longer be relied on to release them, so we recover them on chain
+		if abandonable, ok := objective.(protocols.AbandonableObjective); !ok || !abandonable.SafeToAbandon() {
+			e.logger.Printf("Objective %s was rejected by a counterparty after committing funds, which must be disputed", objective.Id())
+			disputes, err := e.startDispute(objective)
+			if err != nil {
+				return EngineEvent{}, err
+			}
+			allCompleted.CompletedObjectives = append(allCompleted.CompletedObjectives, disputes.CompletedObjectives...)
+			allCompleted.FailedObjectives = append(allCompleted.FailedObjectives, disputes.FailedObjectives...)
+		}
 	}
 
 	for _, voucher := range message.Payments {
 
 		// TODO: return the amount we paid?
 		_, err := e.vm.Receive(voucher)
-
-		allCompleted.ReceivedVouchers = append(allCompleted.ReceivedVouchers, voucher)
 		if err != nil {
-			return EngineEvent{}, fmt.Errorf("error accepting payment voucher: %w", err)
+			e.logger.Printf("Ignoring payment voucher for channel %s: %v", voucher.ChannelId, err)
+			continue
 		}
+		allCompleted.ReceivedVouchers = append(allCompleted.ReceivedVouchers, voucher)
 
 	}
 	return allCompleted, nil
 
 }
 
+// rejectionCodeFor returns the code with which a payload is rejected when err prevents its objective from being created or joined,
+// and false if err is not a reason to reject the payload.
+func rejectionCodeFor(err error) (protocols.RejectionCode, bool) {
+	switch {
+	case errors.Is(err, ErrIncompatibleVersion):
+		return protocols.IncompatibleVersion, true
+	case errors.Is(err, ErrQuotaExceeded):
+		return protocols.QuotaExceeded, true
+	case errors.Is(err, ErrUnsupportedChain):
+		return protocols.UnsupportedChain, true
+	case errors.Is(err, ErrInvalidObjective):
+		return protocols.InvalidObjective, true
+	default:
+		return "", false
+	}
+}
+
+// rejectPayload notifies the sender that its payload was rejected, if err is a reason to reject it (see rejectionCodeFor).
+// It returns true if the payload was rejected.
+func (e *Engine) rejectPayload(payload protocols.ObjectivePayload, from types.Address, err error) bool {
+	code, ok := rejectionCodeFor(err)
+	if !ok {
+		return false
+	}
+	reason := protocols.RejectionReason{Code: code, Message: err.Error()}
+	e.logger.Printf("Rejecting payload for objective %s: %s", payload.ObjectiveId, reason)
+	if code == protocols.QuotaExceeded {
+		e.metrics.RecordLimitTriggered("objective_quota", from)
+	}
+	e.outgoing = append(e.outgoing, protocols.CreateRejectionNoticeMessage(payload.ObjectiveId, reason, from)...)
+	return true
+}
+
 // handleChainEvent handles a Chain Event from the blockchain.
 // It:
 //   - reads an objective from the store,
-//   - generates an updated objective, and
-//   - attempts progress.
-func (e *Engine) handleChainEvent(chainEvent chainservice.Event) (EngineEvent, error) {
+//   - generates an updated objective,
+//   - gives up on the objective if the event leaves it unable to make progress (such as a failed transaction it will not retry), and otherwise
+//   - attempts progress, and
+//   - records the event's block on its chain, so that the chain service can replay later events after a restart.
+func (e *Engine) handleChainEvent(chainId *big.Int, chainEvent chainservice.Event) (EngineEvent, error) {
 	defer e.metrics.RecordFunctionDuration()()
-	e.logger.Printf("handling chain event %v", chainEvent)
+	e.logger.Printf("handling chain event %v from chain %s", chainEvent, chainId)
+	res, err := e.applyChainEvent(chainEvent)
+	if err != nil {
+		return res, err
+	}
+	return res, e.recordBlockNumSeen(chainId, chainEvent)
+}
+
+// applyChainEvent updates the objective which owns the event's channel with the event, and attempts progress.
+func (e *Engine) applyChainEvent(chainEvent chainservice.Event) (EngineEvent, error) {
 	objective, ok := e.store.GetObjectiveByChannelId(chainEvent.ChannelID())
 	if !ok {
-		// TODO: Right now the chain service returns chain events for ALL channels even those we aren't involved in
-		// for now we can ignore channels we aren't involved in
-		// in the future the chain service should allow us to register for specific channels
+		// The chain service only delivers events for channels we have registered, but it may deliver events for
+		// other channels when replaying events on startup, or for a channel whose objective has just finished
 		return EngineEvent{}, nil
 	}
 
@@ -338,13 +558,64 @@ func (e *Engine) handleChainEvent(chainEvent chainservice.Event) (EngineEvent, e
 	if !ok {
 		return EngineEvent{}, &ErrUnhandledChainEvent{event: chainEvent, objective: objective, reason: "objective does not handle chain events"}
 	}
+	if retraction, ok := chainEvent.(chainservice.RetractedEvent); ok && retraction.NowHeld == nil {
+		chainEvent = e.readRetractedHoldings(retraction)
+	}
 	updatedEventHandler, err := eventHandler.UpdateWithChainEvent(chainEvent)
 	if err != nil {
 		return EngineEvent{}, err
 	}
+	if failable, ok := updatedEventHandler.(protocols.FailableObjective); ok {
+		if reason, failed := failable.Failure(); failed {
+			return e.failObjective(failable, reason)
+		}
+	}
 	return e.attemptProgress(updatedEventHandler)
 }
 
+// readRetractedHoldings fills in the holdings of a retraction which the chain service could not read them for. If they still cannot
+// be read the retraction is returned without them, and the objective waits for a later event to learn them.
+func (e *Engine) readRetractedHoldings(retraction chainservice.RetractedEvent) chainservice.RetractedEvent {
+	switch retraction.Retracted.(type) {
+	case chainservice.DepositedEvent, chainservice.AllocationUpdatedEvent:
+	default:
+		return retraction
+	}
+	chain, err := e.chainForChannel(retraction.ChannelID())
+	if err == nil {
+		retraction.NowHeld, err = chain.GetHoldings(retraction.ChannelID(), retraction.AssetAddress)
+	}
+	if err != nil {
+		e.logger.Printf("could not read the holdings of channel %s after a reorg: %v", retraction.ChannelID(), err)
+		retraction.NowHeld = nil
+	}
+	return retraction
+}
+
+// recordBlockNumSeen records the block of an adjudicator event on the given chain in the store. Events which report the outcome of
+// our own transactions or retract earlier events are not replayed, so they are not recorded.
+func (e *Engine) recordBlockNumSeen(chainId *big.Int, chainEvent chainservice.Event) error {
+	var blockNum uint64
+	switch ev := chainEvent.(type) {
+	case chainservice.DepositedEvent:
+		blockNum = ev.BlockNum
+	case chainservice.AllocationUpdatedEvent:
+		blockNum = ev.BlockNum
+	case chainservice.ConcludedEvent:
+		blockNum = ev.BlockNum
+	case chainservice.ChallengeRegisteredEvent:
+		blockNum = ev.BlockNum
+	case chainservice.ChallengeClearedEvent:
+		blockNum = ev.BlockNum
+	default:
+		return nil
+	}
+	if blockNum <= e.store.GetLastBlockNumSeen(chainId) {
+		return nil
+	}
+	return e.store.SetLastBlockNumSeen(chainId, blockNum)
+}
+
 // handleObjectiveRequest handles an ObjectiveRequest (triggered by a client API call).
 // It will attempt to spawn a new, approved objective.
 func (e *Engine) handleObjectiveRequest(or protocols.ObjectiveRequest) (EngineEvent, error) {
@@ -360,6 +631,10 @@ func (e *Engine) handleObjectiveRequest(or protocols.ObjectiveRequest) (EngineEv
 		if err != nil {
 			return EngineEvent{}, fmt.Errorf("handleAPIEvent: Could not create objective for %+v: %w", request, err)
 		}
+		versioned, failed, ok := e.negotiateVersion(&vfo)
+		if !ok {
+			return failed, nil
+		}
 		// Only Alice or Bob care about registering the objective and keeping track of vouchers
 		lastParticipant := uint(len(vfo.V.Participants) - 1)
 		if vfo.MyRole == lastParticipant || vfo.MyRole == payments.PAYER_INDEX {
@@ -372,7 +647,7 @@ func (e *Engine) handleObjectiveRequest(or protocols.ObjectiveRequest) (EngineEv
 		if err != nil {
 			return EngineEvent{}, fmt.Errorf("could not register channel with payment/receipt manager: %w", err)
 		}
-		return e.attemptProgress(&vfo)
+		return e.attemptProgress(versioned)
 
 	case virtualdefund.ObjectiveRequest:
 		minAmount := big.NewInt(0)
@@ -384,25 +659,37 @@ func (e *Engine) handleObjectiveRequest(or protocols.ObjectiveRequest) (EngineEv
 		if err != nil {
 			return EngineEvent{}, fmt.Errorf("handleAPIEvent: Could not create objective for %+v: %w", request, err)
 		}
-		return e.attemptProgress(&vdfo)
+		versioned, failed, ok := e.negotiateVersion(&vdfo)
+		if !ok {
+			return failed, nil
+		}
+		return e.attemptProgress(versioned)
 
 	case directfund.ObjectiveRequest:
 		dfo, err := directfund.NewObjective(request, true, myAddress, e.store.GetChannelsByParticipant, e.store.GetConsensusChannel)
 		if err != nil {
 			return EngineEvent{}, fmt.Errorf("handleAPIEvent: Could not create objective for %+v: %w", request, err)
 		}
-		return e.attemptProgress(&dfo)
+		versioned, failed, ok := e.negotiateVersion(&dfo)
+		if !ok {
+			return failed, nil
+		}
+		return e.attemptProgress(versioned)
 
 	case directdefund.ObjectiveRequest:
 		ddfo, err := directdefund.NewObjective(request, true, e.store.GetConsensusChannelById)
 		if err != nil {
 			return EngineEvent{
-				FailedObjectives: []protocols.ObjectiveId{objectiveId},
+				FailedObjectives: []FailedObjective{{Id: objectiveId, Reason: protocols.RejectionReason{Code: protocols.InvalidObjective, Message: err.Error()}}},
 			}, fmt.Errorf("handleAPIEvent: Could not create objective for %+v: %w", request, err)
 		}
+		versioned, failed, ok := e.negotiateVersion(&ddfo)
+		if !ok {
+			return failed, nil
+		}
 		// If ddfo creation was successful, destroy the consensus channel to prevent it being used (a Channel will now take over governance)
 		e.store.DestroyConsensusChannel(request.ChannelId)
-		return e.attemptProgress(&ddfo)
+		return e.attemptProgress(versioned)
 
 	default:
 		return EngineEvent{}, fmt.Errorf("handleAPIEvent: Unknown objective type %T", request)
@@ -410,6 +697,21 @@ func (e *Engine) handleObjectiveRequest(or protocols.ObjectiveRequest) (EngineEv
 
 }
 
+// handlePaymentRequests handles the given PaymentRequest, along with any others that are waiting to be handled,
+// so that the vouchers for a burst of payments are sent together.
+func (e *Engine) handlePaymentRequests(first PaymentRequest) error {
+	err := e.handlePaymentRequest(first)
+	for err == nil {
+		select {
+		case pr := <-e.PaymentRequestsFromAPI:
+			err = e.handlePaymentRequest(pr)
+		default:
+			return nil
+		}
+	}
+	return err
+}
+
 // handlePaymentRequest handles an PaymentRequest (triggered by a client API call).
 // It prepares and dispatches a payment message to the counterparty.
 func (e *Engine) handlePaymentRequest(request PaymentRequest) error {
@@ -420,7 +722,7 @@ func (e *Engine) handlePaymentRequest(request PaymentRequest) error {
 	voucher, err := e.vm.Pay(
 		cId,
 		request.Amount,
-		*e.store.GetChannelSecretKey())
+		e.store.GetChannelSigner())
 	if err != nil {
 		return fmt.Errorf("handleAPIEvent: Error making payment: %w", err)
 	}
@@ -436,18 +738,60 @@ func (e *Engine) handlePaymentRequest(request PaymentRequest) error {
 	return e.executeSideEffects(se)
 }
 
+// handleCancelRequest handles a CancelRequest (triggered by a client API call).
+// If the objective has not yet committed any funds, it is rejected, the other participants are notified and the objective's channel is released from ownership.
+// Otherwise the objective is left untouched and the reason is returned to the caller.
+func (e *Engine) handleCancelRequest(request CancelRequest) (EngineEvent, error) {
+	defer e.metrics.RecordFunctionDuration()()
+	defer close(request.Result)
+
+	objective, err := e.store.GetObjectiveById(request.ObjectiveId)
+	if err != nil {
+		request.Result <- fmt.Errorf("could not cancel objective %s: %w", request.ObjectiveId, err)
+		return EngineEvent{}, nil
+	}
+	if objective.GetStatus() != protocols.Approved {
+		request.Result <- fmt.Errorf("could not cancel objective %s: objective is not in progress", request.ObjectiveId)
+		return EngineEvent{}, nil
+	}
+	// An intermediary or the payee may already have been sent a ledger proposal which it has not yet received,
+	// so only the proposer, which makes the first proposal, can tell that none has been signed
+	if vfo, ok := objective.(*virtualfund.Objective); ok && vfo.MyRole != 0 {
+		request.Result <- fmt.Errorf("could not cancel objective %s: %w", request.ObjectiveId, ErrNotProposer)
+		return EngineEvent{}, nil
+	}
+	abandonable, ok := objective.(protocols.AbandonableObjective)
+	if !ok || !abandonable.SafeToAbandon() {
+		request.Result <- fmt.Errorf("could not cancel objective %s: %w", request.ObjectiveId, ErrUnsafeToCancel)
+		return EngineEvent{}, nil
+	}
+
+	reason := protocols.RejectionReason{Code: protocols.Cancelled, Message: fmt.Sprintf("cancelled by %s", e.store.GetAddress())}
+	e.logger.Printf("Cancelling objective %s", objective.Id())
+
+	err = e.rejectObjective(objective, reason)
+	if err != nil {
+		request.Result <- fmt.Errorf("could not cancel objective %s: %w", request.ObjectiveId, err)
+		return EngineEvent{}, err
+	}
+	request.Result <- nil
+
+	return EngineEvent{FailedObjectives: []FailedObjective{{Id: objective.Id(), Reason: reason}}}, nil
+}
+
 // executeSideEffects executes the SideEffects declared by cranking an Objective or handling a payment request.
+// Messages are not sent immediately: they are sent by sendOutgoingMessages at the end of the run loop iteration.
 func (e *Engine) executeSideEffects(sideEffects protocols.SideEffects) error {
 	defer e.metrics.RecordFunctionDuration()()
 
-	for _, message := range sideEffects.MessagesToSend {
-		e.logMessage(message, Outgoing)
-		e.recordMessageMetrics(message)
-		e.msg.Send(message)
-	}
+	e.outgoing = append(e.outgoing, sideEffects.MessagesToSend...)
 	for _, tx := range sideEffects.TransactionsToSubmit {
 		e.logger.Printf("Sending chain transaction for channel %s", tx.ChannelId())
-		err := e.chain.SendTransaction(tx)
+		chain, err := e.chainForChannel(tx.ChannelId())
+		if err != nil {
+			return err
+		}
+		err = chain.SendTransaction(tx)
 		if err != nil {
 			return err
 		}
@@ -460,21 +804,25 @@ func (e *Engine) executeSideEffects(sideEffects protocols.SideEffects) error {
 
 // attemptProgress takes a "live" objective in memory and performs the following actions:
 //
-//  1. It pulls the secret key from the store
-//  2. It cranks the objective with that key
+//  1. It pulls the signer from the store
+//  2. It cranks the objective with that signer
 //  3. It commits the cranked objective to the store
 //  4. It executes any side effects that were declared during cranking
 //  5. It updates progress metadata in the store
 func (e *Engine) attemptProgress(objective protocols.Objective) (outgoing EngineEvent, err error) {
 	defer e.metrics.RecordFunctionDuration()()
 
-	secretKey := e.store.GetChannelSecretKey()
+	signer := e.store.GetChannelSigner()
 	var crankedObjective protocols.Objective
 	var sideEffects protocols.SideEffects
 	var waitingFor protocols.WaitingFor
 
-	crankedObjective, sideEffects, waitingFor, err = objective.Crank(secretKey)
+	crankedObjective, sideEffects, waitingFor, err = objective.Crank(signer)
 
+	if errors.Is(err, consensus_channel.ErrInsufficientFunds) {
+		// A ledger channel cannot afford the guarantee the objective needs, which will not change by trying again
+		return e.failObjective(objective, protocols.RejectionReason{Code: protocols.InsufficientLedgerCapacity, Message: err.Error()})
+	}
 	if err != nil {
 		return
 	}
@@ -486,22 +834,74 @@ func (e *Engine) attemptProgress(objective protocols.Objective) (outgoing Engine
 	}
 
 	e.logger.Printf("Objective %s is %s", objective.Id(), waitingFor)
+	e.recordProgress(crankedObjective.Id(), waitingFor, sideEffects.MessagesToSend)
+	e.awaitFinalization(crankedObjective, waitingFor)
 
 	// If our protocol is waiting for nothing then we know the objective is complete
 	// TODO: If attemptProgress is called on a completed objective CompletedObjectives would include that objective id
 	// Probably should have a better check that only adds it to CompletedObjectives if it was completed in this crank
 	if waitingFor == "WaitingForNothing" {
 		outgoing.CompletedObjectives = append(outgoing.CompletedObjectives, crankedObjective)
-		e.store.ReleaseChannelFromOwnership(crankedObjective.OwnsChannel())
+		e.stopTracking(crankedObjective.Id())
+		e.limiter.objectiveClosed(crankedObjective.Id())
+		e.forgetVersions(crankedObjective)
+		e.releaseChannel(crankedObjective)
 		err = e.spawnConsensusChannelIfDirectFundObjective(crankedObjective) // Here we assume that every directfund.Objective is for a ledger channel.
 		if err != nil {
 			return
 		}
 	}
-	err = e.executeSideEffects(sideEffects)
+	err = e.watchChannel(crankedObjective, waitingFor != "WaitingForNothing")
+	if err != nil {
+		return
+	}
+	err = e.executeSideEffects(e.holdUntilWatched(sideEffects))
 	return
 }
 
+// closeObjective stops the engine's bookkeeping for an objective which has been rejected: it is no longer checked for stalls
+// or counted against its proposer's quota, its versions are forgotten, and its channel is released from ownership and no longer watched.
+// The payment channel of a virtualfund objective is removed from the voucher manager, since it will never be funded.
+func (e *Engine) closeObjective(objective protocols.Objective) error {
+	e.stopTracking(objective.Id())
+	e.limiter.objectiveClosed(objective.Id())
+	e.forgetVersions(objective)
+	e.releaseChannel(objective)
+	if vfo, ok := objective.(*virtualfund.Objective); ok {
+		e.vm.Remove(vfo.V.Id)
+	}
+	return e.watchChannel(objective, false)
+}
+
+// releaseChannel releases the objective's channel from ownership, unless the channel is owned by another objective.
+func (e *Engine) releaseChannel(objective protocols.Objective) {
+	owner, ok := e.store.GetObjectiveByChannelId(objective.OwnsChannel())
+	if ok && owner.Id() == objective.Id() {
+		e.store.ReleaseChannelFromOwnership(objective.OwnsChannel())
+	}
+}
+
+// chainFor returns the chain service for the chain with the given id.
+func (e *Engine) chainFor(chainId *big.Int) (chainservice.ChainService, error) {
+	for _, chain := range e.chains {
+		if chain.GetChainId().Cmp(chainId) == 0 {
+			return chain, nil
+		}
+	}
+	return nil, fmt.Errorf("%w %s", ErrUnsupportedChain, chainId)
+}
+
+// chainForChannel returns the chain service for the chain of the channel with the given id.
+func (e *Engine) chainForChannel(channelId types.Destination) (chainservice.ChainService, error) {
+	if c, ok := e.store.GetChannelById(channelId); ok {
+		return e.chainFor(c.ChainId)
+	}
+	if cc, err := e.store.GetConsensusChannelById(channelId); err == nil {
+		return e.chainFor(cc.FixedPart().ChainId)
+	}
+	return nil, fmt.Errorf("could not find the chain of channel %s", channelId)
+}
+
 func (e Engine) registerPaymentChannel(vfo virtualfund.Objective) error {
 	postfund := vfo.V.PostFundState()
 	startingBalance := big.NewInt(0)
@@ -534,21 +934,39 @@ func (e Engine) spawnConsensusChannelIfDirectFundObjective(crankedObjective prot
 }
 
 // getOrCreateObjective retrieves the objective from the store.
-// If the objective does not exist, it creates the objective using the supplied payload and stores it in the store
-func (e *Engine) getOrCreateObjective(p protocols.ObjectivePayload) (protocols.Objective, error) {
+// If the objective does not exist, it creates the objective using the supplied payload and stores it in the store.
+// An ErrUnauthorizedSender error is returned if the sender of the payload is not a participant in the objective,
+// an ErrQuotaExceeded error if the sender may not propose any more objectives for now,
+// and an ErrInvalidObjective error if no objective can be constructed from the payload.
+func (e *Engine) getOrCreateObjective(p protocols.ObjectivePayload, sender types.Address) (protocols.Objective, error) {
 	defer e.metrics.RecordFunctionDuration()()
 	id := p.ObjectiveId
 	objective, err := e.store.GetObjectiveById(id)
 
 	if err == nil {
+		if !includes(objective.Participants(), sender) {
+			return nil, ErrUnauthorizedSender
+		}
 		return objective, nil
 	} else if errors.Is(err, store.ErrNoSuchObjective) {
 
+		if err := e.limiter.allowNewObjective(sender, id); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrQuotaExceeded, err)
+		}
 		newObj, err := e.constructObjectiveFromMessage(id, p)
 
-		if err != nil {
+		if errors.Is(err, ErrUnsupportedChain) {
+			e.limiter.objectiveClosed(id)
 			return nil, fmt.Errorf("error constructing objective from message: %w", err)
 		}
+		if err != nil {
+			e.limiter.objectiveClosed(id)
+			return nil, fmt.Errorf("%w: %v", ErrInvalidObjective, err)
+		}
+		if !includes(newObj.Participants(), sender) {
+			e.limiter.objectiveClosed(id)
+			return nil, ErrUnauthorizedSender
+		}
 		e.metrics.RecordObjectiveStarted(newObj.Id())
 		err = e.store.SetObjective(newObj)
 		if err != nil {
@@ -571,7 +989,13 @@ func (e *Engine) constructObjectiveFromMessage(id protocols.ObjectiveId, p proto
 	case directfund.IsDirectFundObjective(id):
 
 		dfo, err := directfund.ConstructFromPayload(false, p, *e.store.GetAddress())
-		return &dfo, err
+		if err != nil {
+			return &dfo, err
+		}
+		if _, err := e.chainFor(dfo.C.ChainId); err != nil {
+			return &directfund.Objective{}, err
+		}
+		return &dfo, nil
 	case virtualfund.IsVirtualFundObjective(id):
 		vfo, err := virtualfund.ConstructObjectiveFromPayload(p, false, *e.store.GetAddress(), e.store.GetConsensusChannel)
 		if err != nil {
@@ -611,6 +1035,31 @@ func (e *Engine) constructObjectiveFromMessage(id protocols.ObjectiveId, p proto
 
 }
 
+// authenticateMessage checks that the message is addressed to us, and was signed by the sender it claims to be from.
+func (e *Engine) authenticateMessage(message protocols.Message) error {
+	if message.To != *e.store.GetAddress() {
+		return fmt.Errorf("message is addressed to %s", message.To)
+	}
+	signer, err := message.RecoverSigner()
+	if err != nil {
+		return fmt.Errorf("could not recover message signer: %w", err)
+	}
+	if signer != message.From {
+		return fmt.Errorf("message from %s was signed by %s", message.From, signer)
+	}
+	return nil
+}
+
+// includes returns true if address is one of the supplied participants.
+func includes(participants []types.Address, address types.Address) bool {
+	for _, p := range participants {
+		if p == address {
+			return true
+		}
+	}
+	return false
+}
+
 // fromMsgErr wraps errors from objective construction functions and
 // returns an error bundled with the objectiveID
 func fromMsgErr(id protocols.ObjectiveId, err error) error {
@@ -641,14 +1090,58 @@ func getProposalObjectiveId(p consensus_channel.Proposal) protocols.ObjectiveId
 	}
 }
 
-// GetConsensusAppAddress returns the address of a deployed ConsensusApp (for ledger channels)
-func (e *Engine) GetConsensusAppAddress() types.Address {
-	return e.chain.GetConsensusAppAddress()
+// sendOutgoingMessages combines the messages queued during the current run loop iteration into one message per recipient,
+// then signs and sends them. The versions of objectives which closed during the iteration are then forgotten.
+func (e *Engine) sendOutgoingMessages() error {
+	defer e.pruneVersions()
+	if len(e.outgoing) == 0 {
+		return nil
+	}
+	defer e.metrics.RecordFunctionDuration()()
+
+	batched := protocols.BatchMessages(e.outgoing)
+	e.metrics.RecordQueueLength("outgoing_messages_before_batching", len(e.outgoing))
+	e.metrics.RecordQueueLength("outgoing_messages_after_batching", len(batched))
+	e.outgoing = nil
+
+	for _, message := range batched {
+		e.versionMessage(&message)
+		err := message.SignWith(e.store.GetChannelSigner())
+		if err != nil {
+			return fmt.Errorf("could not sign message: %w", err)
+		}
+		e.logMessage(message, Outgoing)
+		e.recordMessageMetrics(message)
+		e.msg.Send(message)
+	}
+	return nil
+}
+
+// GetChainIds returns the ids of the chains the engine has a chain service for. The first is the default chain.
+func (e *Engine) GetChainIds() []*big.Int {
+	chainIds := make([]*big.Int, len(e.chains))
+	for i, chain := range e.chains {
+		chainIds[i] = chain.GetChainId()
+	}
+	return chainIds
+}
+
+// GetConsensusAppAddress returns the address of a deployed ConsensusApp (for ledger channels) on the given chain
+func (e *Engine) GetConsensusAppAddress(chainId *big.Int) (types.Address, error) {
+	chain, err := e.chainFor(chainId)
+	if err != nil {
+		return types.Address{}, err
+	}
+	return chain.GetConsensusAppAddress(), nil
 }
 
-// GetVirtualPaymentAppAddress returns the address of a deployed VirtualPaymentApp
-func (e *Engine) GetVirtualPaymentAppAddress() types.Address {
-	return e.chain.GetVirtualPaymentAppAddress()
+// GetVirtualPaymentAppAddress returns the address of a deployed VirtualPaymentApp on the given chain
+func (e *Engine) GetVirtualPaymentAppAddress(chainId *big.Int) (types.Address, error) {
+	chain, err := e.chainFor(chainId)
+	if err != nil {
+		return types.Address{}, err
+	}
+	return chain.GetVirtualPaymentAppAddress(), nil
 }
 
 type messageDirection string
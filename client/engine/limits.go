@@ -0,0 +1,174 @@
+package engine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// InboundLimits bounds the work that a single peer can cause the engine to do.
+// A zero rate or count disables the corresponding limit.
+type InboundLimits struct {
+	// MessagesPerSecond is the sustained rate at which messages from a peer are handled. Messages above the rate are dropped.
+	MessagesPerSecond float64
+	// MessageBurst is the number of messages from a peer which may be handled in quick succession before MessagesPerSecond applies.
+	MessageBurst int
+	// NewObjectivesPerSecond is the sustained rate at which a peer may propose new objectives. Objectives above the rate are rejected.
+	NewObjectivesPerSecond float64
+	// NewObjectiveBurst is the number of objectives a peer may propose in quick succession before NewObjectivesPerSecond applies.
+	NewObjectiveBurst int
+	// MaxOpenObjectives is the number of objectives proposed by a peer which may be open at once: awaiting approval or in progress.
+	// Further objectives proposed by the peer are rejected until one of its open objectives completes or is rejected.
+	MaxOpenObjectives int
+}
+
+// DefaultInboundLimits are the limits used when none are supplied. They are generous enough not to affect well-behaved peers.
+var DefaultInboundLimits = InboundLimits{
+	MessagesPerSecond:      500,
+	MessageBurst:           5_000,
+	NewObjectivesPerSecond: 50,
+	NewObjectiveBurst:      500,
+	MaxOpenObjectives:      1_000,
+}
+
+// tokenBucket allows events at a sustained rate, with bursts of up to capacity events.
+type tokenBucket struct {
+	rate     float64 // tokens added per second
+	capacity float64
+	tokens   float64
+	last     time.Time
+}
+
+func newTokenBucket(rate float64, capacity int, now time.Time) *tokenBucket {
+	return &tokenBucket{rate: rate, capacity: float64(capacity), tokens: float64(capacity), last: now}
+}
+
+// take removes a token from the bucket, returning false if there are none left.
+func (b *tokenBucket) take(now time.Time) bool {
+	b.refill(now)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// full returns true if the bucket has refilled to capacity, in which case it behaves exactly like a new bucket.
+func (b *tokenBucket) full(now time.Time) bool {
+	b.refill(now)
+	return b.tokens >= b.capacity
+}
+
+// refill adds the tokens accrued since the bucket was last used.
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.last = now
+}
+
+// minBucketsToPrune is the number of buckets a peerBuckets must hold before its full buckets are pruned.
+const minBucketsToPrune = 64
+
+// peerBuckets holds a token bucket for each peer which has recently used one.
+// Buckets which have refilled are pruned as the number of buckets grows, since a new bucket would be full too.
+type peerBuckets struct {
+	rate     float64
+	capacity int
+	buckets  map[types.Address]*tokenBucket
+	pruneAt  int // the number of buckets at which full buckets are next pruned
+}
+
+func newPeerBuckets(rate float64, capacity int) *peerBuckets {
+	return &peerBuckets{rate: rate, capacity: capacity, buckets: make(map[types.Address]*tokenBucket), pruneAt: minBucketsToPrune}
+}
+
+// take removes a token from the peer's bucket, returning false if there are none left.
+func (pb *peerBuckets) take(peer types.Address, now time.Time) bool {
+	b, ok := pb.buckets[peer]
+	if !ok {
+		if len(pb.buckets) >= pb.pruneAt {
+			pb.prune(now)
+		}
+		b = newTokenBucket(pb.rate, pb.capacity, now)
+		pb.buckets[peer] = b
+	}
+	return b.take(now)
+}
+
+// prune removes the buckets which have refilled, and doubles the number of buckets to hold before pruning again,
+// so that the cost of pruning is spread over the buckets created in between.
+func (pb *peerBuckets) prune(now time.Time) {
+	for peer, b := range pb.buckets {
+		if b.full(now) {
+			delete(pb.buckets, peer)
+		}
+	}
+	pb.pruneAt = 2 * len(pb.buckets)
+	if pb.pruneAt < minBucketsToPrune {
+		pb.pruneAt = minBucketsToPrune
+	}
+}
+
+// inboundLimiter enforces InboundLimits for each peer.
+type inboundLimiter struct {
+	limits InboundLimits
+
+	messages   *peerBuckets
+	objectives *peerBuckets
+
+	open     map[types.Address]map[protocols.ObjectiveId]struct{} // the open objectives proposed by each peer
+	proposer map[protocols.ObjectiveId]types.Address              // the peer which proposed each open objective
+}
+
+func newInboundLimiter(limits InboundLimits) *inboundLimiter {
+	return &inboundLimiter{
+		limits:     limits,
+		messages:   newPeerBuckets(limits.MessagesPerSecond, limits.MessageBurst),
+		objectives: newPeerBuckets(limits.NewObjectivesPerSecond, limits.NewObjectiveBurst),
+		open:       make(map[types.Address]map[protocols.ObjectiveId]struct{}),
+		proposer:   make(map[protocols.ObjectiveId]types.Address),
+	}
+}
+
+// allowMessage returns true if a message from the peer should be handled.
+func (l *inboundLimiter) allowMessage(peer types.Address) bool {
+	if l.limits.MessagesPerSecond == 0 {
+		return true
+	}
+	return l.messages.take(peer, time.Now())
+}
+
+// allowNewObjective returns an error if the peer may not propose another objective.
+// Otherwise the objective counts towards the peer's quotas until objectiveClosed is called.
+func (l *inboundLimiter) allowNewObjective(peer types.Address, id protocols.ObjectiveId) error {
+	if l.limits.MaxOpenObjectives > 0 && len(l.open[peer]) >= l.limits.MaxOpenObjectives {
+		return fmt.Errorf("%s has %d open objectives, which is the maximum allowed", peer, len(l.open[peer]))
+	}
+	if l.limits.NewObjectivesPerSecond > 0 && !l.objectives.take(peer, time.Now()) {
+		return fmt.Errorf("%s is proposing objectives faster than the allowed rate", peer)
+	}
+
+	if l.open[peer] == nil {
+		l.open[peer] = make(map[protocols.ObjectiveId]struct{})
+	}
+	l.open[peer][id] = struct{}{}
+	l.proposer[id] = peer
+	return nil
+}
+
+// objectiveClosed stops the objective counting towards its proposer's open objectives.
+func (l *inboundLimiter) objectiveClosed(id protocols.ObjectiveId) {
+	peer, ok := l.proposer[id]
+	if !ok {
+		return
+	}
+	delete(l.proposer, id)
+	delete(l.open[peer], id)
+	if len(l.open[peer]) == 0 {
+		delete(l.open, peer)
+	}
+}
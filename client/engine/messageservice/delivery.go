@@ -0,0 +1,181 @@
+package messageservice
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"sync"
+
+	"github.com/statechannels/go-nitro/client/engine/store"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// This file contains the building blocks shared by message services which deliver messages reliably:
+// messages to each peer are numbered and queued in an Outbox until acknowledged, and the receiver uses an Inbox
+// to pass each message on exactly once, in order.
+
+// Envelope wraps a protocols.Message with the information needed for reliable, ordered delivery.
+type Envelope struct {
+	// Session identifies the instance of the sending message service. Sequence numbers restart from 1 in each session.
+	Session uint64
+	// Seq is the position of the message in the sender's outbox for the recipient, starting at 1.
+	Seq     uint64
+	Message protocols.Message
+}
+
+// Ack acknowledges receipt of the envelope with the given sequence number.
+type Ack struct {
+	Seq uint64
+}
+
+// NewSession returns a random session identifier.
+func NewSession() uint64 {
+	b := make([]byte, 8)
+	_, err := rand.Read(b)
+	if err != nil {
+		panic(err)
+	}
+	return binary.BigEndian.Uint64(b)
+}
+
+// Outbox holds the messages waiting to be delivered to a single peer, in the order they were sent.
+// A message stays in the outbox until the peer acknowledges it.
+// An outbox may be persisted to a store, so that unacknowledged messages survive a restart.
+type Outbox struct {
+	Peer types.Address
+	// Wake is signalled when a message is pushed.
+	Wake chan struct{}
+
+	mu      sync.Mutex
+	session uint64
+	nextSeq uint64
+	pending []Envelope
+	store   store.OutboxStore // nil if the outbox is not persisted
+}
+
+// NewOutbox returns an empty outbox for the given peer, whose messages are numbered in the given session.
+func NewOutbox(peer types.Address, session uint64) *Outbox {
+	return &Outbox{Peer: peer, Wake: make(chan struct{}, 1), session: session}
+}
+
+// LoadOutbox returns the outbox for the given peer held in the store, which stays persisted to the store.
+// The messages in it keep the session and sequence numbers they were given before they were persisted,
+// so that the peer can recognise any it has already received. ok is false if the store holds no outbox for the peer.
+func LoadOutbox(peer types.Address, s store.OutboxStore) (o *Outbox, ok bool) {
+	persisted, ok := s.GetOutbox(peer)
+	if !ok {
+		return nil, false
+	}
+	o = NewOutbox(peer, persisted.Session)
+	o.nextSeq = persisted.NextSeq
+	for _, p := range persisted.Pending {
+		o.pending = append(o.pending, Envelope{Session: persisted.Session, Seq: p.Seq, Message: p.Message})
+	}
+	o.store = s
+	if len(o.pending) > 0 {
+		o.Wake <- struct{}{}
+	}
+	return o, true
+}
+
+// Persist saves the outbox to the store, and saves it again whenever a message is pushed or acknowledged.
+func (o *Outbox) Persist(s store.OutboxStore) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.store = s
+	return o.save()
+}
+
+// save writes the outbox to its store, if it has one. The caller must hold the outbox's lock.
+func (o *Outbox) save() error {
+	if o.store == nil {
+		return nil
+	}
+	persisted := store.PersistedOutbox{Session: o.session, NextSeq: o.nextSeq, Pending: make([]store.PendingMessage, len(o.pending))}
+	for i, e := range o.pending {
+		persisted.Pending[i] = store.PendingMessage{Seq: e.Seq, Message: e.Message}
+	}
+	return o.store.SetOutbox(o.Peer, persisted)
+}
+
+// Push assigns the next sequence number to the message and queues it for delivery.
+// The message is queued even if the outbox cannot be saved to its store, in which case an error is returned.
+func (o *Outbox) Push(msg protocols.Message) error {
+	o.mu.Lock()
+	o.nextSeq++
+	o.pending = append(o.pending, Envelope{Session: o.session, Seq: o.nextSeq, Message: msg})
+	err := o.save()
+	o.mu.Unlock()
+
+	select {
+	case o.Wake <- struct{}{}:
+	default: // a wake up is already pending
+	}
+	return err
+}
+
+// Peek returns the oldest unacknowledged message, if any.
+func (o *Outbox) Peek() (Envelope, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.pending) == 0 {
+		return Envelope{}, false
+	}
+	return o.pending[0], true
+}
+
+// Pop removes the oldest message from the outbox, if it has the given sequence number.
+// An error is returned if the outbox cannot be saved to its store.
+func (o *Outbox) Pop(seq uint64) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.pending) > 0 && o.pending[0].Seq == seq {
+		o.pending = o.pending[1:]
+		return o.save()
+	}
+	return nil
+}
+
+// Inbox tracks the last message received from each peer, so that redelivered messages are only passed on once.
+type Inbox struct {
+	sync.Mutex
+	received map[types.Address]Envelope
+}
+
+// NewInbox returns an inbox which has not received any messages.
+func NewInbox() *Inbox {
+	return &Inbox{received: make(map[types.Address]Envelope)}
+}
+
+// Receipt describes what should be done with an incoming envelope.
+type Receipt int
+
+const (
+	Deliver    Receipt = iota // the message is next in sequence: pass it on and acknowledge it
+	Duplicate                 // the message has already been passed on: acknowledge it again
+	OutOfOrder                // a preceding message is missing: do not acknowledge, so the sender redelivers
+)
+
+// Accept records the envelope from the sender if it is next in sequence and returns how it should be handled.
+// The caller must hold the inbox's lock until the message has been passed on, so that messages from a peer stay in order.
+//
+// The first envelope received in a session is delivered whatever its sequence number: senders only transmit
+// the oldest message they have not had acknowledged, so every earlier message in the session has already been
+// received (by us, before we restarted, or by whichever instance of us acknowledged it).
+func (in *Inbox) Accept(sender types.Address, e Envelope) Receipt {
+	last, ok := in.received[sender]
+	if !ok || last.Session != e.Session {
+		// The sender has started a new session (e.g. after restarting), or we have restarted and forgotten its session
+		in.received[sender] = Envelope{Session: e.Session, Seq: e.Seq}
+		return Deliver
+	}
+	switch {
+	case e.Seq <= last.Seq:
+		return Duplicate
+	case e.Seq == last.Seq+1:
+		in.received[sender] = Envelope{Session: e.Session, Seq: e.Seq}
+		return Deliver
+	default:
+		return OutOfOrder
+	}
+}
@@ -0,0 +1,125 @@
+package messageservice
+
+import (
+	"testing"
+
+	"github.com/statechannels/go-nitro/client/engine/store"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestInboxAccept(t *testing.T) {
+	in := NewInbox()
+	sender := types.Address{'a'}
+
+	testCases := []struct {
+		name string
+		e    Envelope
+		want Receipt
+	}{
+		{"first message", Envelope{Session: 1, Seq: 1}, Deliver},
+		{"redelivered message", Envelope{Session: 1, Seq: 1}, Duplicate},
+		{"gap in sequence", Envelope{Session: 1, Seq: 3}, OutOfOrder},
+		{"next message", Envelope{Session: 1, Seq: 2}, Deliver},
+		{"new session", Envelope{Session: 2, Seq: 1}, Deliver},
+		{"gap in new session", Envelope{Session: 2, Seq: 3}, OutOfOrder},
+		// A sender whose outbox was persisted resumes its session after restarting
+		{"new session resumed mid-sequence", Envelope{Session: 3, Seq: 5}, Deliver},
+		{"next message in resumed session", Envelope{Session: 3, Seq: 6}, Deliver},
+	}
+	for _, tc := range testCases {
+		if got := in.Accept(sender, tc.e); got != tc.want {
+			t.Fatalf("%s: expected receipt %d, got %d", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestInboxAcceptAfterRestart(t *testing.T) {
+	sender := types.Address{'a'}
+	in := NewInbox()
+	for seq := uint64(1); seq <= 3; seq++ {
+		in.Accept(sender, Envelope{Session: 1, Seq: seq})
+	}
+
+	// The receiver restarts, forgetting which messages it has received, while the sender continues its session
+	in = NewInbox()
+	if got := in.Accept(sender, Envelope{Session: 1, Seq: 4}); got != Deliver {
+		t.Fatalf("expected the first message after restarting to be delivered, got receipt %d", got)
+	}
+	if got := in.Accept(sender, Envelope{Session: 1, Seq: 5}); got != Deliver {
+		t.Fatalf("expected the next message to be delivered, got receipt %d", got)
+	}
+}
+
+func TestOutbox(t *testing.T) {
+	o := NewOutbox(types.Address{'b'}, 7)
+	if _, ok := o.Peek(); ok {
+		t.Fatal("expected a new outbox to be empty")
+	}
+
+	first := protocols.Message{To: types.Address{'b'}, RejectedObjectives: []protocols.RejectionNotice{{ObjectiveId: "first"}}}
+	second := protocols.Message{To: types.Address{'b'}, RejectedObjectives: []protocols.RejectionNotice{{ObjectiveId: "second"}}}
+	_ = o.Push(first)
+	_ = o.Push(second)
+	<-o.Wake
+
+	e, _ := o.Peek()
+	if e.Seq != 1 || e.Message.RejectedObjectives[0].ObjectiveId != "first" || e.Session != 7 {
+		t.Fatalf("unexpected envelope %+v", e)
+	}
+	// Acknowledging a message which is not at the head of the outbox has no effect
+	_ = o.Pop(2)
+	if e, _ := o.Peek(); e.Seq != 1 {
+		t.Fatalf("expected message 1 to remain at the head of the outbox, got %d", e.Seq)
+	}
+	_ = o.Pop(1)
+	if e, _ := o.Peek(); e.Seq != 2 || e.Message.RejectedObjectives[0].ObjectiveId != "second" {
+		t.Fatalf("unexpected envelope %+v", e)
+	}
+}
+
+func TestOutboxPersistence(t *testing.T) {
+	peer := types.Address{'b'}
+	s := store.NewMemStore(testactors.Alice.PrivateKey)
+	if _, ok := LoadOutbox(peer, s); ok {
+		t.Fatal("expected no outbox to be loaded from an empty store")
+	}
+
+	o := NewOutbox(peer, 7)
+	if err := o.Persist(s); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []protocols.ObjectiveId{"first", "second"} {
+		if err := o.Push(protocols.Message{To: peer, RejectedObjectives: []protocols.RejectionNotice{{ObjectiveId: id}}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := o.Pop(1); err != nil {
+		t.Fatal(err)
+	}
+
+	// The sender restarts, and resumes delivery from the store
+	restored, ok := LoadOutbox(peer, s)
+	if !ok {
+		t.Fatal("expected the outbox to be loaded from the store")
+	}
+	select {
+	case <-restored.Wake:
+	default:
+		t.Fatal("expected a restored outbox with pending messages to be awake")
+	}
+	e, _ := restored.Peek()
+	if e.Session != 7 || e.Seq != 2 || e.Message.RejectedObjectives[0].ObjectiveId != "second" {
+		t.Fatalf("unexpected envelope %+v", e)
+	}
+
+	// Messages pushed after restarting continue the sequence, and are persisted
+	if err := restored.Push(protocols.Message{To: peer}); err != nil {
+		t.Fatal(err)
+	}
+	persisted, _ := s.GetOutbox(peer)
+	if persisted.Session != 7 || persisted.NextSeq != 3 || len(persisted.Pending) != 2 {
+		t.Fatalf("unexpected persisted outbox %+v", persisted)
+	}
+}
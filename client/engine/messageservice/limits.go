@@ -0,0 +1,24 @@
+package messageservice
+
+import (
+	"fmt"
+
+	"github.com/statechannels/go-nitro/protocols"
+)
+
+const (
+	// MaxFrameSize is the size in bytes of the largest encoded envelope a message service reads from a peer.
+	MaxFrameSize = 4 << 20
+	// MaxPayloadSize is the size in bytes of the largest objective payload a message service passes on to the engine.
+	MaxPayloadSize = 256 << 10
+)
+
+// CheckPayloadSizes returns an error if the message contains an objective payload larger than MaxPayloadSize.
+func CheckPayloadSizes(m protocols.Message) error {
+	for _, p := range m.ObjectivePayloads {
+		if len(p.PayloadData) > MaxPayloadSize {
+			return fmt.Errorf("payload for objective %s is %d bytes, which exceeds the maximum of %d", p.ObjectiveId, len(p.PayloadData), MaxPayloadSize)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package messageservice
+
+import (
+	"testing"
+
+	"github.com/statechannels/go-nitro/protocols"
+)
+
+func TestCheckPayloadSizes(t *testing.T) {
+	msg := protocols.Message{ObjectivePayloads: []protocols.ObjectivePayload{{PayloadData: make([]byte, MaxPayloadSize)}}}
+	if err := CheckPayloadSizes(msg); err != nil {
+		t.Fatalf("expected a payload of the maximum size to be accepted, got %v", err)
+	}
+
+	msg.ObjectivePayloads = append(msg.ObjectivePayloads, protocols.ObjectivePayload{PayloadData: make([]byte, MaxPayloadSize+1)})
+	if err := CheckPayloadSizes(msg); err == nil {
+		t.Fatal("expected an oversized payload to be rejected")
+	}
+}
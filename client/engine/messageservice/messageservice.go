@@ -1,7 +1,10 @@
 // Package messageservice is a messaging service responsible for routing messages to peers and relaying messages received from peers.
 package messageservice // import "github.com/statechannels/go-nitro/client/messageservice"
 
-import "github.com/statechannels/go-nitro/protocols"
+import (
+	"github.com/statechannels/go-nitro/client/engine/store"
+	"github.com/statechannels/go-nitro/protocols"
+)
 
 type MessageService interface {
 	// Out returns a chan for receiving messages from the message service
@@ -9,3 +12,10 @@ type MessageService interface {
 	// Send is for sending messages with the message service
 	Send(protocols.Message)
 }
+
+// PersistentMessageService is a MessageService which can persist the messages it has yet to deliver, so that they survive a restart.
+type PersistentMessageService interface {
+	MessageService
+	// PersistOutboxes persists undelivered messages to the store, and resumes delivery of any messages the store holds from an earlier run
+	PersistOutboxes(store.OutboxStore) error
+}
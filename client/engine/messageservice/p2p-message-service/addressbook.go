@@ -0,0 +1,173 @@
+package p2pms
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/record"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// addressBook stores the most recent signed peer record seen for each Ethereum address, for at most MAX_ADDRESS_BOOK_SIZE addresses.
+// If it has a path, the address book is written to that file whenever it changes, and can be loaded again after a restart.
+type addressBook struct {
+	path    string
+	mu      sync.Mutex
+	records map[types.Address][]byte // marshalled signed envelopes containing a peer.PeerRecord
+	seqs    map[types.Address]uint64
+}
+
+// loadAddressBook returns an address book containing the valid records stored at path.
+// A missing or unreadable file results in an empty address book, and invalid records are skipped and logged to logger.
+// An empty path results in an address book which is never persisted.
+func loadAddressBook(path string, logger *log.Logger) (*addressBook, []*peer.PeerRecord, error) {
+	ab := &addressBook{
+		path:    path,
+		records: make(map[types.Address][]byte),
+		seqs:    make(map[types.Address]uint64),
+	}
+	if path == "" {
+		return ab, nil, nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ab, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stored := make(map[types.Address][]byte)
+	err = json.Unmarshal(raw, &stored)
+	if err != nil {
+		logger.Printf("ignoring address book %s, which could not be parsed: %v", path, err)
+		return ab, nil, nil
+	}
+
+	recs := make([]*peer.PeerRecord, 0, len(stored))
+	for _, envelope := range stored {
+		if len(recs) == MAX_ADDRESS_BOOK_SIZE {
+			break
+		}
+		address, rec, err := verifyPeerRecord(envelope)
+		if err != nil {
+			logger.Printf("skipping invalid record in address book %s: %v", path, err)
+			continue
+		}
+		ab.records[address] = envelope
+		ab.seqs[address] = rec.Seq
+		recs = append(recs, rec)
+	}
+	return ab, recs, nil
+}
+
+// add stores the signed peer record for the address, unless a more recent record is already known.
+// Once the address book is full, a record for a new address replaces the oldest record (the one with the lowest sequence number,
+// which libp2p derives from the time the record was made), unless the new record is older still.
+// It returns true if the record was stored.
+func (ab *addressBook) add(address types.Address, rec *peer.PeerRecord, envelope []byte) (bool, error) {
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+
+	seq, ok := ab.seqs[address]
+	if ok && seq >= rec.Seq {
+		return false, nil
+	}
+	if !ok && len(ab.records) >= MAX_ADDRESS_BOOK_SIZE {
+		oldest := ab.oldest()
+		if ab.seqs[oldest] >= rec.Seq {
+			return false, nil
+		}
+		delete(ab.records, oldest)
+		delete(ab.seqs, oldest)
+	}
+	ab.records[address] = envelope
+	ab.seqs[address] = rec.Seq
+
+	if ab.path == "" {
+		return true, nil
+	}
+	raw, err := json.Marshal(ab.records)
+	if err != nil {
+		return true, err
+	}
+	return true, os.WriteFile(ab.path, raw, 0600)
+}
+
+// get returns the peer record for the address, if there is one.
+func (ab *addressBook) get(address types.Address) (*peer.PeerRecord, bool) {
+	ab.mu.Lock()
+	envelope, ok := ab.records[address]
+	ab.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	_, rec, err := verifyPeerRecord(envelope)
+	if err != nil {
+		return nil, false
+	}
+	return rec, true
+}
+
+// oldest returns the address whose record has the lowest sequence number. The caller must hold ab.mu.
+func (ab *addressBook) oldest() types.Address {
+	var oldest types.Address
+	first := true
+	for address, seq := range ab.seqs {
+		if first || seq < ab.seqs[oldest] {
+			oldest, first = address, false
+		}
+	}
+	return oldest
+}
+
+// recent returns the n most recent signed peer records in the address book, or all of them if there are fewer.
+func (ab *addressBook) recent(n int) [][]byte {
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+	addresses := make([]types.Address, 0, len(ab.records))
+	for address := range ab.records {
+		addresses = append(addresses, address)
+	}
+	sort.Slice(addresses, func(i, j int) bool { return ab.seqs[addresses[i]] > ab.seqs[addresses[j]] })
+	if len(addresses) > n {
+		addresses = addresses[:n]
+	}
+	envelopes := make([][]byte, 0, len(addresses))
+	for _, address := range addresses {
+		envelopes = append(envelopes, ab.records[address])
+	}
+	return envelopes
+}
+
+// verifyPeerRecord checks that the envelope contains a peer record signed by the peer it describes,
+// and returns the Ethereum address of that peer.
+func verifyPeerRecord(envelope []byte) (types.Address, *peer.PeerRecord, error) {
+	env, r, err := record.ConsumeEnvelope(envelope, peer.PeerRecordEnvelopeDomain)
+	if err != nil {
+		return types.Address{}, nil, err
+	}
+	rec, ok := r.(*peer.PeerRecord)
+	if !ok {
+		return types.Address{}, nil, fmt.Errorf("envelope does not contain a peer record")
+	}
+	signer, err := peer.IDFromPublicKey(env.PublicKey)
+	if err != nil {
+		return types.Address{}, nil, err
+	}
+	if signer != rec.PeerID {
+		return types.Address{}, nil, fmt.Errorf("record for peer %s was signed by %s", rec.PeerID, signer)
+	}
+	address, err := addressFromPeerId(rec.PeerID)
+	if err != nil {
+		return types.Address{}, nil, err
+	}
+	return address, rec, nil
+}
@@ -0,0 +1,130 @@
+package p2pms
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	p2pcrypto "github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/record"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// signedPeerRecord returns a peer record for a new secp256k1 identity, signed by that identity, along with its Ethereum address.
+func signedPeerRecord(t *testing.T) (types.Address, *peer.PeerRecord, []byte) {
+	key, _, err := p2pcrypto.GenerateSecp256k1Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := peer.IDFromPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := peer.PeerRecordFromAddrInfo(peer.AddrInfo{ID: id, Addrs: []multiaddr.Multiaddr{multiaddr.StringCast("/ip4/127.0.0.1/tcp/3200")}})
+	env, err := record.Seal(rec, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope, err := env.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	address, err := addressFromPeerId(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return address, rec, envelope
+}
+
+func TestLoadAddressBookSkipsInvalidRecords(t *testing.T) {
+	address, _, envelope := signedPeerRecord(t)
+	path := filepath.Join(t.TempDir(), "addressbook.json")
+	raw, err := json.Marshal(map[types.Address][]byte{address: envelope, {'x'}: []byte("not a peer record")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, raw, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ab, recs, err := loadAddressBook(path, log.New(io.Discard, "", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 1 {
+		t.Fatalf("expected the valid record to be loaded, got %d records", len(recs))
+	}
+	if _, ok := ab.get(address); !ok {
+		t.Fatalf("expected the address book to hold a record for %s", address)
+	}
+
+	// A file which cannot be parsed at all results in an empty address book
+	if err := os.WriteFile(path, []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, recs, err = loadAddressBook(path, log.New(io.Discard, "", 0))
+	if err != nil || len(recs) != 0 {
+		t.Fatalf("expected an empty address book, got %d records and error %v", len(recs), err)
+	}
+}
+
+func TestAddressBookIsCapped(t *testing.T) {
+	ab, _, err := loadAddressBook("", log.New(io.Discard, "", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var firstAddress, secondAddress types.Address
+	var firstRec *peer.PeerRecord
+	var firstEnvelope []byte
+	for i := 0; i < MAX_ADDRESS_BOOK_SIZE; i++ {
+		address, rec, envelope := signedPeerRecord(t)
+		if i == 0 {
+			firstAddress, firstRec, firstEnvelope = address, rec, envelope
+		}
+		if i == 1 {
+			secondAddress = address
+		}
+		if added, err := ab.add(address, rec, envelope); !added || err != nil {
+			t.Fatalf("expected record %d to be added, got %t and error %v", i, added, err)
+		}
+	}
+
+	// Known addresses can still be updated
+	newer := *firstRec
+	newer.Seq = peer.TimestampSeq()
+	if added, err := ab.add(firstAddress, &newer, firstEnvelope); !added || err != nil {
+		t.Fatalf("expected a known address to be updated, got %t and error %v", added, err)
+	}
+
+	// A new address replaces the oldest record, which is now the second one added
+	address, rec, envelope := signedPeerRecord(t)
+	if added, err := ab.add(address, rec, envelope); !added || err != nil {
+		t.Fatalf("expected a full address book to make room for a new address, got %t and error %v", added, err)
+	}
+	if len(ab.records) != MAX_ADDRESS_BOOK_SIZE {
+		t.Fatalf("expected the address book to hold %d records, got %d", MAX_ADDRESS_BOOK_SIZE, len(ab.records))
+	}
+	if _, ok := ab.get(secondAddress); ok {
+		t.Fatalf("expected the oldest record, for %s, to be evicted", secondAddress)
+	}
+	if _, ok := ab.get(firstAddress); !ok {
+		t.Fatalf("expected the updated record for %s to be kept", firstAddress)
+	}
+
+	// A record older than every record in a full address book is not stored
+	stale := *rec
+	stale.Seq = 0
+	if added, err := ab.add(types.Address{'x'}, &stale, envelope); added || err != nil {
+		t.Fatalf("expected a stale record to be refused, got %t and error %v", added, err)
+	}
+
+	if got := len(ab.recent(MAX_PEER_RECORDS_PER_EXCHANGE)); got != MAX_PEER_RECORDS_PER_EXCHANGE {
+		t.Fatalf("expected %d recent records, got %d", MAX_PEER_RECORDS_PER_EXCHANGE, got)
+	}
+}
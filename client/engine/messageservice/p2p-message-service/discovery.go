@@ -0,0 +1,212 @@
+package p2pms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/libp2p/go-libp2p/core/record"
+	"github.com/libp2p/go-libp2p/p2p/discovery/mdns"
+	"github.com/statechannels/go-nitro/types"
+)
+
+const (
+	PEER_EXCHANGE_PROTOCOL_ID     protocol.ID = "/go-nitro/peer-exchange/1.0.0"
+	MDNS_SERVICE_NAME                         = "go-nitro"
+	MAX_PEER_EXCHANGE_SIZE                    = 1 << 20
+	MAX_PEER_RECORDS_PER_EXCHANGE             = 100
+	MAX_ADDRESS_BOOK_SIZE                     = 1_000
+	// DISCOVERED_ADDR_TTL is how long discovered addresses are kept in the peerstore.
+	// Addresses of peers in the address book are restored from it if they expire before they are needed.
+	DISCOVERED_ADDR_TTL = time.Hour
+)
+
+// DiscoveryConfig configures how the message service finds the network addresses of other participants,
+// in addition to any peers added with AddPeers.
+//
+// Since a participant's libp2p identity is their Ethereum key, a discovered peer's Ethereum address is derived from its peer ID.
+type DiscoveryConfig struct {
+	// Mdns enables discovery of peers on the local network.
+	Mdns bool
+	// BootstrapPeers are the multiaddrs (including a /p2p/ component) of peers to connect to on startup.
+	// Connected peers exchange the signed peer records they know about, so connecting to a single well-known peer is enough to learn about others.
+	BootstrapPeers []string
+	// AddressBookPath is the file in which known peer records are stored across restarts. If empty, records are not persisted.
+	AddressBookPath string
+}
+
+// startDiscovery loads the address book, starts listening for peer record exchanges and connects to the configured peers.
+func (ms *P2PMessageService) startDiscovery(config DiscoveryConfig) error {
+	ab, recs, err := loadAddressBook(config.AddressBookPath, ms.logger)
+	if err != nil {
+		return err
+	}
+	ms.addressBook = ab
+	for _, rec := range recs {
+		ms.registerPeer(peer.AddrInfo{ID: rec.PeerID, Addrs: rec.Addrs})
+	}
+
+	ms.p2pHost.SetStreamHandler(PEER_EXCHANGE_PROTOCOL_ID, ms.handlePeerExchange)
+	ms.p2pHost.Network().Notify(&network.NotifyBundle{
+		ConnectedF: func(_ network.Network, c network.Conn) {
+			go ms.exchangePeerRecords(c.RemotePeer())
+		},
+	})
+
+	if config.Mdns {
+		ms.mdns = mdns.NewMdnsService(ms.p2pHost, MDNS_SERVICE_NAME, ms)
+		err = ms.mdns.Start()
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, addr := range config.BootstrapPeers {
+		info, err := peer.AddrInfoFromString(addr)
+		if err != nil {
+			return fmt.Errorf("invalid bootstrap peer %s: %w", addr, err)
+		}
+		ms.registerPeer(*info)
+		err = ms.p2pHost.Connect(context.Background(), *info)
+		if err != nil {
+			ms.logger.Printf("could not connect to bootstrap peer %s: %v", addr, err)
+		}
+	}
+	return nil
+}
+
+// HandlePeerFound is called when a peer is discovered on the local network.
+// It implements mdns.Notifee.
+func (ms *P2PMessageService) HandlePeerFound(info peer.AddrInfo) {
+	if info.ID == ms.p2pHost.ID() {
+		return
+	}
+	ms.registerPeer(info)
+	// Connecting triggers an exchange of signed peer records
+	go func() { _ = ms.p2pHost.Connect(context.Background(), info) }()
+}
+
+// registerPeer records the addresses of the peer, so that messages to its Ethereum address can be delivered.
+// Peers whose ID is not derived from a secp256k1 key are ignored.
+func (ms *P2PMessageService) registerPeer(info peer.AddrInfo) {
+	address, err := addressFromPeerId(info.ID)
+	if err != nil || address == ms.me {
+		return
+	}
+	ms.p2pHost.Peerstore().AddAddrs(info.ID, info.Addrs, DISCOVERED_ADDR_TTL)
+	ms.peers.Store(address.String(), info.ID)
+}
+
+// restorePeerAddrs re-registers the addresses in the address book for the peer with the given Ethereum address,
+// if the peerstore no longer has any addresses for it.
+func (ms *P2PMessageService) restorePeerAddrs(address types.Address, id peer.ID) {
+	if len(ms.p2pHost.Peerstore().Addrs(id)) > 0 {
+		return
+	}
+	if rec, ok := ms.addressBook.get(address); ok {
+		ms.registerPeer(peer.AddrInfo{ID: rec.PeerID, Addrs: rec.Addrs})
+	}
+}
+
+// ownPeerRecord returns our current addresses as a signed peer record.
+func (ms *P2PMessageService) ownPeerRecord() ([]byte, error) {
+	rec := peer.PeerRecordFromAddrInfo(peer.AddrInfo{ID: ms.p2pHost.ID(), Addrs: ms.p2pHost.Addrs()})
+	env, err := record.Seal(rec, ms.key)
+	if err != nil {
+		return nil, err
+	}
+	return env.Marshal()
+}
+
+// exchangePeerRecords sends the peer records we know about to the given peer, and stores the valid records it sends in reply.
+func (ms *P2PMessageService) exchangePeerRecords(id peer.ID) {
+	select {
+	case <-ms.quit:
+		return
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), STREAM_TIMEOUT)
+	defer cancel()
+	s, err := ms.p2pHost.NewStream(ctx, id, PEER_EXCHANGE_PROTOCOL_ID)
+	if err != nil {
+		// The peer may not support peer exchange, or may have gone away
+		return
+	}
+	defer s.Close()
+	_ = s.SetDeadline(time.Now().Add(STREAM_TIMEOUT))
+
+	if ms.writePeerRecords(s) != nil {
+		s.Reset()
+		return
+	}
+	_ = s.CloseWrite()
+	ms.readPeerRecords(s)
+}
+
+// handlePeerExchange stores the valid peer records sent by a peer, and replies with the peer records we know about.
+func (ms *P2PMessageService) handlePeerExchange(stream network.Stream) {
+	defer stream.Close()
+	_ = stream.SetDeadline(time.Now().Add(STREAM_TIMEOUT))
+
+	ms.readPeerRecords(stream)
+	_ = ms.writePeerRecords(stream)
+}
+
+// writePeerRecords writes our own signed peer record to the stream, followed by the most recent records in our address book,
+// up to the MAX_PEER_RECORDS_PER_EXCHANGE that the other side will read.
+func (ms *P2PMessageService) writePeerRecords(s network.Stream) error {
+	own, err := ms.ownPeerRecord()
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(append([][]byte{own}, ms.addressBook.recent(MAX_PEER_RECORDS_PER_EXCHANGE-1)...))
+	if err != nil {
+		return err
+	}
+	_, err = s.Write(raw)
+	return err
+}
+
+// readPeerRecords reads peer records from the stream until the other side stops writing, and stores the valid ones.
+// Records which are not signed by the peer they describe are ignored, as are any beyond the first MAX_PEER_RECORDS_PER_EXCHANGE.
+func (ms *P2PMessageService) readPeerRecords(s network.Stream) {
+	raw, err := io.ReadAll(io.LimitReader(s, MAX_PEER_EXCHANGE_SIZE))
+	if err != nil {
+		return
+	}
+	var envelopes [][]byte
+	if json.Unmarshal(raw, &envelopes) != nil {
+		return
+	}
+
+	if len(envelopes) > MAX_PEER_RECORDS_PER_EXCHANGE {
+		envelopes = envelopes[:MAX_PEER_RECORDS_PER_EXCHANGE]
+	}
+	for _, envelope := range envelopes {
+		address, rec, err := verifyPeerRecord(envelope)
+		if err != nil || address == ms.me {
+			continue
+		}
+		added, err := ms.addressBook.add(address, rec, envelope)
+		if err != nil {
+			ms.logger.Printf("could not store peer record for %s: %v", address, err)
+		}
+		if added {
+			ms.registerPeer(peer.AddrInfo{ID: rec.PeerID, Addrs: rec.Addrs})
+		}
+	}
+}
+
+// requestPeerRecords exchanges peer records with every connected peer.
+// It is used when we need to deliver a message to a participant whose address we do not yet know.
+func (ms *P2PMessageService) requestPeerRecords() {
+	for _, id := range ms.p2pHost.Network().Peers() {
+		go ms.exchangePeerRecords(id)
+	}
+}
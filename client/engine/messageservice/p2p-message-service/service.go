@@ -3,14 +3,15 @@ package p2pms
 import (
 	"bufio"
 	"context"
-	"crypto/sha256"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
-	"math/big"
-	"math/rand"
+	"log"
+	"sync"
 	"time"
 
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
 	"github.com/libp2p/go-libp2p"
 	p2pcrypto "github.com/libp2p/go-libp2p/core/crypto"
 	"github.com/libp2p/go-libp2p/core/host"
@@ -18,7 +19,11 @@ import (
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/peerstore"
 	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/libp2p/go-libp2p/p2p/discovery/mdns"
+	"github.com/libp2p/go-libp2p/p2p/security/noise"
 	"github.com/multiformats/go-multiaddr"
+	"github.com/statechannels/go-nitro/client/engine/messageservice"
+	"github.com/statechannels/go-nitro/client/engine/store"
 	"github.com/statechannels/go-nitro/client/engine/store/safesync"
 	"github.com/statechannels/go-nitro/crypto"
 	"github.com/statechannels/go-nitro/protocols"
@@ -26,14 +31,21 @@ import (
 )
 
 const (
-	PROTOCOL_ID          protocol.ID = "/go-nitro/msg/1.0.0"
-	DELIMITER                        = '\n'
-	BUFFER_SIZE                      = 1_000
-	NUM_CONNECT_ATTEMPTS             = 20
-	RETRY_SLEEP_DURATION             = 5 * time.Second
+	DELIMITER           = '\n'
+	MAX_FRAME_SIZE      = messageservice.MaxFrameSize
+	BUFFER_SIZE         = 1_000
+	INITIAL_RETRY_DELAY = 100 * time.Millisecond
+	MAX_RETRY_DELAY     = 5 * time.Second
+	STREAM_TIMEOUT      = 10 * time.Second
 )
 
+// PROTOCOL_ID identifies the messaging protocol spoken by this node, so that libp2p only opens streams between peers which speak the same protocol version.
+var PROTOCOL_ID = protocol.ID(fmt.Sprintf("/go-nitro/msg/%d.0.0", protocols.ProtocolVersion))
+
+const logPrefix = "p2pms: "
+
 // P2PMessageService is a rudimentary message service that uses TCP to send and receive messages.
+// Connections are encrypted and authenticated with the Noise protocol, using the participant's Ethereum key as its libp2p identity.
 type P2PMessageService struct {
 	toEngine chan protocols.Message // for forwarding processed messages to the engine
 
@@ -43,6 +55,16 @@ type P2PMessageService struct {
 	me      types.Address
 	key     p2pcrypto.PrivKey
 	p2pHost host.Host
+	logger  *log.Logger
+
+	session     uint64 // identifies this instance of the message service to peers
+	outboxes    map[types.Address]*messageservice.Outbox
+	outboxesMu  sync.Mutex
+	outboxStore store.OutboxStore // nil if outboxes are not persisted
+	inbox       *messageservice.Inbox
+
+	addressBook *addressBook
+	mdns        mdns.Service
 }
 
 // Id returns the libp2p peer ID of the message service.
@@ -53,7 +75,17 @@ func (ms *P2PMessageService) Id() peer.ID {
 
 // AddPeers adds the peers to the message service.
 // We ignore peers that are ourselves.
-func (ms *P2PMessageService) AddPeers(peers []PeerInfo) {
+// An error is returned if a peer's libp2p ID is not derived from the Ethereum key for its address; no peers are added in that case.
+func (ms *P2PMessageService) AddPeers(peers []PeerInfo) error {
+	for _, p := range peers {
+		address, err := addressFromPeerId(p.Id)
+		if err != nil {
+			return fmt.Errorf("could not add peer %s: %w", p.Address, err)
+		}
+		if address != p.Address {
+			return fmt.Errorf("could not add peer %s: peer id %s belongs to %s", p.Address, p.Id, address)
+		}
+	}
 
 	for _, p := range peers {
 		// Ignore ourselves
@@ -70,23 +102,35 @@ func (ms *P2PMessageService) AddPeers(peers []PeerInfo) {
 
 		ms.peers.Store(p.Address.String(), info.ID)
 	}
+	return nil
 }
 
 // NewMessageService returns a running P2PMessageService listening on the given ip and port.
-func NewMessageService(ip string, port int, pk []byte) *P2PMessageService {
-	// We generate a random key using the hash of the pk as a seed
-	// This should mean that the message key is deterministic
-	// TODO: Ideally we would use the pk directly, but I haven't figured out of this is possible with lib p2p
-	hash := sha256.Sum256(pk)
-	seed := big.NewInt(0).SetBytes(hash[:]).Int64()
-	messageKey, _, err := p2pcrypto.GenerateECDSAKeyPair(rand.New(rand.NewSource(seed)))
+// Peers must be added with AddPeers before messages can be sent to them.
+// Delivery and discovery problems are logged to logDestination; nil discards them.
+//
+// pk is the participant's Ethereum secret key. It is required in plaintext because it is also the service's libp2p identity:
+// a peer ID is derived from the Ethereum key, so any peer can tell which Ethereum address it is talking to from the Noise
+// handshake alone, and peer IDs can be checked against addresses (see AddPeers and discovery) without exchanging signed bindings
+// between a separate identity key and the address. The key is only held in memory by the libp2p host. A participant whose
+// Ethereum key is only available through a crypto.Signer (such as one backed by a keystore) cannot use this message service.
+func NewMessageService(ip string, port int, pk []byte, logDestination io.Writer) *P2PMessageService {
+	return NewMessageServiceWithDiscovery(ip, port, pk, DiscoveryConfig{}, logDestination)
+}
+
+// NewMessageServiceWithDiscovery returns a running P2PMessageService listening on the given ip and port,
+// which discovers the addresses of other participants as configured. See NewMessageService for why pk is required.
+func NewMessageServiceWithDiscovery(ip string, port int, pk []byte, discovery DiscoveryConfig, logDestination io.Writer) *P2PMessageService {
+	// The Ethereum secret key is a secp256k1 key, so we use it directly as our libp2p identity.
+	// Our peer ID is then bound to our Ethereum address, which lets peers authenticate us during the Noise handshake.
+	messageKey, err := p2pcrypto.UnmarshalSecp256k1PrivateKey(pk)
 	if err != nil {
 		panic(err)
 	}
 	options := []libp2p.Option{libp2p.Identity(messageKey),
 		libp2p.ListenAddrStrings(fmt.Sprintf("/ip4/%s/tcp/%d", ip, port)),
 		libp2p.DefaultTransports,
-		libp2p.NoSecurity,
+		libp2p.Security(noise.ID, noise.New),
 		libp2p.DefaultMuxers,
 	}
 	host, err := libp2p.New(options...)
@@ -94,6 +138,9 @@ func NewMessageService(ip string, port int, pk []byte) *P2PMessageService {
 		panic(err)
 	}
 
+	if logDestination == nil {
+		logDestination = io.Discard
+	}
 	safePeers := safesync.Map[peer.ID]{}
 	h := &P2PMessageService{
 		toEngine: make(chan protocols.Message, BUFFER_SIZE),
@@ -101,88 +148,320 @@ func NewMessageService(ip string, port int, pk []byte) *P2PMessageService {
 		p2pHost:  host,
 		quit:     make(chan struct{}),
 		key:      messageKey,
+		logger:   log.New(logDestination, logPrefix, log.Lmicroseconds|log.Lshortfile),
 		me:       crypto.GetAddressFromSecretKeyBytes(pk),
+		session:  messageservice.NewSession(),
+		outboxes: make(map[types.Address]*messageservice.Outbox),
+		inbox:    messageservice.NewInbox(),
+	}
+
+	for _, format := range messageservice.SupportedWireFormats {
+		h.p2pHost.SetStreamHandler(protocolIdFor(format), h.handleStream(format))
 	}
 
-	h.p2pHost.SetStreamHandler(PROTOCOL_ID, func(stream network.Stream) {
+	err = h.startDiscovery(discovery)
+	if err != nil {
+		panic(err)
+	}
+
+	return h
+
+}
 
+// handleStream returns a stream handler for the given wire format.
+// The handler reads an envelope from the stream, passes its message on to the engine if it is next in sequence,
+// and acknowledges it.
+func (ms *P2PMessageService) handleStream(format messageservice.WireFormat) network.StreamHandler {
+	return func(stream network.Stream) {
 		select {
-		case <-h.quit:
+		case <-ms.quit:
 			stream.Close()
 			return
 		default:
+		}
 
-			reader := bufio.NewReader(stream)
-			// Create a buffer stream for non blocking read and write.
-			raw, err := reader.ReadString(DELIMITER)
-
-			// An EOF means the stream has been closed by the other side.
-			if errors.Is(err, io.EOF) {
-				stream.Close()
-				return
-			}
-			h.checkError(err)
-			m, err := protocols.DeserializeMessage(raw)
+		_ = stream.SetDeadline(time.Now().Add(STREAM_TIMEOUT))
+		raw, err := readFrame(bufio.NewReader(stream), format)
 
-			h.checkError(err)
-			h.toEngine <- m
+		// An EOF means the stream has been closed by the other side.
+		if errors.Is(err, io.EOF) {
 			stream.Close()
+			return
+		}
+		if err != nil {
+			// The sender will redeliver the message, since we have not acknowledged it
+			stream.Reset()
+			return
 		}
 
-	})
+		e, err := format.DecodeEnvelope(raw)
+		if err != nil {
+			ms.logger.Printf("dropping malformed message from peer %s: %v", stream.Conn().RemotePeer(), err)
+			stream.Reset()
+			return
+		}
+		m := e.Message
+
+		// The Noise handshake proves that the remote peer controls the key behind its peer ID.
+		// We only accept messages which claim to be from the Ethereum address for that key.
+		sender, err := addressFromPeerId(stream.Conn().RemotePeer())
+		if err != nil || sender != m.From {
+			ms.logger.Printf("dropping message from peer %s claiming to be from %s", stream.Conn().RemotePeer(), m.From)
+			stream.Reset()
+			return
+		}
 
-	return h
+		ms.inbox.Lock()
+		switch ms.inbox.Accept(sender, e) {
+		case messageservice.Deliver:
+			// Oversized messages are acknowledged but dropped, since redelivering them would not help
+			if err := messageservice.CheckPayloadSizes(m); err != nil {
+				ms.logger.Printf("dropping message from %s: %v", m.From, err)
+				break
+			}
+			ms.toEngine <- m
+		case messageservice.OutOfOrder:
+			ms.inbox.Unlock()
+			stream.Reset()
+			return
+		}
+		ms.inbox.Unlock()
 
+		rawAck, err := format.EncodeAck(messageservice.Ack{Seq: e.Seq})
+		if err != nil {
+			ms.logger.Printf("could not acknowledge message %d from %s: %v", e.Seq, m.From, err)
+			stream.Reset()
+			return
+		}
+		// If the acknowledgement is lost the sender redelivers the message, which we then recognise as a duplicate.
+		_ = writeFrame(stream, format, rawAck)
+		stream.Close()
+	}
 }
 
-// Send sends messages to other participants.
-// It blocks until the message is sent.
-// It will retry establishing a stream NUM_CONNECT_ATTEMPTS times before giving up
+// Send queues the message for delivery to its recipient and returns immediately.
+// Messages to each peer are delivered in order, and are redelivered until the peer acknowledges them.
+// If the recipient's address is not yet known, messages are held until it is discovered.
 func (ms *P2PMessageService) Send(msg protocols.Message) {
-	raw, err := msg.Serialize()
-	ms.checkError(err)
+	err := ms.outboxFor(msg.To).Push(msg)
+	if err != nil {
+		ms.logger.Printf("could not persist message to %s: %v", msg.To, err)
+	}
+}
 
-	id, ok := ms.peers.Load(msg.To.String())
+// PersistOutboxes persists the messages waiting to be delivered to the store, so that they survive a restart,
+// and resumes delivery of any messages the store holds from an earlier run.
+// It should be called before any messages are sent.
+func (ms *P2PMessageService) PersistOutboxes(s store.OutboxStore) error {
+	ms.outboxesMu.Lock()
+	defer ms.outboxesMu.Unlock()
+	for _, peer := range s.GetOutboxPeers() {
+		if _, ok := ms.outboxes[peer]; ok {
+			continue
+		}
+		o, _ := messageservice.LoadOutbox(peer, s)
+		ms.outboxes[peer] = o
+		go ms.deliver(o)
+	}
+	for _, o := range ms.outboxes {
+		err := o.Persist(s)
+		if err != nil {
+			return err
+		}
+	}
+	ms.outboxStore = s
+	return nil
+}
+
+// outboxFor returns the outbox for the given peer, creating it and starting its delivery goroutine if necessary.
+func (ms *P2PMessageService) outboxFor(peer types.Address) *messageservice.Outbox {
+	ms.outboxesMu.Lock()
+	defer ms.outboxesMu.Unlock()
+	o, ok := ms.outboxes[peer]
 	if !ok {
-		panic(fmt.Errorf("could not load peer %s", msg.To.String()))
+		o = messageservice.NewOutbox(peer, ms.session)
+		if ms.outboxStore != nil {
+			err := o.Persist(ms.outboxStore)
+			if err != nil {
+				ms.logger.Printf("could not persist outbox for %s: %v", peer, err)
+			}
+		}
+		ms.outboxes[peer] = o
+		go ms.deliver(o)
 	}
+	return o
+}
+
+// deliver transmits the messages in the outbox one at a time, in order, until the message service is closed.
+// If a message cannot be delivered it is retried with exponential backoff, so delivery resumes once the peer is reachable again.
+func (ms *P2PMessageService) deliver(o *messageservice.Outbox) {
+	delay := INITIAL_RETRY_DELAY
+	attempt := 1
+	for {
+		e, ok := o.Peek()
+		if !ok {
+			select {
+			case <-o.Wake:
+				continue
+			case <-ms.quit:
+				return
+			}
+		}
 
-	for i := 0; i < NUM_CONNECT_ATTEMPTS; i++ {
-		s, err := ms.p2pHost.NewStream(context.Background(), id, PROTOCOL_ID)
+		err := ms.transmit(o.Peer, e)
 		if err == nil {
+			err = o.Pop(e.Seq)
+			if err != nil {
+				ms.logger.Printf("could not persist acknowledgement of message %d by %s: %v", e.Seq, o.Peer, err)
+			}
+			delay = INITIAL_RETRY_DELAY
+			attempt = 1
+			continue
+		}
 
-			writer := bufio.NewWriter(s)
+		ms.logger.Printf("attempt %d: could not deliver message %d to %s, retrying in %s: %v", attempt, e.Seq, o.Peer, delay, err)
+		select {
+		case <-time.After(delay):
+		case <-ms.quit:
+			return
+		}
+		attempt++
+		delay *= 2
+		if delay > MAX_RETRY_DELAY {
+			delay = MAX_RETRY_DELAY
+		}
+	}
+}
 
-			// We don't care about the number of bytes written
-			_, err = writer.WriteString(raw + string(DELIMITER))
+// transmit opens a stream to the peer, writes the envelope and waits for it to be acknowledged.
+// The most preferred wire format supported by the peer is negotiated when the stream is opened.
+func (ms *P2PMessageService) transmit(to types.Address, e messageservice.Envelope) error {
+	id, ok := ms.peers.Load(to.String())
+	if !ok {
+		ms.requestPeerRecords()
+		return fmt.Errorf("could not load peer %s", to)
+	}
+	ms.restorePeerAddrs(to, id)
 
-			ms.checkError(err)
+	ctx, cancel := context.WithTimeout(context.Background(), STREAM_TIMEOUT)
+	defer cancel()
+	s, err := ms.p2pHost.NewStream(ctx, id, protocolIds()...)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	_ = s.SetDeadline(time.Now().Add(STREAM_TIMEOUT))
+	format := wireFormatFor(s.Protocol())
 
-			writer.Flush()
-			s.Close()
+	raw, err := format.EncodeEnvelope(e)
+	if err != nil {
+		return err
+	}
+	err = writeFrame(s, format, raw)
+	if err != nil {
+		s.Reset()
+		return err
+	}
 
-			return
-		}
+	reply, err := readFrame(bufio.NewReader(s), format)
+	if err != nil {
+		s.Reset()
+		return fmt.Errorf("message was not acknowledged: %w", err)
+	}
+	a, err := format.DecodeAck(reply)
+	if err != nil {
+		return err
+	}
+	if a.Seq != e.Seq {
+		return fmt.Errorf("expected acknowledgement of message %d, got %d", e.Seq, a.Seq)
+	}
+	return nil
+}
 
-		// TODO: Hook up to a logger
-		fmt.Printf("attempt %d: could not open stream to %s, retrying in %s\n", i, msg.To.String(), RETRY_SLEEP_DURATION.String())
-		time.Sleep(RETRY_SLEEP_DURATION)
+// protocolIds returns the message protocol ids for the supported wire formats, in order of preference.
+func protocolIds() []protocol.ID {
+	ids := make([]protocol.ID, len(messageservice.SupportedWireFormats))
+	for i, format := range messageservice.SupportedWireFormats {
+		ids[i] = protocolIdFor(format)
+	}
+	return ids
+}
 
+// protocolIdFor returns the message protocol id for the wire format.
+// JSON uses the original protocol id, so that peers which predate wire format negotiation can still be reached.
+func protocolIdFor(format messageservice.WireFormat) protocol.ID {
+	if format == messageservice.JSONFormat {
+		return PROTOCOL_ID
 	}
+	return protocol.ID(fmt.Sprintf("%s/%s", PROTOCOL_ID, format))
+}
 
+// wireFormatFor returns the wire format used by the message protocol id.
+func wireFormatFor(id protocol.ID) messageservice.WireFormat {
+	for _, format := range messageservice.SupportedWireFormats {
+		if protocolIdFor(format) == id {
+			return format
+		}
+	}
+	return messageservice.JSONFormat
 }
 
-// checkError panics if the message service is running and there is an error, otherwise it just returns
-func (s *P2PMessageService) checkError(err error) {
-	if err == nil {
-		return
+// writeFrame writes the encoded data to the stream. JSON is delimited by a newline,
+// and other wire formats (which may contain newlines) are prefixed by their length.
+func writeFrame(w io.Writer, format messageservice.WireFormat, data []byte) error {
+	if format == messageservice.JSONFormat {
+		_, err := w.Write(append(data, DELIMITER))
+		return err
 	}
-	select {
-	case <-s.quit: // If we are quitting we can ignore the error
-		return
-	default:
-		panic(err)
+	_, err := w.Write(append(binary.AppendUvarint(nil, uint64(len(data))), data...))
+	return err
+}
+
+// readFrame reads data written by writeFrame, failing if the frame is larger than MAX_FRAME_SIZE.
+func readFrame(r *bufio.Reader, format messageservice.WireFormat) ([]byte, error) {
+	if format == messageservice.JSONFormat {
+		data := []byte{}
+		for {
+			chunk, err := r.ReadSlice(DELIMITER)
+			data = append(data, chunk...)
+			if len(data) > MAX_FRAME_SIZE+1 {
+				return nil, fmt.Errorf("frame exceeds the maximum of %d bytes", MAX_FRAME_SIZE)
+			}
+			if !errors.Is(err, bufio.ErrBufferFull) {
+				return data, err
+			}
+		}
 	}
+	l, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if l > MAX_FRAME_SIZE {
+		return nil, fmt.Errorf("frame of %d bytes exceeds the maximum of %d", l, MAX_FRAME_SIZE)
+	}
+	data := make([]byte, l)
+	_, err = io.ReadFull(r, data)
+	return data, err
+}
+
+// addressFromPeerId returns the Ethereum address for the secp256k1 public key embedded in the peer ID.
+func addressFromPeerId(id peer.ID) (types.Address, error) {
+	pub, err := id.ExtractPublicKey()
+	if err != nil {
+		return types.Address{}, err
+	}
+	if pub.Type() != p2pcrypto.Secp256k1 {
+		return types.Address{}, fmt.Errorf("peer id %s does not use a secp256k1 key", id)
+	}
+	raw, err := pub.Raw()
+	if err != nil {
+		return types.Address{}, err
+	}
+	ecdsaPub, err := ethcrypto.DecompressPubkey(raw)
+	if err != nil {
+		return types.Address{}, err
+	}
+	return ethcrypto.PubkeyToAddress(*ecdsaPub), nil
 }
 
 // Out returns a channel that can be used to receive messages from the message service
@@ -193,5 +472,8 @@ func (s *P2PMessageService) Out() <-chan protocols.Message {
 // Close closes the P2PMessageService
 func (s *P2PMessageService) Close() {
 	close(s.quit)
+	if s.mdns != nil {
+		s.mdns.Close()
+	}
 	s.p2pHost.Close()
 }
@@ -0,0 +1,177 @@
+package p2pms
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/client/engine/store"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/protocols"
+)
+
+var alice = testactors.Alice
+var bob = testactors.Bob
+
+// signedMessage returns a message from alice to bob which can be identified by its (only) rejected objective.
+func signedMessage(t *testing.T, id protocols.ObjectiveId) protocols.Message {
+	msg := protocols.Message{
+		To:                 bob.Address(),
+		RejectedObjectives: []protocols.RejectionNotice{{ObjectiveId: id}},
+	}
+	err := msg.Sign(alice.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func TestRedeliveryAfterPartition(t *testing.T) {
+	msA := NewMessageService("127.0.0.1", 3105, alice.PrivateKey, nil)
+	defer msA.Close()
+	msB := NewMessageService("127.0.0.1", 3106, bob.PrivateKey, nil)
+	peers := []PeerInfo{
+		{Id: msA.Id(), IpAddress: "127.0.0.1", Port: 3105, Address: alice.Address()},
+		{Id: msB.Id(), IpAddress: "127.0.0.1", Port: 3106, Address: bob.Address()},
+	}
+	if err := msA.AddPeers(peers); err != nil {
+		t.Fatal(err)
+	}
+
+	// Bob goes offline before alice sends anything
+	msB.Close()
+
+	const numMessages = 3
+	for i := 0; i < numMessages; i++ {
+		start := time.Now()
+		msA.Send(signedMessage(t, protocols.ObjectiveId(fmt.Sprint(i))))
+		if time.Since(start) > 100*time.Millisecond {
+			t.Fatal("expected Send not to block while the peer is unreachable")
+		}
+	}
+
+	// Let alice's first delivery attempts fail
+	time.Sleep(300 * time.Millisecond)
+
+	// Bob comes back online with the same identity
+	msB = NewMessageService("127.0.0.1", 3106, bob.PrivateKey, nil)
+	defer msB.Close()
+	if err := msB.AddPeers(peers); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < numMessages; i++ {
+		select {
+		case got := <-msB.Out():
+			want := protocols.ObjectiveId(fmt.Sprint(i))
+			if got.RejectedObjectives[0].ObjectiveId != want {
+				t.Fatalf("expected message %s, got %s", want, got.RejectedObjectives[0].ObjectiveId)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timed out waiting for message %d", i)
+		}
+	}
+
+	select {
+	case extra := <-msB.Out():
+		t.Fatalf("expected each message to be delivered once, got an extra message %v", extra.Summarize())
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestRedeliveryAfterBothPeersRestart(t *testing.T) {
+	storeA := store.NewMemStore(alice.PrivateKey)
+	newA := func() *P2PMessageService {
+		msA := NewMessageService("127.0.0.1", 3110, alice.PrivateKey, nil)
+		if err := msA.PersistOutboxes(storeA); err != nil {
+			t.Fatal(err)
+		}
+		return msA
+	}
+	msA := newA()
+	msB := NewMessageService("127.0.0.1", 3111, bob.PrivateKey, nil)
+	peers := []PeerInfo{
+		{Id: msA.Id(), IpAddress: "127.0.0.1", Port: 3110, Address: alice.Address()},
+		{Id: msB.Id(), IpAddress: "127.0.0.1", Port: 3111, Address: bob.Address()},
+	}
+	addPeers := func(ms *P2PMessageService) {
+		if err := ms.AddPeers(peers); err != nil {
+			t.Fatal(err)
+		}
+	}
+	addPeers(msA)
+	expectMessage := func(id protocols.ObjectiveId) {
+		select {
+		case got := <-msB.Out():
+			if got.RejectedObjectives[0].ObjectiveId != id {
+				t.Fatalf("expected message %s, got %s", id, got.RejectedObjectives[0].ObjectiveId)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timed out waiting for message %s", id)
+		}
+	}
+
+	msA.Send(signedMessage(t, "0"))
+	expectMessage("0")
+	// Wait for bob's acknowledgement to reach alice's store, so that the message is not redelivered
+	for outbox, _ := storeA.GetOutbox(bob.Address()); len(outbox.Pending) > 0; outbox, _ = storeA.GetOutbox(bob.Address()) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Bob goes offline, and alice restarts before her next messages are delivered
+	msB.Close()
+	msA.Send(signedMessage(t, "1"))
+	msA.Send(signedMessage(t, "2"))
+	msA.Close()
+	msA = newA()
+	defer msA.Close()
+	addPeers(msA)
+
+	// Bob comes back online having forgotten which messages he received, and gets the rest of alice's session
+	msB = NewMessageService("127.0.0.1", 3111, bob.PrivateKey, nil)
+	defer msB.Close()
+	addPeers(msB)
+	expectMessage("1")
+	expectMessage("2")
+
+	select {
+	case extra := <-msB.Out():
+		t.Fatalf("expected each message to be delivered once, got an extra message %v", extra.Summarize())
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestDiscoveryViaBootstrapPeer(t *testing.T) {
+	irene := testactors.Irene
+	msI := NewMessageService("127.0.0.1", 3107, irene.PrivateKey, nil)
+	defer msI.Close()
+	bootstrap := []string{fmt.Sprintf("/ip4/127.0.0.1/tcp/3107/p2p/%s", msI.Id())}
+
+	// Neither alice nor bob call AddPeers: they only know how to reach irene
+	msB := NewMessageServiceWithDiscovery("127.0.0.1", 3108, bob.PrivateKey, DiscoveryConfig{BootstrapPeers: bootstrap}, nil)
+	defer msB.Close()
+	addressBook := filepath.Join(t.TempDir(), "addressbook.json")
+	msA := NewMessageServiceWithDiscovery("127.0.0.1", 3109, alice.PrivateKey, DiscoveryConfig{BootstrapPeers: bootstrap, AddressBookPath: addressBook}, nil)
+
+	expectMessage := func(id protocols.ObjectiveId) {
+		select {
+		case got := <-msB.Out():
+			if got.RejectedObjectives[0].ObjectiveId != id {
+				t.Fatalf("expected message %s, got %s", id, got.RejectedObjectives[0].ObjectiveId)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timed out waiting for message %s", id)
+		}
+	}
+
+	msA.Send(signedMessage(t, "via-discovery"))
+	expectMessage("via-discovery")
+	msA.Close()
+
+	// After a restart, alice can reach bob using her address book alone
+	msA = NewMessageServiceWithDiscovery("127.0.0.1", 3109, alice.PrivateKey, DiscoveryConfig{AddressBookPath: addressBook}, nil)
+	defer msA.Close()
+	msA.Send(signedMessage(t, "via-address-book"))
+	expectMessage("via-address-book")
+}
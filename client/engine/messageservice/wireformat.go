@@ -0,0 +1,94 @@
+package messageservice
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/statechannels/go-nitro/internal/wire"
+)
+
+// WireFormat identifies an encoding of envelopes and acknowledgements on the wire.
+// Message services negotiate the format with each peer, falling back to JSON for peers which do not support any other.
+type WireFormat string
+
+const (
+	// JSONFormat encodes envelopes and acknowledgements as JSON, with messages serialized as by protocols.Message.Serialize.
+	JSONFormat WireFormat = "json"
+	// BinaryFormat encodes envelopes and acknowledgements compactly, with messages encoded by protocols.Message.MarshalBinary.
+	BinaryFormat WireFormat = "nitro-binary-v2"
+)
+
+// SupportedWireFormats lists the wire formats supported by this node, in order of preference.
+var SupportedWireFormats = []WireFormat{BinaryFormat, JSONFormat}
+
+// EncodeEnvelope encodes the envelope in the wire format.
+func (f WireFormat) EncodeEnvelope(e Envelope) ([]byte, error) {
+	switch f {
+	case JSONFormat:
+		return json.Marshal(e)
+	case BinaryFormat:
+		msg, err := e.Message.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		w := wire.Writer{}
+		w.Uvarint(e.Session)
+		w.Uvarint(e.Seq)
+		w.Bytes(msg)
+		return w.Data(), nil
+	default:
+		return nil, fmt.Errorf("unsupported wire format %s", f)
+	}
+}
+
+// DecodeEnvelope decodes an envelope encoded in the wire format.
+func (f WireFormat) DecodeEnvelope(data []byte) (Envelope, error) {
+	e := Envelope{}
+	switch f {
+	case JSONFormat:
+		err := json.Unmarshal(data, &e)
+		return e, err
+	case BinaryFormat:
+		r := wire.NewReader(data)
+		e.Session = r.Uvarint()
+		e.Seq = r.Uvarint()
+		msg := r.Bytes()
+		if err := r.Done(); err != nil {
+			return e, err
+		}
+		err := e.Message.UnmarshalBinary(msg)
+		return e, err
+	default:
+		return e, fmt.Errorf("unsupported wire format %s", f)
+	}
+}
+
+// EncodeAck encodes the acknowledgement in the wire format.
+func (f WireFormat) EncodeAck(a Ack) ([]byte, error) {
+	switch f {
+	case JSONFormat:
+		return json.Marshal(a)
+	case BinaryFormat:
+		w := wire.Writer{}
+		w.Uvarint(a.Seq)
+		return w.Data(), nil
+	default:
+		return nil, fmt.Errorf("unsupported wire format %s", f)
+	}
+}
+
+// DecodeAck decodes an acknowledgement encoded in the wire format.
+func (f WireFormat) DecodeAck(data []byte) (Ack, error) {
+	a := Ack{}
+	switch f {
+	case JSONFormat:
+		err := json.Unmarshal(data, &a)
+		return a, err
+	case BinaryFormat:
+		r := wire.NewReader(data)
+		a.Seq = r.Uvarint()
+		return a, r.Done()
+	default:
+		return a, fmt.Errorf("unsupported wire format %s", f)
+	}
+}
@@ -0,0 +1,45 @@
+package messageservice
+
+import (
+	"testing"
+
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestWireFormats(t *testing.T) {
+	e := Envelope{
+		Session: 42,
+		Seq:     7,
+		Message: protocols.Message{To: types.Address{'b'}, RejectedObjectives: []protocols.RejectionNotice{{ObjectiveId: "x"}}},
+	}
+	want, _ := e.Message.Serialize()
+
+	for _, format := range SupportedWireFormats {
+		raw, err := format.EncodeEnvelope(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := format.DecodeEnvelope(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotMessage, _ := got.Message.Serialize()
+		if got.Session != e.Session || got.Seq != e.Seq || gotMessage != want {
+			t.Fatalf("%s: incorrect envelope round trip: got %+v, wanted %+v", format, got, e)
+		}
+
+		rawAck, err := format.EncodeAck(Ack{Seq: 7})
+		if err != nil {
+			t.Fatal(err)
+		}
+		a, err := format.DecodeAck(rawAck)
+		if err != nil || a.Seq != 7 {
+			t.Fatalf("%s: incorrect ack round trip: got %+v (err %v)", format, a, err)
+		}
+	}
+
+	if _, err := WireFormat("unknown").EncodeEnvelope(e); err == nil {
+		t.Fatal("expected an unknown wire format to be rejected")
+	}
+}
@@ -0,0 +1,458 @@
+// Package wsms is a message service which exchanges messages with peers over WebSockets, for deployments where raw libp2p TCP connections are not possible.
+package wsms
+
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/statechannels/go-nitro/client/engine/messageservice"
+	"github.com/statechannels/go-nitro/client/engine/store"
+	"github.com/statechannels/go-nitro/client/engine/store/safesync"
+	"github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+const (
+	BUFFER_SIZE         = 1_000
+	INITIAL_RETRY_DELAY = 100 * time.Millisecond
+	MAX_RETRY_DELAY     = 5 * time.Second
+	ACK_TIMEOUT         = 10 * time.Second
+	AUTH_TIMEOUT        = 10 * time.Second
+	NONCE_SIZE          = 32
+)
+
+const logPrefix = "wsms: "
+
+// WsMessageService is a message service which exchanges messages with peers over WebSockets.
+//
+// It is an http.Handler: peers connect to it via whichever HTTP(S) server it is mounted on, and it connects to
+// the URL registered for a peer (e.g. wss://bob.example.com/nitro) in order to send to that peer.
+//
+// Messages to each peer are delivered in order and redelivered until acknowledged, across dropped connections.
+// Since WebSockets do not authenticate their clients, a peer which connects must first sign a challenge
+// (see authenticate), and then only messages from and signed by that peer are accepted over the connection.
+// This stops a captured message being replayed to us by anyone other than its sender.
+type WsMessageService struct {
+	toEngine chan protocols.Message // for forwarding processed messages to the engine
+
+	me       types.Address
+	signer   crypto.Signer // signs the challenges sent by peers we connect to
+	logger   *log.Logger
+	peers    *safesync.Map[string] // peer URLs, keyed by Ethereum address
+	dialer   *websocket.Dialer
+	upgrader websocket.Upgrader
+
+	session     uint64 // identifies this instance of the message service to peers
+	outboxes    map[types.Address]*messageservice.Outbox
+	outboxesMu  sync.Mutex
+	outboxStore store.OutboxStore // nil if outboxes are not persisted
+	inbox       *messageservice.Inbox
+
+	conns   map[*websocket.Conn]struct{} // open connections, which are closed when the message service is closed
+	connsMu sync.Mutex
+
+	quit chan struct{} // quit is used to signal the goroutines to stop
+}
+
+// NewMessageService returns a WsMessageService for the participant whose key is held by the signer.
+// Peer URLs are taken from peers, and can be added later with AddPeers.
+// tlsConfig is used when connecting to wss:// URLs; nil means the default configuration.
+// Delivery problems are logged to logDestination; nil discards them.
+func NewMessageService(signer crypto.Signer, peers map[types.Address]string, tlsConfig *tls.Config, logDestination io.Writer) *WsMessageService {
+	dialer := *websocket.DefaultDialer
+	dialer.TLSClientConfig = tlsConfig
+
+	subprotocols := make([]string, len(messageservice.SupportedWireFormats))
+	for i, format := range messageservice.SupportedWireFormats {
+		subprotocols[i] = string(format)
+	}
+	dialer.Subprotocols = subprotocols
+
+	if logDestination == nil {
+		logDestination = io.Discard
+	}
+	ms := &WsMessageService{
+		toEngine: make(chan protocols.Message, BUFFER_SIZE),
+		me:       signer.Address(),
+		signer:   signer,
+		logger:   log.New(logDestination, logPrefix, log.Lmicroseconds|log.Lshortfile),
+		peers:    &safesync.Map[string]{},
+		dialer:   &dialer,
+		upgrader: websocket.Upgrader{Subprotocols: subprotocols},
+		session:  messageservice.NewSession(),
+		outboxes: make(map[types.Address]*messageservice.Outbox),
+		inbox:    messageservice.NewInbox(),
+		conns:    make(map[*websocket.Conn]struct{}),
+		quit:     make(chan struct{}),
+	}
+	ms.AddPeers(peers)
+	return ms
+}
+
+// AddPeers adds or updates the URLs at which peers can be reached.
+func (ms *WsMessageService) AddPeers(peers map[types.Address]string) {
+	for address, url := range peers {
+		if address == ms.me {
+			continue
+		}
+		ms.peers.Store(address.String(), url)
+	}
+}
+
+// ServeHTTP accepts a WebSocket connection from a peer, and receives messages over it until it is closed.
+// The peer must first prove which address it is connecting as, by signing a challenge.
+func (ms *WsMessageService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := ms.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already replied to the peer with an error
+		return
+	}
+	if !ms.track(conn) {
+		return
+	}
+	defer ms.untrack(conn)
+	conn.SetReadLimit(messageservice.MaxFrameSize)
+	format := wireFormatOf(conn)
+
+	peer, err := ms.challenge(conn)
+	if err != nil {
+		ms.logger.Printf("closing connection from %s, which did not authenticate: %v", r.RemoteAddr, err)
+		return
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+
+	for {
+		_, raw, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		e, err := format.DecodeEnvelope(raw)
+		if err != nil {
+			return
+		}
+		if !ms.receive(conn, format, peer, e) {
+			return
+		}
+	}
+}
+
+// receive passes the message in the envelope on to the engine if it is next in sequence, and acknowledges it.
+// Only messages from, and signed by, the peer which authenticated the connection are accepted.
+// It returns false if the connection should be closed.
+func (ms *WsMessageService) receive(conn *websocket.Conn, format messageservice.WireFormat, peer types.Address, e messageservice.Envelope) bool {
+	m := e.Message
+	signer, err := m.RecoverSigner()
+	if err != nil || signer != m.From || m.From != peer || m.To != ms.me {
+		ms.logger.Printf("dropping message to %s claiming to be from %s, signed by %s, over a connection from %s", m.To, m.From, signer, peer)
+		return false
+	}
+
+	ms.inbox.Lock()
+	switch ms.inbox.Accept(m.From, e) {
+	case messageservice.Deliver:
+		// Oversized messages are acknowledged but dropped, since redelivering them would not help
+		if err := messageservice.CheckPayloadSizes(m); err != nil {
+			ms.logger.Printf("dropping message from %s: %v", m.From, err)
+			break
+		}
+		ms.toEngine <- m
+	case messageservice.OutOfOrder:
+		ms.inbox.Unlock()
+		return false
+	}
+	ms.inbox.Unlock()
+
+	// If the acknowledgement is lost the sender redelivers the message, which we then recognise as a duplicate.
+	rawAck, err := format.EncodeAck(messageservice.Ack{Seq: e.Seq})
+	if err != nil {
+		return false
+	}
+	_ = conn.SetWriteDeadline(time.Now().Add(ACK_TIMEOUT))
+	return conn.WriteMessage(messageTypeFor(format), rawAck) == nil
+}
+
+// Send queues the message for delivery to its recipient and returns immediately.
+// Messages to each peer are delivered in order, and are redelivered until the peer acknowledges them.
+// If no URL is known for the recipient, messages are held until one is added.
+func (ms *WsMessageService) Send(msg protocols.Message) {
+	err := ms.outboxFor(msg.To).Push(msg)
+	if err != nil {
+		ms.logger.Printf("could not persist message to %s: %v", msg.To, err)
+	}
+}
+
+// PersistOutboxes persists the messages waiting to be delivered to the store, so that they survive a restart,
+// and resumes delivery of any messages the store holds from an earlier run.
+// It should be called before any messages are sent.
+func (ms *WsMessageService) PersistOutboxes(s store.OutboxStore) error {
+	ms.outboxesMu.Lock()
+	defer ms.outboxesMu.Unlock()
+	for _, peer := range s.GetOutboxPeers() {
+		if _, ok := ms.outboxes[peer]; ok {
+			continue
+		}
+		o, _ := messageservice.LoadOutbox(peer, s)
+		ms.outboxes[peer] = o
+		go ms.deliver(o)
+	}
+	for _, o := range ms.outboxes {
+		err := o.Persist(s)
+		if err != nil {
+			return err
+		}
+	}
+	ms.outboxStore = s
+	return nil
+}
+
+// outboxFor returns the outbox for the given peer, creating it and starting its delivery goroutine if necessary.
+func (ms *WsMessageService) outboxFor(peer types.Address) *messageservice.Outbox {
+	ms.outboxesMu.Lock()
+	defer ms.outboxesMu.Unlock()
+	o, ok := ms.outboxes[peer]
+	if !ok {
+		o = messageservice.NewOutbox(peer, ms.session)
+		if ms.outboxStore != nil {
+			err := o.Persist(ms.outboxStore)
+			if err != nil {
+				ms.logger.Printf("could not persist outbox for %s: %v", peer, err)
+			}
+		}
+		ms.outboxes[peer] = o
+		go ms.deliver(o)
+	}
+	return o
+}
+
+// deliver transmits the messages in the outbox one at a time, in order, over a connection to the peer which is
+// reused until it fails. Failed deliveries are retried over a new connection with exponential backoff.
+func (ms *WsMessageService) deliver(o *messageservice.Outbox) {
+	var conn *websocket.Conn
+	defer func() {
+		if conn != nil {
+			ms.untrack(conn)
+		}
+	}()
+
+	delay := INITIAL_RETRY_DELAY
+	attempt := 1
+	for {
+		e, ok := o.Peek()
+		if !ok {
+			select {
+			case <-o.Wake:
+				continue
+			case <-ms.quit:
+				return
+			}
+		}
+
+		var err error
+		if conn == nil {
+			conn, err = ms.dial(o.Peer)
+		}
+		if err == nil {
+			err = transmit(conn, e)
+		}
+		if err == nil {
+			err = o.Pop(e.Seq)
+			if err != nil {
+				ms.logger.Printf("could not persist acknowledgement of message %d by %s: %v", e.Seq, o.Peer, err)
+			}
+			delay = INITIAL_RETRY_DELAY
+			attempt = 1
+			continue
+		}
+
+		if conn != nil {
+			ms.untrack(conn)
+			conn = nil
+		}
+		ms.logger.Printf("attempt %d: could not deliver message %d to %s, retrying in %s: %v", attempt, e.Seq, o.Peer, delay, err)
+		select {
+		case <-time.After(delay):
+		case <-ms.quit:
+			return
+		}
+		attempt++
+		delay *= 2
+		if delay > MAX_RETRY_DELAY {
+			delay = MAX_RETRY_DELAY
+		}
+	}
+}
+
+// dial opens a WebSocket connection to the peer's URL, and authenticates us to the peer.
+func (ms *WsMessageService) dial(peer types.Address) (*websocket.Conn, error) {
+	url, ok := ms.peers.Load(peer.String())
+	if !ok {
+		return nil, fmt.Errorf("no URL known for peer %s", peer)
+	}
+	conn, _, err := ms.dialer.Dial(url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !ms.track(conn) {
+		return nil, fmt.Errorf("message service is closed")
+	}
+	conn.SetReadLimit(messageservice.MaxFrameSize)
+	err = authenticate(conn, ms.signer, peer)
+	if err != nil {
+		ms.untrack(conn)
+		return nil, fmt.Errorf("could not authenticate to peer %s: %w", peer, err)
+	}
+	return conn, nil
+}
+
+// authChallenge is sent by a peer which accepts a connection. It is answered with an authResponse.
+type authChallenge struct {
+	Nonce []byte
+}
+
+// authResponse proves that the connecting peer controls the key for the address which signed it.
+type authResponse struct {
+	Signature crypto.Signature
+}
+
+// authPayload returns the data a connecting peer signs in response to the challenge from the recipient.
+// The recipient's address is included so that a challenge cannot be relayed to another peer.
+func authPayload(recipient types.Address, nonce []byte) []byte {
+	payload := append([]byte("go-nitro/wsms/auth/"), recipient.Bytes()...)
+	return append(payload, nonce...)
+}
+
+// challenge sends a random nonce to the peer which opened the connection, and returns the address which signs it.
+func (ms *WsMessageService) challenge(conn *websocket.Conn) (types.Address, error) {
+	nonce := make([]byte, NONCE_SIZE)
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return types.Address{}, err
+	}
+	_ = conn.SetWriteDeadline(time.Now().Add(AUTH_TIMEOUT))
+	err = conn.WriteJSON(authChallenge{Nonce: nonce})
+	if err != nil {
+		return types.Address{}, err
+	}
+
+	var response authResponse
+	_ = conn.SetReadDeadline(time.Now().Add(AUTH_TIMEOUT))
+	err = conn.ReadJSON(&response)
+	if err != nil {
+		return types.Address{}, err
+	}
+	return crypto.RecoverEthereumMessageSigner(authPayload(ms.me, nonce), response.Signature)
+}
+
+// authenticate answers the challenge sent by the peer we have connected to.
+func authenticate(conn *websocket.Conn, signer crypto.Signer, peer types.Address) error {
+	var c authChallenge
+	_ = conn.SetReadDeadline(time.Now().Add(AUTH_TIMEOUT))
+	err := conn.ReadJSON(&c)
+	if err != nil {
+		return err
+	}
+	if len(c.Nonce) != NONCE_SIZE {
+		return fmt.Errorf("expected a nonce of %d bytes, got %d", NONCE_SIZE, len(c.Nonce))
+	}
+	sig, err := signer.SignMessage(authPayload(peer, c.Nonce))
+	if err != nil {
+		return err
+	}
+	_ = conn.SetWriteDeadline(time.Now().Add(AUTH_TIMEOUT))
+	return conn.WriteJSON(authResponse{Signature: sig})
+}
+
+// transmit writes the envelope to the connection and waits for it to be acknowledged.
+func transmit(conn *websocket.Conn, e messageservice.Envelope) error {
+	format := wireFormatOf(conn)
+	raw, err := format.EncodeEnvelope(e)
+	if err != nil {
+		return err
+	}
+	_ = conn.SetWriteDeadline(time.Now().Add(ACK_TIMEOUT))
+	err = conn.WriteMessage(messageTypeFor(format), raw)
+	if err != nil {
+		return err
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(ACK_TIMEOUT))
+	_, rawAck, err := conn.ReadMessage()
+	if err != nil {
+		return fmt.Errorf("message was not acknowledged: %w", err)
+	}
+	a, err := format.DecodeAck(rawAck)
+	if err != nil {
+		return err
+	}
+	if a.Seq != e.Seq {
+		return fmt.Errorf("expected acknowledgement of message %d, got %d", e.Seq, a.Seq)
+	}
+	return nil
+}
+
+// wireFormatOf returns the wire format negotiated as the connection's subprotocol.
+// Peers which do not negotiate a subprotocol use JSON.
+func wireFormatOf(conn *websocket.Conn) messageservice.WireFormat {
+	for _, format := range messageservice.SupportedWireFormats {
+		if conn.Subprotocol() == string(format) {
+			return format
+		}
+	}
+	return messageservice.JSONFormat
+}
+
+// messageTypeFor returns the WebSocket message type used to carry the wire format.
+func messageTypeFor(format messageservice.WireFormat) int {
+	if format == messageservice.JSONFormat {
+		return websocket.TextMessage
+	}
+	return websocket.BinaryMessage
+}
+
+// track records an open connection so that it is closed when the message service is closed.
+// It closes the connection and returns false if the message service is already closed.
+func (ms *WsMessageService) track(conn *websocket.Conn) bool {
+	ms.connsMu.Lock()
+	defer ms.connsMu.Unlock()
+	select {
+	case <-ms.quit:
+		conn.Close()
+		return false
+	default:
+	}
+	ms.conns[conn] = struct{}{}
+	return true
+}
+
+// untrack closes the connection and forgets about it.
+func (ms *WsMessageService) untrack(conn *websocket.Conn) {
+	ms.connsMu.Lock()
+	defer ms.connsMu.Unlock()
+	conn.Close()
+	delete(ms.conns, conn)
+}
+
+// Out returns a channel that can be used to receive messages from the message service
+func (ms *WsMessageService) Out() <-chan protocols.Message {
+	return ms.toEngine
+}
+
+// Close stops delivering messages and closes all open connections.
+// It does not stop the HTTP server the message service is mounted on.
+func (ms *WsMessageService) Close() {
+	ms.connsMu.Lock()
+	defer ms.connsMu.Unlock()
+	close(ms.quit)
+	for conn := range ms.conns {
+		conn.Close()
+	}
+	ms.conns = make(map[*websocket.Conn]struct{})
+}
@@ -0,0 +1,247 @@
+package wsms
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/statechannels/go-nitro/client/engine/messageservice"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+var alice = testactors.Alice
+var bob = testactors.Bob
+
+// signedMessage returns a message from alice to bob which can be identified by its (only) rejected objective.
+func signedMessage(t *testing.T, id protocols.ObjectiveId, signer []byte) protocols.Message {
+	msg := protocols.Message{
+		To:                 bob.Address(),
+		RejectedObjectives: []protocols.RejectionNotice{{ObjectiveId: id}},
+	}
+	err := msg.Sign(signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+// partitionable wraps a handler so that it can be made unreachable.
+type partitionable struct {
+	handler     http.Handler
+	partitioned atomic.Bool
+}
+
+func (p *partitionable) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if p.partitioned.Load() {
+		http.Error(w, "partitioned", http.StatusServiceUnavailable)
+		return
+	}
+	p.handler.ServeHTTP(w, r)
+}
+
+// serve mounts the message service on a new TLS test server, and returns the server and its wss:// URL.
+func serve(ms *WsMessageService) (*httptest.Server, *partitionable, string) {
+	p := &partitionable{handler: ms}
+	server := httptest.NewTLSServer(p)
+	return server, p, "wss" + strings.TrimPrefix(server.URL, "https")
+}
+
+// trusting returns a TLS configuration which trusts the test server's certificate.
+func trusting(server *httptest.Server) *tls.Config {
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	return &tls.Config{RootCAs: pool}
+}
+
+// dialAs opens a connection to the server, authenticated as the given actor.
+func dialAs(t *testing.T, dialer websocket.Dialer, url string, as testactors.Actor) *websocket.Conn {
+	conn, _, err := dialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := authenticate(conn, as.Signer(), bob.Address()); err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+func expectMessages(t *testing.T, ms *WsMessageService, ids ...protocols.ObjectiveId) {
+	for _, id := range ids {
+		select {
+		case got := <-ms.Out():
+			if got.RejectedObjectives[0].ObjectiveId != id {
+				t.Fatalf("expected message %s, got %s", id, got.RejectedObjectives[0].ObjectiveId)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for message %s", id)
+		}
+	}
+}
+
+func expectNoMessage(t *testing.T, ms *WsMessageService) {
+	select {
+	case extra := <-ms.Out():
+		t.Fatalf("expected no further messages, got %v", extra.Summarize())
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestDeliveryAcrossPartition(t *testing.T) {
+	msB := NewMessageService(bob.Signer(), nil, nil, nil)
+	defer msB.Close()
+	server, p, url := serve(msB)
+	defer server.Close()
+
+	msA := NewMessageService(alice.Signer(), map[types.Address]string{bob.Address(): url}, trusting(server), nil)
+	defer msA.Close()
+
+	msA.Send(signedMessage(t, "before", alice.PrivateKey))
+	expectMessages(t, msB, "before")
+
+	// Bob becomes unreachable, and his existing connections are dropped
+	p.partitioned.Store(true)
+	server.CloseClientConnections()
+
+	const numMessages = 3
+	ids := make([]protocols.ObjectiveId, numMessages)
+	for i := range ids {
+		ids[i] = protocols.ObjectiveId(fmt.Sprint(i))
+		start := time.Now()
+		msA.Send(signedMessage(t, ids[i], alice.PrivateKey))
+		if time.Since(start) > 100*time.Millisecond {
+			t.Fatal("expected Send not to block while the peer is unreachable")
+		}
+	}
+	time.Sleep(300 * time.Millisecond)
+
+	p.partitioned.Store(false)
+	expectMessages(t, msB, ids...)
+	expectNoMessage(t, msB)
+}
+
+func TestForgedMessagesAreDropped(t *testing.T) {
+	msB := NewMessageService(bob.Signer(), nil, nil, nil)
+	defer msB.Close()
+	server, _, url := serve(msB)
+	defer server.Close()
+
+	conn := dialAs(t, websocket.Dialer{TLSClientConfig: trusting(server)}, url, alice)
+	defer conn.Close()
+
+	// A message claiming to be from alice, but signed by someone else
+	forged := signedMessage(t, "forged", testactors.Irene.PrivateKey)
+	forged.From = alice.Address()
+	err := conn.WriteJSON(messageservice.Envelope{Session: 1, Seq: 1, Message: forged})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var a messageservice.Ack
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	if conn.ReadJSON(&a) == nil {
+		t.Fatal("expected a forged message not to be acknowledged")
+	}
+	expectNoMessage(t, msB)
+}
+
+func TestWireFormatNegotiation(t *testing.T) {
+	msB := NewMessageService(bob.Signer(), nil, nil, nil)
+	defer msB.Close()
+	server, _, url := serve(msB)
+	defer server.Close()
+
+	// Peers which support the binary format negotiate it
+	dialer := websocket.Dialer{TLSClientConfig: trusting(server), Subprotocols: []string{string(messageservice.BinaryFormat), string(messageservice.JSONFormat)}}
+	conn := dialAs(t, dialer, url, alice)
+	if conn.Subprotocol() != string(messageservice.BinaryFormat) {
+		t.Fatalf("expected the binary format to be negotiated, got %q", conn.Subprotocol())
+	}
+	err := transmit(conn, messageservice.Envelope{Session: 1, Seq: 1, Message: signedMessage(t, "binary", alice.PrivateKey)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	expectMessages(t, msB, "binary")
+
+	// Peers which do not negotiate a subprotocol fall back to JSON
+	dialer.Subprotocols = nil
+	conn = dialAs(t, dialer, url, alice)
+	defer conn.Close()
+	err = conn.WriteJSON(messageservice.Envelope{Session: 2, Seq: 1, Message: signedMessage(t, "json", alice.PrivateKey)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var a messageservice.Ack
+	err = conn.ReadJSON(&a)
+	if err != nil || a.Seq != 1 {
+		t.Fatalf("expected an acknowledgement of message 1, got %+v (err %v)", a, err)
+	}
+	expectMessages(t, msB, "json")
+}
+
+func TestReplayedMessagesAreDropped(t *testing.T) {
+	msB := NewMessageService(bob.Signer(), nil, nil, nil)
+	defer msB.Close()
+	server, _, url := serve(msB)
+	defer server.Close()
+	dialer := websocket.Dialer{TLSClientConfig: trusting(server)}
+
+	conn := dialAs(t, dialer, url, alice)
+	captured := messageservice.Envelope{Session: 1, Seq: 1, Message: signedMessage(t, "captured", alice.PrivateKey)}
+	if err := transmit(conn, captured); err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	expectMessages(t, msB, "captured")
+
+	// Someone other than alice replays her message in a new session
+	conn = dialAs(t, dialer, url, testactors.Irene)
+	defer conn.Close()
+	captured.Session = 2
+	if transmit(conn, captured) == nil {
+		t.Fatal("expected a replayed message not to be acknowledged")
+	}
+	expectNoMessage(t, msB)
+}
+
+func TestUnauthenticatedConnectionsAreClosed(t *testing.T) {
+	msB := NewMessageService(bob.Signer(), nil, nil, nil)
+	defer msB.Close()
+	server, _, url := serve(msB)
+	defer server.Close()
+
+	conn, _, err := (&websocket.Dialer{TLSClientConfig: trusting(server)}).Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// Sending a message without answering the challenge closes the connection
+	if transmit(conn, messageservice.Envelope{Session: 1, Seq: 1, Message: signedMessage(t, "unauthenticated", alice.PrivateKey)}) == nil {
+		t.Fatal("expected a message over an unauthenticated connection not to be acknowledged")
+	}
+	expectNoMessage(t, msB)
+
+	// A challenge signed for another recipient does not authenticate the connection
+	conn, _, err = (&websocket.Dialer{TLSClientConfig: trusting(server)}).Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := authenticate(conn, alice.Signer(), testactors.Irene.Address()); err != nil {
+		t.Fatal(err)
+	}
+	if transmit(conn, messageservice.Envelope{Session: 1, Seq: 1, Message: signedMessage(t, "relayed", alice.PrivateKey)}) == nil {
+		t.Fatal("expected a message over a connection authenticated for another recipient not to be acknowledged")
+	}
+	expectNoMessage(t, msB)
+}
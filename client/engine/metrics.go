@@ -127,6 +127,11 @@ func (o *MetricsRecorder) RecordQueueLength(name string, queueLength int) {
 	o.metrics.Gauge(o.addMyAddress(name)).Update(float64(queueLength))
 }
 
+// RecordLimitTriggered records that a message or objective from the peer was refused because of the named inbound limit
+func (o *MetricsRecorder) RecordLimitTriggered(limit string, peer types.Address) {
+	o.metrics.RecordPoint(o.addMyAddress("inbound_limit_triggered")+fmt.Sprintf(",limit=%s,peer=%s", limit, peer), 1)
+}
+
 func (o *MetricsRecorder) addMyAddress(name string) string {
 	return fmt.Sprintf("%s,wallet=%s", name, o.me.String())
 }
@@ -4,13 +4,17 @@ import "github.com/statechannels/go-nitro/protocols"
 
 // PolicyMaker is used to decide whether to approve or reject an objective
 type PolicyMaker interface {
-	ShouldApprove(o protocols.Objective) bool
+	// ShouldApprove decides whether to approve o. When o is not approved, the returned reason is sent to the other participants.
+	ShouldApprove(o protocols.Objective) (approve bool, reason protocols.RejectionReason)
 }
 
 // PermissivePolicy is a policy maker that decides to approve every unapproved objective
 type PermissivePolicy struct{}
 
 // ShouldApprove decides to approve o if it is currently unapproved
-func (pp *PermissivePolicy) ShouldApprove(o protocols.Objective) bool {
-	return o.GetStatus() == protocols.Unapproved
+func (pp *PermissivePolicy) ShouldApprove(o protocols.Objective) (bool, protocols.RejectionReason) {
+	if o.GetStatus() != protocols.Unapproved {
+		return false, protocols.RejectionReason{Code: protocols.Unspecified, Message: "objective is not awaiting approval"}
+	}
+	return true, protocols.RejectionReason{}
 }
@@ -3,6 +3,7 @@ package store
 import (
 	"encoding/json"
 	"fmt"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/statechannels/go-nitro/channel"
@@ -12,6 +13,7 @@ import (
 	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/protocols/directdefund"
 	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/dispute"
 	"github.com/statechannels/go-nitro/protocols/virtualdefund"
 	"github.com/statechannels/go-nitro/protocols/virtualfund"
 	"github.com/statechannels/go-nitro/types"
@@ -22,20 +24,35 @@ type MemStore struct {
 	channels           safesync.Map[[]byte]
 	consensusChannels  safesync.Map[[]byte]
 	channelToObjective safesync.Map[protocols.ObjectiveId]
+	lastBlockNumSeen   safesync.Map[uint64] // the last block number seen on each chain, keyed by chain id
+	outboxes           safesync.Map[[]byte] // persisted outboxes, keyed by peer address
 
-	key     string // the signing key of the store's engine
-	address string // the (Ethereum) address associated to the signing key
+	signer  crypto.Signer // the signer of the store's engine
+	address string        // the (Ethereum) address of the signer
 }
 
+// NewMemStore returns a MemStore whose engine signs with the supplied secret key, held in memory.
+// To avoid handling the raw key, use NewMemStoreWithSigner with a Signer loaded from a keystore file or mnemonic.
 func NewMemStore(key []byte) Store {
+	signer, err := crypto.NewInMemorySigner(key)
+	if err != nil {
+		panic(err)
+	}
+	return NewMemStoreWithSigner(signer)
+}
+
+// NewMemStoreWithSigner returns a MemStore whose engine signs with the supplied signer.
+func NewMemStoreWithSigner(signer crypto.Signer) Store {
 	ms := MemStore{}
-	ms.key = common.Bytes2Hex(key)
-	ms.address = crypto.GetAddressFromSecretKeyBytes(key).String()
+	ms.signer = signer
+	ms.address = signer.Address().String()
 
 	ms.objectives = safesync.Map[[]byte]{}
 	ms.channels = safesync.Map[[]byte]{}
 	ms.consensusChannels = safesync.Map[[]byte]{}
 	ms.channelToObjective = safesync.Map[protocols.ObjectiveId]{}
+	ms.lastBlockNumSeen = safesync.Map[uint64]{}
+	ms.outboxes = safesync.Map[[]byte]{}
 
 	return &ms
 }
@@ -45,9 +62,49 @@ func (ms *MemStore) GetAddress() *types.Address {
 	return &address
 }
 
-func (ms *MemStore) GetChannelSecretKey() *[]byte {
-	val := common.Hex2Bytes(ms.key)
-	return &val
+func (ms *MemStore) GetChannelSigner() crypto.Signer {
+	return ms.signer
+}
+
+func (ms *MemStore) GetLastBlockNumSeen(chainId *big.Int) uint64 {
+	blockNum, _ := ms.lastBlockNumSeen.Load(chainId.String())
+	return blockNum
+}
+
+func (ms *MemStore) SetLastBlockNumSeen(chainId *big.Int, blockNum uint64) error {
+	ms.lastBlockNumSeen.Store(chainId.String(), blockNum)
+	return nil
+}
+
+func (ms *MemStore) GetOutbox(peer types.Address) (PersistedOutbox, bool) {
+	outboxJSON, ok := ms.outboxes.Load(peer.String())
+	if !ok {
+		return PersistedOutbox{}, false
+	}
+	var outbox PersistedOutbox
+	err := json.Unmarshal(outboxJSON, &outbox)
+	if err != nil {
+		return PersistedOutbox{}, false
+	}
+	return outbox, true
+}
+
+func (ms *MemStore) SetOutbox(peer types.Address, outbox PersistedOutbox) error {
+	outboxJSON, err := json.Marshal(outbox)
+	if err != nil {
+		return fmt.Errorf("error setting outbox for %s: %w", peer, err)
+	}
+	ms.outboxes.Store(peer.String(), outboxJSON)
+	return nil
+}
+
+func (ms *MemStore) GetOutboxPeers() []types.Address {
+	peers := []types.Address{}
+	ms.outboxes.Range(func(key string, _ []byte) bool {
+		peers = append(peers, common.HexToAddress(key))
+		return true
+	})
+	return peers
 }
 
 func (ms *MemStore) GetObjectiveById(id protocols.ObjectiveId) (protocols.Objective, error) {
@@ -148,6 +205,16 @@ func (ms *MemStore) DestroyConsensusChannel(id types.Destination) {
 	ms.consensusChannels.Delete(id.String())
 }
 
+// GetConsensusChannelIds returns the ids of the consensus channels in the store.
+func (ms *MemStore) GetConsensusChannelIds() []types.Destination {
+	ids := []types.Destination{}
+	ms.consensusChannels.Range(func(key string, _ []byte) bool {
+		ids = append(ids, types.Destination(common.HexToHash(key)))
+		return true
+	})
+	return ids
+}
+
 // GetChannelById retrieves the channel with the supplied id, if it exists.
 func (ms *MemStore) GetChannelById(id types.Destination) (c *channel.Channel, ok bool) {
 	ch, err := ms.getChannelById(id)
@@ -177,8 +244,8 @@ func (ms *MemStore) getChannelById(id types.Destination) (channel.Channel, error
 	return ch, nil
 }
 
-// GetChannelsByParticipant returns any channels that include the given participant
-func (ms *MemStore) GetChannelsByParticipant(participant types.Address) []*channel.Channel {
+// GetChannelsByParticipant returns any channels on the given chain that include the given participant
+func (ms *MemStore) GetChannelsByParticipant(participant types.Address, chainId *big.Int) []*channel.Channel {
 	toReturn := []*channel.Channel{}
 	ms.channels.Range(func(key string, chJSON []byte) bool {
 
@@ -191,7 +258,7 @@ func (ms *MemStore) GetChannelsByParticipant(participant types.Address) []*chann
 
 		participants := ch.FixedPart.Participants
 		for _, p := range participants {
-			if p == participant {
+			if p == participant && ch.ChainId.Cmp(chainId) == 0 {
 				toReturn = append(toReturn, &ch)
 			}
 
@@ -222,9 +289,9 @@ func (ms *MemStore) GetConsensusChannelById(id types.Destination) (channel *cons
 	return ch, nil
 }
 
-// GetConsensusChannel returns a ConsensusChannel between the calling client and
+// GetConsensusChannel returns a ConsensusChannel on the given chain between the calling client and
 // the supplied counterparty, if such channel exists
-func (ms *MemStore) GetConsensusChannel(counterparty types.Address) (channel *consensus_channel.ConsensusChannel, ok bool) {
+func (ms *MemStore) GetConsensusChannel(counterparty types.Address, chainId *big.Int) (channel *consensus_channel.ConsensusChannel, ok bool) {
 
 	ms.consensusChannels.Range(func(key string, chJSON []byte) bool {
 
@@ -236,7 +303,7 @@ func (ms *MemStore) GetConsensusChannel(counterparty types.Address) (channel *co
 		}
 
 		participants := ch.Participants()
-		if len(participants) == 2 {
+		if len(participants) == 2 && ch.FixedPart().ChainId.Cmp(chainId) == 0 {
 			if participants[0] == counterparty || participants[1] == counterparty {
 				channel = &ch
 				ok = true
@@ -319,6 +386,9 @@ func (ms *MemStore) populateChannelData(obj protocols.Objective) error {
 			o.ToMyRight.Channel = right
 		}
 
+		return nil
+	case *dispute.Objective:
+		// The objective holds the state it disputes
 		return nil
 	case *virtualdefund.Objective:
 
@@ -371,6 +441,10 @@ func decodeObjective(id protocols.ObjectiveId, data []byte) (protocols.Objective
 		dvfo := virtualdefund.Objective{}
 		err := dvfo.UnmarshalJSON(data)
 		return &dvfo, err
+	case dispute.IsDisputeObjective(id):
+		do := dispute.Objective{}
+		err := do.UnmarshalJSON(data)
+		return &do, err
 	default:
 		return nil, fmt.Errorf("objective id %s does not correspond to a known Objective type", id)
 
@@ -113,17 +113,39 @@ func TestGetObjectiveByChannelId(t *testing.T) {
 	}
 }
 
-func TestGetChannelSecretKey(t *testing.T) {
+func TestSetGetLastBlockNumSeen(t *testing.T) {
+	sk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+
+	ms := store.NewMemStore(sk)
+	chainId, otherChainId := big.NewInt(1337), big.NewInt(9001)
+	if got := ms.GetLastBlockNumSeen(chainId); got != 0 {
+		t.Fatalf("expected no block to have been seen, got %d", got)
+	}
+	if err := ms.SetLastBlockNumSeen(chainId, 42); err != nil {
+		t.Fatal(err)
+	}
+	if got := ms.GetLastBlockNumSeen(chainId); got != 42 {
+		t.Fatalf("expected block 42 to have been seen, got %d", got)
+	}
+	if got := ms.GetLastBlockNumSeen(otherChainId); got != 0 {
+		t.Fatalf("expected no block to have been seen on another chain, got %d", got)
+	}
+}
+
+func TestGetChannelSigner(t *testing.T) {
 	// from state/test-fixtures.go
 	sk := common.Hex2Bytes("caab404f975b4620747174a75f08d98b4e5a7053b691b41bcfc0d839d48b7634")
 	pk := common.HexToAddress("0xF5A1BB5607C9D079E46d1B3Dc33f257d937b43BD")
 
 	ms := store.NewMemStore(sk)
-	key := ms.GetChannelSecretKey()
+	signer := ms.GetChannelSigner()
+	if signer.Address() != pk {
+		t.Fatalf("expected signer address %x, but got %x", pk, signer.Address())
+	}
 
 	msg := []byte("sign this")
 
-	signedMsg, _ := nc.SignEthereumMessage(msg, *key)
+	signedMsg, _ := signer.SignMessage(msg)
 	recoveredSigner, _ := nc.RecoverEthereumMessageSigner(msg, signedMsg)
 
 	if recoveredSigner != pk {
@@ -136,7 +158,7 @@ func TestConsensusChannelStore(t *testing.T) {
 
 	ms := store.NewMemStore(sk)
 
-	got, ok := ms.GetConsensusChannel(ta.Alice.Address())
+	got, ok := ms.GetConsensusChannel(ta.Alice.Address(), big.NewInt(1337))
 	if ok {
 		t.Fatalf("expected not to find the a consensus channel, but found %v", got)
 	}
@@ -169,7 +191,7 @@ func TestConsensusChannelStore(t *testing.T) {
 	// Generate a new proposal so we test that the proposal queue is being fetched properly
 	proposedGuarantee := cc.NewGuarantee(big.NewInt(1), types.Destination{2}, left.AsAllocation().Destination, right.AsAllocation().Destination)
 	proposal := cc.NewAddProposal(leader.Id, proposedGuarantee, big.NewInt(1))
-	_, err = leader.Propose(proposal, ta.Alice.PrivateKey)
+	_, err = leader.Propose(proposal, ta.Alice.Signer())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -181,7 +203,7 @@ func TestConsensusChannelStore(t *testing.T) {
 		t.Fatalf("error setting consensus channel %v: %s", want, err.Error())
 	}
 
-	got, ok = ms.GetConsensusChannel(fp.Participants[1])
+	got, ok = ms.GetConsensusChannel(fp.Participants[1], fp.ChainId)
 
 	if !ok {
 		t.Fatalf("expected to find the inserted consensus channel, but didn't")
@@ -194,6 +216,19 @@ func TestConsensusChannelStore(t *testing.T) {
 	if diff := cmp.Diff(*got, want, cmp.AllowUnexported(cc.ConsensusChannel{}, big.Int{}, cc.LedgerOutcome{}, cc.Balance{}, cc.Guarantee{}, cc.Add{}, cc.Proposal{}, cc.Remove{})); diff != "" {
 		t.Fatalf("fetched result different than expected %s", diff)
 	}
+
+	otherChainId := new(big.Int).Add(fp.ChainId, big.NewInt(1))
+	if got, ok := ms.GetConsensusChannel(fp.Participants[1], otherChainId); ok {
+		t.Fatalf("expected not to find a consensus channel on another chain, but found %v", got)
+	}
+
+	if ids := ms.GetConsensusChannelIds(); len(ids) != 1 || ids[0] != want.Id {
+		t.Fatalf("expected the ids of the stored consensus channels to be [%s], got %v", want.Id, ids)
+	}
+	ms.DestroyConsensusChannel(want.Id)
+	if ids := ms.GetConsensusChannelIds(); len(ids) != 0 {
+		t.Fatalf("expected no consensus channels after destroying the channel, got %v", ids)
+	}
 }
 
 func TestGetChannelsByParticipant(t *testing.T) {
@@ -204,10 +239,15 @@ func TestGetChannelsByParticipant(t *testing.T) {
 	want := []*channel.Channel{c}
 	_ = ms.SetChannel(c)
 
-	got := ms.GetChannelsByParticipant(c.Participants[0])
+	got := ms.GetChannelsByParticipant(c.Participants[0], c.ChainId)
 
 	if diff := cmp.Diff(got, want, cmp.AllowUnexported(channel.Channel{}, big.Int{}, state.SignedState{})); diff != "" {
 		t.Fatalf("fetched result different than expected %s", diff)
 	}
 
+	otherChainId := new(big.Int).Add(c.ChainId, big.NewInt(1))
+	if got := ms.GetChannelsByParticipant(c.Participants[0], otherChainId); len(got) != 0 {
+		t.Fatalf("expected no channels on another chain, got %v", got)
+	}
+
 }
@@ -3,9 +3,11 @@ package store // import "github.com/statechannels/go-nitro/client/engine/store"
 
 import (
 	"errors"
+	"math/big"
 
 	"github.com/statechannels/go-nitro/channel"
 	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/crypto"
 	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/types"
 )
@@ -15,28 +17,53 @@ var (
 	ErrNoSuchChannel   error = errors.New("store: failed to find required channel data")
 )
 
-// Store is responsible for persisting objectives, objective metadata, states, signatures, private keys and blockchain data
+// Store is responsible for persisting objectives, objective metadata, states, signatures and blockchain data, and for providing the engine's signer
 type Store interface {
-	GetChannelSecretKey() *[]byte // Get a pointer to a secret key for signing channel updates
-	GetAddress() *types.Address   // Get the (Ethereum) address associated with the ChannelSecretKey
+	GetChannelSigner() crypto.Signer // Get the signer for channel updates, vouchers and messages
+	GetAddress() *types.Address      // Get the (Ethereum) address of the ChannelSigner
 
 	GetObjectiveById(protocols.ObjectiveId) (protocols.Objective, error)          // Read an existing objective
 	GetObjectiveByChannelId(types.Destination) (obj protocols.Objective, ok bool) // Get the objective that currently owns the channel with the supplied ChannelId
 	SetObjective(protocols.Objective) error                                       // Write an objective
 
 	GetChannelById(id types.Destination) (c *channel.Channel, ok bool)
-	GetChannelsByParticipant(participant types.Address) []*channel.Channel // Returns any channels that includes the given participant
+	GetChannelsByParticipant(participant types.Address, chainId *big.Int) []*channel.Channel // Returns any channels on the given chain that include the given participant
 	SetChannel(*channel.Channel) error
 	DestroyChannel(id types.Destination)
 
 	ReleaseChannelFromOwnership(types.Destination) // Release channel from being owned by any objective
 
+	GetLastBlockNumSeen(chainId *big.Int) uint64                 // Get the number of the latest block on the chain from which an adjudicator event has been handled
+	SetLastBlockNumSeen(chainId *big.Int, blockNum uint64) error // Record that an adjudicator event from the supplied block of the chain has been handled
+
 	ConsensusChannelStore
+	OutboxStore
 }
 
 type ConsensusChannelStore interface {
-	GetConsensusChannel(counterparty types.Address) (channel *consensus_channel.ConsensusChannel, ok bool)
+	GetConsensusChannel(counterparty types.Address, chainId *big.Int) (channel *consensus_channel.ConsensusChannel, ok bool)
 	GetConsensusChannelById(id types.Destination) (channel *consensus_channel.ConsensusChannel, err error)
 	SetConsensusChannel(*consensus_channel.ConsensusChannel) error
 	DestroyConsensusChannel(id types.Destination)
+	GetConsensusChannelIds() []types.Destination // Returns the ids of the consensus channels in the store
+}
+
+// OutboxStore persists the messages a message service has queued for each peer, so that messages which have not been acknowledged survive a restart
+type OutboxStore interface {
+	GetOutbox(peer types.Address) (outbox PersistedOutbox, ok bool)
+	SetOutbox(peer types.Address, outbox PersistedOutbox) error
+	GetOutboxPeers() []types.Address // Returns the peers which have an outbox in the store
+}
+
+// PersistedOutbox is the state of a message service's outbox for a single peer
+type PersistedOutbox struct {
+	Session uint64 // the session in which the messages were numbered
+	NextSeq uint64 // the sequence number of the last message pushed to the outbox
+	Pending []PendingMessage
+}
+
+// PendingMessage is a message which has not yet been acknowledged by its recipient
+type PendingMessage struct {
+	Seq     uint64
+	Message protocols.Message
 }
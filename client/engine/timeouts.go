@@ -0,0 +1,174 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/statechannels/go-nitro/protocols"
+)
+
+// minStallCheckInterval is the shortest interval at which the engine checks for stalled objectives.
+const minStallCheckInterval = 10 * time.Millisecond
+
+// ObjectiveDeadlines configures how long an objective may remain blocked before the engine intervenes.
+type ObjectiveDeadlines struct {
+	// RetryAfter is how long the engine waits without progress before resending the messages most recently sent for the objective.
+	// A zero value disables retries.
+	RetryAfter time.Duration
+	// FailAfter is how long the engine waits without progress before giving up on the objective.
+	// A zero value disables failure.
+	FailAfter time.Duration
+}
+
+// ObjectiveTimeouts maps an objective id prefix (e.g. virtualfund.ObjectivePrefix) to the deadlines for objectives of that type.
+// Objectives of a type without an entry are never timed out.
+type ObjectiveTimeouts map[string]ObjectiveDeadlines
+
+// deadlinesFor returns the deadlines that apply to the objective with the given id, if any.
+func (ot ObjectiveTimeouts) deadlinesFor(id protocols.ObjectiveId) (ObjectiveDeadlines, bool) {
+	for prefix, deadlines := range ot {
+		if strings.HasPrefix(string(id), prefix) {
+			return deadlines, true
+		}
+	}
+	return ObjectiveDeadlines{}, false
+}
+
+// checkInterval returns how often the engine should check for stalled objectives, or zero if no deadlines are configured.
+func (ot ObjectiveTimeouts) checkInterval() time.Duration {
+	interval := time.Duration(0)
+	for _, d := range ot {
+		for _, deadline := range []time.Duration{d.RetryAfter, d.FailAfter} {
+			if deadline > 0 && (interval == 0 || deadline/4 < interval) {
+				interval = deadline / 4
+			}
+		}
+	}
+	if interval > 0 && interval < minStallCheckInterval {
+		interval = minStallCheckInterval
+	}
+	return interval
+}
+
+// objectiveProgress records when an objective last made progress, and the messages it most recently sent.
+type objectiveProgress struct {
+	waitingFor   protocols.WaitingFor
+	lastProgress time.Time
+	lastAttempt  time.Time
+	lastMessages []protocols.Message
+}
+
+// recordProgress updates the progress record for an objective after it has been cranked.
+func (e *Engine) recordProgress(id protocols.ObjectiveId, waitingFor protocols.WaitingFor, sent []protocols.Message) {
+	if _, ok := e.timeouts.deadlinesFor(id); !ok {
+		return
+	}
+	now := time.Now()
+	p, ok := e.progress[id]
+	if !ok || p.waitingFor != waitingFor {
+		p = &objectiveProgress{waitingFor: waitingFor, lastProgress: now}
+		e.progress[id] = p
+	}
+	p.lastAttempt = now
+	if len(sent) > 0 {
+		p.lastMessages = sent
+	}
+}
+
+// stopTracking stops the engine from checking whether the objective has stalled.
+func (e *Engine) stopTracking(id protocols.ObjectiveId) {
+	delete(e.progress, id)
+}
+
+// checkForStalledObjectives resends messages for objectives which have not progressed within their RetryAfter deadline,
+// and gives up on objectives which have not progressed within their FailAfter deadline.
+func (e *Engine) checkForStalledObjectives() (EngineEvent, error) {
+	defer e.metrics.RecordFunctionDuration()()
+	now := time.Now()
+	res := EngineEvent{}
+
+	for id, p := range e.progress {
+		deadlines, _ := e.timeouts.deadlinesFor(id)
+		stalledFor := now.Sub(p.lastProgress)
+
+		if deadlines.FailAfter > 0 && stalledFor >= deadlines.FailAfter {
+			failed, err := e.abandonStalledObjective(id, p.waitingFor, stalledFor)
+			if err != nil {
+				return res, err
+			}
+			res.FailedObjectives = append(res.FailedObjectives, failed)
+			continue
+		}
+
+		if deadlines.RetryAfter > 0 && now.Sub(p.lastAttempt) >= deadlines.RetryAfter && len(p.lastMessages) > 0 {
+			e.logger.Printf("Objective %s has been %s for %s, resending %d message(s)", id, p.waitingFor, stalledFor, len(p.lastMessages))
+			p.lastAttempt = now
+			err := e.executeSideEffects(protocols.SideEffects{MessagesToSend: p.lastMessages})
+			if err != nil {
+				return res, err
+			}
+		}
+	}
+	return res, nil
+}
+
+// abandonStalledObjective gives up on an objective that has stalled (see failObjective).
+// If its funds are at risk, it is reported as requiring a dispute.
+func (e *Engine) abandonStalledObjective(id protocols.ObjectiveId, waitingFor protocols.WaitingFor, stalledFor time.Duration) (FailedObjective, error) {
+	objective, err := e.store.GetObjectiveById(id)
+	if err != nil {
+		return FailedObjective{}, fmt.Errorf("could not retrieve stalled objective %s: %w", id, err)
+	}
+
+	reason := protocols.RejectionReason{
+		Code:    protocols.TimedOut,
+		Message: fmt.Sprintf("no progress while %s for %s", waitingFor, stalledFor),
+	}
+	if abandonable, ok := objective.(protocols.AbandonableObjective); !ok || !abandonable.SafeToAbandon() {
+		reason = protocols.RejectionReason{
+			Code:    protocols.DisputeRequired,
+			Message: fmt.Sprintf("no progress while %s for %s and funds are at risk", waitingFor, stalledFor),
+		}
+	}
+	_, err = e.failObjective(objective, reason)
+	return FailedObjective{Id: id, Reason: reason}, err
+}
+
+// failObjective gives up on an objective which can make no further progress: it is rejected (notifying the other participants) and closed.
+//
+// If the objective was not safe to abandon, its funds are at risk, so the channels holding them are handed to dispute objectives (see startDispute).
+func (e *Engine) failObjective(objective protocols.Objective, reason protocols.RejectionReason) (EngineEvent, error) {
+	failed := EngineEvent{FailedObjectives: []FailedObjective{{Id: objective.Id(), Reason: reason}}}
+
+	abandonable, ok := objective.(protocols.AbandonableObjective)
+	if ok && abandonable.SafeToAbandon() {
+		e.logger.Printf("Abandoning objective %s: %s", objective.Id(), reason)
+		return failed, e.rejectObjective(objective, reason)
+	}
+
+	e.logger.Printf("Objective %s has failed and cannot be abandoned: %s", objective.Id(), reason)
+	err := e.rejectObjective(objective, reason)
+	if err != nil {
+		return failed, err
+	}
+	disputes, err := e.startDispute(objective)
+	failed.CompletedObjectives = disputes.CompletedObjectives
+	failed.FailedObjectives = append(failed.FailedObjectives, disputes.FailedObjectives...)
+	return failed, err
+}
+
+// rejectObjective rejects the objective, notifying the other participants, and closes it (see closeObjective).
+func (e *Engine) rejectObjective(objective protocols.Objective, reason protocols.RejectionReason) error {
+	rejected, sideEffects := objective.Reject(reason)
+	err := e.store.SetObjective(rejected)
+	if err != nil {
+		return err
+	}
+	err = e.closeObjective(rejected)
+	if err != nil {
+		return err
+	}
+
+	return e.executeSideEffects(sideEffects)
+}
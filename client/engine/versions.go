@@ -0,0 +1,180 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directdefund"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/virtualdefund"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// SupportedVersions are the protocol and objective versions implemented by this engine.
+// When an objective's protocol changes incompatibly, the new version is added to its list, and older versions are removed
+// once they are no longer supported.
+var SupportedVersions = protocols.Versions{
+	Protocol: protocols.ProtocolVersion,
+	Objectives: map[string][]uint{
+		directfund.ObjectivePrefix:    {1},
+		directdefund.ObjectivePrefix:  {1},
+		virtualfund.ObjectivePrefix:   {1},
+		virtualdefund.ObjectivePrefix: {1},
+	},
+}
+
+// acceptVersions records the versions announced in the message, if any.
+// It returns false if the sender speaks a different protocol version, in which case the message should be ignored.
+//
+// Peers announce their versions in their first message to us. If a peer we already know announces its versions again,
+// it has restarted and forgotten ours, so we announce ours again. If a peer we do not know sends a message without
+// its versions, we have forgotten them, and announcing ours again prompts the peer to announce its own.
+func (e *Engine) acceptVersions(message protocols.Message) bool {
+	_, known := e.peerVersions[message.From]
+	if message.Versions != nil {
+		e.peerVersions[message.From] = *message.Versions
+	}
+	if known == (message.Versions != nil) {
+		delete(e.announced, message.From)
+	}
+	peer, ok := e.peerVersions[message.From]
+	if ok && peer.Protocol != e.versions.Protocol {
+		e.logger.Printf("Ignoring message from %s, which speaks protocol version %d", message.From, peer.Protocol)
+		return false
+	}
+	return true
+}
+
+// negotiateVersion chooses the version of the objective's protocol to run: the highest version supported by this engine
+// and by every other participant whose versions are known. It returns the objective recording that version.
+// If there is no such version, it returns false along with an event reporting that the objective failed.
+func (e *Engine) negotiateVersion(objective protocols.Objective) (protocols.Objective, EngineEvent, bool) {
+	me := *e.store.GetAddress()
+	peers := []protocols.Versions{}
+	for _, p := range objective.Participants() {
+		if v, ok := e.peerVersions[p]; ok && p != me {
+			peers = append(peers, v)
+		}
+	}
+
+	version, err := e.versions.Negotiate(protocols.ObjectiveType(objective.Id()), peers...)
+	if err != nil {
+		reason := protocols.RejectionReason{Code: protocols.IncompatibleVersion, Message: err.Error()}
+		e.logger.Printf("Refusing to start objective %s: %s", objective.Id(), reason)
+		return objective, EngineEvent{FailedObjectives: []FailedObjective{{Id: objective.Id(), Reason: reason}}}, false
+	}
+	return e.setObjectiveVersion(objective, version), EngineEvent{}, true
+}
+
+// checkPayloadVersion returns an error if the payload was created under a version of its objective's protocol
+// which this engine does not support.
+func (e *Engine) checkPayloadVersion(payload protocols.ObjectivePayload) error {
+	version := payload.ObjectiveVersion()
+	if !e.versions.Supports(protocols.ObjectiveType(payload.ObjectiveId), version) {
+		return fmt.Errorf("%w: version %d of objective %s is not supported", ErrIncompatibleVersion, version, payload.ObjectiveId)
+	}
+	return nil
+}
+
+// recordPayloadVersion returns the objective recording the version of the payload, unless the objective's version
+// has already been negotiated. An objective restored from the store after a restart keeps the version it recorded.
+func (e *Engine) recordPayloadVersion(objective protocols.Objective, payload protocols.ObjectivePayload) protocols.Objective {
+	if version, ok := e.objectiveVersions[objective.Id()]; ok {
+		return e.setObjectiveVersion(objective, version)
+	}
+	if v, ok := objective.(protocols.VersionedObjective); ok && v.GetVersion() != 0 {
+		return e.setObjectiveVersion(objective, v.GetVersion())
+	}
+	return e.setObjectiveVersion(objective, payload.ObjectiveVersion())
+}
+
+// setObjectiveVersion records the version of the objective's protocol, and counts the objective as open with each of its other participants.
+// It returns the objective recording the version, so that the version is stored with it.
+func (e *Engine) setObjectiveVersion(objective protocols.Objective, version uint) protocols.Objective {
+	if _, ok := e.objectiveVersions[objective.Id()]; !ok {
+		for _, p := range e.otherParticipants(objective) {
+			e.openWith[p]++
+		}
+	}
+	e.objectiveVersions[objective.Id()] = version
+	if v, ok := objective.(protocols.VersionedObjective); ok && v.GetVersion() != version {
+		return v.WithVersion(version)
+	}
+	return objective
+}
+
+// forgetVersions arranges for the version of an objective which has completed or failed to be forgotten,
+// once the messages queued in the current run loop iteration (which may include its final payloads) have been sent.
+func (e *Engine) forgetVersions(objective protocols.Objective) {
+	e.closedObjectives = append(e.closedObjectives, objective)
+}
+
+// pruneVersions forgets the versions of the objectives passed to forgetVersions.
+// Once no open objective is shared with a peer, the peer's versions are forgotten too, and ours are announced again in our next message to it.
+func (e *Engine) pruneVersions() {
+	for _, objective := range e.closedObjectives {
+		if _, ok := e.objectiveVersions[objective.Id()]; !ok {
+			continue
+		}
+		delete(e.objectiveVersions, objective.Id())
+		for _, p := range e.otherParticipants(objective) {
+			e.openWith[p]--
+			if e.openWith[p] <= 0 {
+				delete(e.openWith, p)
+				delete(e.peerVersions, p)
+				delete(e.announced, p)
+			}
+		}
+	}
+	e.closedObjectives = nil
+}
+
+// otherParticipants returns the participants in the objective other than us.
+func (e *Engine) otherParticipants(objective protocols.Objective) []types.Address {
+	me := *e.store.GetAddress()
+	others := []types.Address{}
+	for _, p := range objective.Participants() {
+		if p != me {
+			others = append(others, p)
+		}
+	}
+	return others
+}
+
+// objectiveVersion returns the version of the objective's protocol which its payloads should be sent under.
+// Objectives which were not started or joined since the engine was constructed use the version recorded in the store,
+// or if there is none, the highest version supported by the recipient.
+func (e *Engine) objectiveVersion(id protocols.ObjectiveId, recipient types.Address) uint {
+	if version, ok := e.objectiveVersions[id]; ok {
+		return version
+	}
+	if o, err := e.store.GetObjectiveById(id); err == nil {
+		if v, ok := o.(protocols.VersionedObjective); ok && v.GetVersion() != 0 {
+			return v.GetVersion()
+		}
+	}
+	peers := []protocols.Versions{}
+	if v, ok := e.peerVersions[recipient]; ok {
+		peers = append(peers, v)
+	}
+	version, err := e.versions.Negotiate(protocols.ObjectiveType(id), peers...)
+	if err != nil {
+		return protocols.DefaultObjectiveVersion
+	}
+	return version
+}
+
+// versionMessage stamps each payload in the outgoing message with its objective's version,
+// and attaches our versions if this is the first message to the recipient.
+func (e *Engine) versionMessage(message *protocols.Message) {
+	for i := range message.ObjectivePayloads {
+		p := &message.ObjectivePayloads[i]
+		p.Version = e.objectiveVersion(p.ObjectiveId, message.To)
+	}
+	if !e.announced[message.To] {
+		versions := e.versions
+		message.Versions = &versions
+		e.announced[message.To] = true
+	}
+}
@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/statechannels/go-nitro/client/engine/chainservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// watchRetryInterval is how long the engine waits before retrying registrations with chain services which have failed.
+const watchRetryInterval = time.Second
+
+// watchChannel registers the channel of an objective which handles chain events with the chain service, or unregisters it
+// once the objective no longer needs its events. Registration happens before any transaction is submitted, so that its events are not missed.
+// A ledger channel stays registered for as long as it is in the store as a consensus channel (see watchConsensusChannels).
+//
+// The chain service is only called when the channel's watch state changes. If the call fails it is logged and retried later,
+// and the channel's transactions are held until it succeeds (see holdUntilWatched). An error is only returned if held
+// transactions cannot be submitted.
+func (e *Engine) watchChannel(objective protocols.Objective, watch bool) error {
+	if _, ok := objective.(chainservice.ChainEventHandler); !ok {
+		return nil
+	}
+	channelId := objective.OwnsChannel()
+	if !watch {
+		_, err := e.store.GetConsensusChannelById(channelId)
+		watch = err == nil
+	}
+	if e.watchWanted[channelId] == watch && e.watched[channelId] == watch {
+		return nil
+	}
+	e.watchWanted[channelId] = watch
+	return e.syncWatch(channelId)
+}
+
+// watchConsensusChannels registers the ledger channels in the store with their chain services, so that a challenge of a ledger
+// is seen even while no objective is using it. Each ledger stays registered until it is removed from the store by a directdefund objective.
+func (e *Engine) watchConsensusChannels() error {
+	for _, channelId := range e.store.GetConsensusChannelIds() {
+		e.watchWanted[channelId] = true
+		err := e.syncWatch(channelId)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncWatch registers or unregisters the channel with its chain service, so that its registration matches the wanted one.
+// Once the channel is registered, any transactions held for it are submitted.
+func (e *Engine) syncWatch(channelId types.Destination) error {
+	watch := e.watchWanted[channelId]
+	if e.watched[channelId] != watch {
+		chain, err := e.chainForChannel(channelId)
+		if err == nil {
+			if watch {
+				err = chain.RegisterChannel(channelId)
+			} else {
+				err = chain.UnregisterChannel(channelId)
+			}
+		}
+		if err != nil {
+			e.logger.Printf("could not update the registration of channel %s with its chain service, will retry: %v", channelId, err)
+			if e.watchRetry == nil {
+				e.watchRetry = time.After(watchRetryInterval)
+			}
+			return nil
+		}
+		e.watched[channelId] = watch
+	}
+	if !watch {
+		delete(e.watchWanted, channelId)
+		delete(e.watched, channelId)
+		delete(e.heldTransactions, channelId)
+		return nil
+	}
+	held := e.heldTransactions[channelId]
+	delete(e.heldTransactions, channelId)
+	return e.executeSideEffects(protocols.SideEffects{TransactionsToSubmit: held})
+}
+
+// holdUntilWatched removes the transactions for channels which are not yet registered with their chain service from the side effects,
+// and holds them until the channel is registered.
+func (e *Engine) holdUntilWatched(sideEffects protocols.SideEffects) protocols.SideEffects {
+	toSubmit := make([]protocols.ChainTransaction, 0, len(sideEffects.TransactionsToSubmit))
+	for _, tx := range sideEffects.TransactionsToSubmit {
+		if e.watchWanted[tx.ChannelId()] && !e.watched[tx.ChannelId()] {
+			e.heldTransactions[tx.ChannelId()] = append(e.heldTransactions[tx.ChannelId()], tx)
+			continue
+		}
+		toSubmit = append(toSubmit, tx)
+	}
+	sideEffects.TransactionsToSubmit = toSubmit
+	return sideEffects
+}
+
+// retryWatches retries the registrations with chain services which have failed.
+func (e *Engine) retryWatches() error {
+	e.watchRetry = nil
+	for channelId, watch := range e.watchWanted {
+		if e.watched[channelId] != watch {
+			err := e.syncWatch(channelId)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -2,17 +2,23 @@
 package client_test // import "github.com/statechannels/go-nitro/client_test"
 
 import (
+	"errors"
+	"math/big"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/statechannels/go-nitro/channel/consensus_channel"
 	"github.com/statechannels/go-nitro/client"
+	"github.com/statechannels/go-nitro/client/engine"
 	"github.com/statechannels/go-nitro/client/engine/chainservice"
 	"github.com/statechannels/go-nitro/client/engine/messageservice"
 	"github.com/statechannels/go-nitro/client/engine/store"
 	"github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
 	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/dispute"
 	"github.com/statechannels/go-nitro/types"
 )
 
@@ -30,8 +36,10 @@ func directlyFundALedgerChannel(t *testing.T, alpha client.Client, beta client.C
 
 type RejectingPolicyMaker struct{}
 
-func (pm *RejectingPolicyMaker) ShouldApprove(obj protocols.Objective) bool {
-	return false
+var rejectionReason = protocols.RejectionReason{Code: protocols.CounterpartyNotAllowed, Message: "counterparty is not on the allow list"}
+
+func (pm *RejectingPolicyMaker) ShouldApprove(obj protocols.Objective) (bool, protocols.RejectionReason) {
+	return false, rejectionReason
 }
 
 func TestWhenObjectiveIsRejected(t *testing.T) {
@@ -49,16 +57,39 @@ func TestWhenObjectiveIsRejected(t *testing.T) {
 	meanMessageDelay := time.Duration(0)
 	clientA, storeA := setupClient(alice.PrivateKey, chainServiceA, broker, logDestination, meanMessageDelay)
 	var storeB store.Store
+	var clientB client.Client
 	{
 		messageservice := messageservice.NewTestMessageService(bob.Address(), broker, meanMessageDelay)
 		storeB = store.NewMemStore(bob.PrivateKey)
-		_ = client.New(messageservice, chainServiceB, storeB, logDestination, &RejectingPolicyMaker{}, nil)
+		clientB = client.New(messageservice, chainServiceB, storeB, logDestination, &RejectingPolicyMaker{}, nil)
 	}
 
 	outcome := testdata.Outcomes.Create(alice.Address(), bob.Address(), ledgerChannelDeposit, ledgerChannelDeposit)
 	response := clientA.CreateLedgerChannel(bob.Address(), 0, outcome)
 
-	waitTimeForCompletedObjectiveIds(t, &clientA, time.Second, response.Id)
+	select {
+	case failed := <-clientA.FailedObjectives():
+		if failed.Id != response.Id {
+			t.Fatalf("expected objective %s to fail, got %s", response.Id, failed.Id)
+		}
+		if failed.Reason != rejectionReason {
+			t.Fatalf("expected rejection reason %v, got %v", rejectionReason, failed.Reason)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a failed objective to be reported")
+	}
+
+	// Bob reports the objective his policy rejected as failed, with his policy's reason
+	select {
+	case failed := <-clientB.FailedObjectives():
+		if failed.Id != response.Id || failed.Reason != rejectionReason {
+			t.Fatalf("expected objective %s to fail with reason %v, got %s with %v", response.Id, rejectionReason, failed.Id, failed.Reason)
+		}
+	case <-clientB.CompletedObjectives():
+		t.Fatal("expected the rejected objective not to be reported as completed")
+	case <-time.After(time.Second):
+		t.Fatal("expected a failed objective to be reported")
+	}
 
 	obj, _ := storeA.GetObjectiveById(response.Id)
 
@@ -75,6 +106,343 @@ func TestWhenObjectiveIsRejected(t *testing.T) {
 	}
 }
 
+// selectivePolicyMaker rejects the objectives in reject, and approves every other objective.
+type selectivePolicyMaker struct {
+	reject map[protocols.ObjectiveId]bool
+}
+
+func (pm *selectivePolicyMaker) ShouldApprove(obj protocols.Objective) (bool, protocols.RejectionReason) {
+	if pm.reject[obj.Id()] {
+		return false, rejectionReason
+	}
+	return true, protocols.RejectionReason{}
+}
+
+func TestRejectionDoesNotDropTheRestOfAMessage(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_direct_fund_partial_rejection.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chain := chainservice.NewMockChain()
+	chainServiceA := chainservice.NewMockChainService(chain, alice.Address())
+	broker := messageservice.NewBroker()
+
+	rejected, rejectedId := ledgerProposalFromBrian(t, 1)
+	approved, approvedId := ledgerProposalFromBrian(t, 2)
+	policy := &selectivePolicyMaker{reject: map[protocols.ObjectiveId]bool{rejectedId: true}}
+	messageserviceA := messageservice.NewTestMessageService(alice.Address(), broker, 0)
+	_ = client.New(messageserviceA, chainServiceA, store.NewMemStore(alice.PrivateKey), logDestination, policy, nil)
+	messageserviceBr := messageservice.NewTestMessageService(brian.Address(), broker, 0)
+
+	// Brian proposes both objectives in a single message, the rejected one first
+	both := protocols.Message{To: alice.Address(), ObjectivePayloads: append(rejected.ObjectivePayloads, approved.ObjectivePayloads...)}
+	testhelpers.Ok(t, both.Sign(brian.PrivateKey))
+	messageserviceBr.Send(both)
+
+	sawRejection, sawApproval := false, false
+	for !sawRejection || !sawApproval {
+		select {
+		case msg := <-messageserviceBr.Out():
+			for _, notice := range msg.RejectedObjectives {
+				sawRejection = sawRejection || notice.ObjectiveId == rejectedId
+			}
+			for _, payload := range msg.ObjectivePayloads {
+				sawApproval = sawApproval || payload.ObjectiveId == approvedId
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected alice to reject %s and join %s, saw rejection: %t, saw approval: %t", rejectedId, approvedId, sawRejection, sawApproval)
+		}
+	}
+}
+
+func TestWhenObjectiveTimesOut(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_direct_fund_timeout.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chain := chainservice.NewMockChain()
+	chainServiceA := chainservice.NewMockChainService(chain, alice.Address())
+	broker := messageservice.NewBroker()
+
+	timeouts := engine.ObjectiveTimeouts{
+		directfund.ObjectivePrefix: {RetryAfter: 100 * time.Millisecond, FailAfter: 350 * time.Millisecond},
+	}
+	messageserviceA := messageservice.NewTestMessageService(alice.Address(), broker, 0)
+	storeA := store.NewMemStore(alice.PrivateKey)
+	clientA := client.NewWithOptions(messageserviceA, chainServiceA, storeA, logDestination, &engine.PermissivePolicy{}, nil, engine.Options{Timeouts: timeouts})
+
+	// Bob's message service is connected, but Bob never responds
+	messageserviceB := messageservice.NewTestMessageService(bob.Address(), broker, 0)
+	received := make(chan protocols.Message, 100)
+	go func() {
+		for msg := range messageserviceB.Out() {
+			received <- msg
+		}
+	}()
+
+	outcome := testdata.Outcomes.Create(alice.Address(), bob.Address(), ledgerChannelDeposit, ledgerChannelDeposit)
+	response := clientA.CreateLedgerChannel(bob.Address(), 0, outcome)
+
+	select {
+	case failed := <-clientA.FailedObjectives():
+		if failed.Id != response.Id {
+			t.Fatalf("expected objective %s to fail, got %s", response.Id, failed.Id)
+		}
+		if failed.Reason.Code != protocols.TimedOut {
+			t.Fatalf("expected objective to time out, got %v", failed.Reason)
+		}
+	case <-time.After(defaultTimeout):
+		t.Fatal("expected the objective to time out")
+	}
+
+	obj, _ := storeA.GetObjectiveById(response.Id)
+	if obj.GetStatus() != protocols.Rejected {
+		t.Fatalf("expected objective to be rejected, got status %v", obj.GetStatus())
+	}
+	if _, owned := storeA.GetObjectiveByChannelId(response.ChannelId); owned {
+		t.Fatal("expected channel to be released from ownership")
+	}
+
+	payloads := 0
+	for rejected := false; !rejected; {
+		select {
+		case msg := <-received:
+			payloads += len(msg.ObjectivePayloads)
+			rejected = len(msg.RejectedObjectives) > 0
+		case <-time.After(time.Second):
+			t.Fatal("expected bob to receive a rejection notice")
+		}
+	}
+	if payloads < 2 {
+		t.Errorf("expected the prefund state to be resent, but it was sent %d time(s)", payloads)
+	}
+}
+
+// droppingChainService is a ChainService which never submits its transactions.
+type droppingChainService struct {
+	*chainservice.MockChainService
+}
+
+func (cs droppingChainService) SendTransaction(tx protocols.ChainTransaction) error {
+	return nil
+}
+
+func TestWhenObjectiveWithFundsAtRiskTimesOut(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_direct_fund.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chain := chainservice.NewMockChain()
+	chainServiceA := chainservice.NewMockChainService(chain, alice.Address())
+	// Bob's deposit never reaches the chain, so the channel is never fully funded
+	chainServiceB := droppingChainService{chainservice.NewMockChainService(chain, bob.Address())}
+	broker := messageservice.NewBroker()
+
+	timeouts := engine.ObjectiveTimeouts{
+		directfund.ObjectivePrefix: {FailAfter: 350 * time.Millisecond},
+	}
+	messageserviceA := messageservice.NewTestMessageService(alice.Address(), broker, 0)
+	storeA := store.NewMemStore(alice.PrivateKey)
+	clientA := client.NewWithOptions(messageserviceA, chainServiceA, storeA, logDestination, &engine.PermissivePolicy{}, nil, engine.Options{Timeouts: timeouts})
+	_, _ = setupClient(bob.PrivateKey, chainServiceB, broker, logDestination, 0)
+
+	outcome := testdata.Outcomes.Create(alice.Address(), bob.Address(), ledgerChannelDeposit, ledgerChannelDeposit)
+	response := clientA.CreateLedgerChannel(bob.Address(), 60, outcome)
+
+	failed := waitTimeForFailedObjective(t, &clientA, defaultTimeout, response.Id)
+	if failed.Reason.Code != protocols.DisputeRequired {
+		t.Fatalf("expected a dispute to be required, got %v", failed.Reason)
+	}
+
+	// Alice has deposited, so she challenges the channel to recover her deposit
+	if holdings := chain.GetHoldings(response.ChannelId, types.Address{}); holdings.Cmp(big.NewInt(ledgerChannelDeposit)) != 0 {
+		t.Fatalf("expected alice's deposit of %d to be held, got %s", ledgerChannelDeposit, holdings)
+	}
+	status := chain.GetAdjudicationStatus(response.ChannelId)
+	if status.FinalizesAt == 0 {
+		t.Fatalf("expected the channel to be challenged, got status %+v", status)
+	}
+	if want := chain.Now() + 60; status.FinalizesAt > want {
+		t.Fatalf("expected the challenge to finalize by %d, got %d", want, status.FinalizesAt)
+	}
+
+	// The channel is handed to a dispute, which pays out alice's deposit once the challenge finalizes
+	obj, _ := storeA.GetObjectiveById(response.Id)
+	if obj.GetStatus() != protocols.Rejected {
+		t.Fatalf("expected the failed objective to be rejected, got status %v", obj.GetStatus())
+	}
+	owner, owned := storeA.GetObjectiveByChannelId(response.ChannelId)
+	if !owned || owner.Id() != dispute.ObjectiveIdFor(response.ChannelId) {
+		t.Fatalf("expected the channel to be owned by its dispute, got %v", owner)
+	}
+	chain.AdvanceTime(61 * time.Second)
+	waitTimeForCompletedObjectiveIds(t, &clientA, defaultTimeout, dispute.ObjectiveIdFor(response.ChannelId))
+	if holdings := chain.GetHoldings(response.ChannelId, types.Address{}); holdings.Sign() != 0 {
+		t.Fatalf("expected alice's deposit to be paid out, got holdings of %s", holdings)
+	}
+
+	// The failure is only reported once
+	select {
+	case failed := <-clientA.FailedObjectives():
+		t.Fatalf("expected no further failures, got %+v", failed)
+	default:
+	}
+}
+
+func TestCancelObjective(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_direct_fund_cancel.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chain := chainservice.NewMockChain()
+	chainServiceA := chainservice.NewMockChainService(chain, alice.Address())
+	broker := messageservice.NewBroker()
+
+	clientA, storeA := setupClient(alice.PrivateKey, chainServiceA, broker, logDestination, 0)
+
+	// Bob's message service is connected, but Bob never responds
+	messageserviceB := messageservice.NewTestMessageService(bob.Address(), broker, 0)
+	received := make(chan protocols.Message, 100)
+	go func() {
+		for msg := range messageserviceB.Out() {
+			received <- msg
+		}
+	}()
+
+	outcome := testdata.Outcomes.Create(alice.Address(), bob.Address(), ledgerChannelDeposit, ledgerChannelDeposit)
+	response := clientA.CreateLedgerChannel(bob.Address(), 0, outcome)
+
+	err := clientA.CancelObjective(response.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case failed := <-clientA.FailedObjectives():
+		if failed.Id != response.Id || failed.Reason.Code != protocols.Cancelled {
+			t.Fatalf("expected objective %s to be cancelled, got %+v", response.Id, failed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the objective to be reported as cancelled")
+	}
+
+	obj, _ := storeA.GetObjectiveById(response.Id)
+	if obj.GetStatus() != protocols.Rejected {
+		t.Fatalf("expected objective to be rejected, got status %v", obj.GetStatus())
+	}
+	if _, owned := storeA.GetObjectiveByChannelId(response.ChannelId); owned {
+		t.Fatal("expected channel to be released from ownership")
+	}
+
+	for rejected := false; !rejected; {
+		select {
+		case msg := <-received:
+			for _, notice := range msg.RejectedObjectives {
+				rejected = notice.ObjectiveId == response.Id && notice.Reason.Code == protocols.Cancelled
+			}
+		case <-time.After(time.Second):
+			t.Fatal("expected bob to receive a rejection notice")
+		}
+	}
+
+	if err := clientA.CancelObjective(response.Id); err == nil {
+		t.Fatal("expected an error when cancelling an objective that is no longer in progress")
+	}
+}
+
+func TestCancelObjectiveWithCommittedFunds(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_direct_fund_cancel.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chain := chainservice.NewMockChain()
+	chainServiceA := chainservice.NewMockChainService(chain, alice.Address())
+	// Bob's deposit never reaches the chain, so the objective stays in progress after alice deposits
+	chainServiceB := droppingChainService{chainservice.NewMockChainService(chain, bob.Address())}
+	broker := messageservice.NewBroker()
+
+	clientA, storeA := setupClient(alice.PrivateKey, chainServiceA, broker, logDestination, 0)
+	_, _ = setupClient(bob.PrivateKey, chainServiceB, broker, logDestination, 0)
+
+	outcome := testdata.Outcomes.Create(alice.Address(), bob.Address(), ledgerChannelDeposit, ledgerChannelDeposit)
+	response := clientA.CreateLedgerChannel(bob.Address(), 0, outcome)
+
+	deadline := time.After(defaultTimeout)
+	for chain.GetHoldings(response.ChannelId, types.Address{}).Sign() == 0 {
+		select {
+		case <-deadline:
+			t.Fatal("expected alice to deposit")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	err := clientA.CancelObjective(response.Id)
+	if !errors.Is(err, engine.ErrUnsafeToCancel) {
+		t.Fatalf("expected %v, got %v", engine.ErrUnsafeToCancel, err)
+	}
+	obj, _ := storeA.GetObjectiveById(response.Id)
+	if obj.GetStatus() != protocols.Approved {
+		t.Fatalf("expected objective to remain in progress, got status %v", obj.GetStatus())
+	}
+}
+
+func TestForgedRejectionNoticesAreIgnored(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_direct_fund_forged_rejection.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chain := chainservice.NewMockChain()
+	chainServiceA := chainservice.NewMockChainService(chain, alice.Address())
+	broker := messageservice.NewBroker()
+
+	clientA, _ := setupClient(alice.PrivateKey, chainServiceA, broker, logDestination, 0)
+	messageserviceB := messageservice.NewTestMessageService(bob.Address(), broker, 0)
+	messageserviceBr := messageservice.NewTestMessageService(brian.Address(), broker, 0)
+
+	outcome := testdata.Outcomes.Create(alice.Address(), bob.Address(), ledgerChannelDeposit, ledgerChannelDeposit)
+	response := clientA.CreateLedgerChannel(bob.Address(), 0, outcome)
+
+	forgedReason := protocols.RejectionReason{Code: protocols.CounterpartyNotAllowed, Message: "forged"}
+	genuineReason := protocols.RejectionReason{Code: protocols.CounterpartyNotAllowed, Message: "genuine"}
+
+	// Brian is not a participant in the objective
+	fromBrian := protocols.CreateRejectionNoticeMessage(response.Id, forgedReason, alice.Address())[0]
+	testhelpers.Ok(t, fromBrian.Sign(brian.PrivateKey))
+	messageserviceBr.Send(fromBrian)
+
+	// Brian claims to be Bob, but cannot produce Bob's signature
+	impersonatingBob := protocols.CreateRejectionNoticeMessage(response.Id, forgedReason, alice.Address())[0]
+	testhelpers.Ok(t, impersonatingBob.Sign(brian.PrivateKey))
+	impersonatingBob.From = bob.Address()
+	messageserviceBr.Send(impersonatingBob)
+
+	// Messages are delivered in order, so the forged notices are handled before Bob's genuine notice
+	fromBob := protocols.CreateRejectionNoticeMessage(response.Id, genuineReason, alice.Address())[0]
+	testhelpers.Ok(t, fromBob.Sign(bob.PrivateKey))
+	messageserviceB.Send(fromBob)
+
+	select {
+	case failed := <-clientA.FailedObjectives():
+		if failed.Reason != genuineReason {
+			t.Fatalf("expected the objective to be rejected by bob, but got %v", failed.Reason)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the objective to be rejected by bob")
+	}
+}
+
 // TestDirectFund uses the geth simulated backend
 func TestDirectFund(t *testing.T) {
 
@@ -112,10 +480,10 @@ func TestDirectFund(t *testing.T) {
 		var ok bool
 
 		// each client fetches the ConsensusChannel by reference to their counterparty
-		if store.GetChannelSecretKey() == &alice.PrivateKey {
-			con, ok = store.GetConsensusChannel(*clientB.Address)
+		if *store.GetAddress() == alice.Address() {
+			con, ok = store.GetConsensusChannel(*clientB.Address, chainA.GetChainId())
 		} else {
-			con, ok = store.GetConsensusChannel(*clientA.Address)
+			con, ok = store.GetConsensusChannel(*clientA.Address, chainB.GetChainId())
 		}
 
 		if !ok {
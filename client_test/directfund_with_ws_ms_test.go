@@ -0,0 +1,47 @@
+package client_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/statechannels/go-nitro/client"
+	"github.com/statechannels/go-nitro/client/engine"
+	"github.com/statechannels/go-nitro/client/engine/chainservice"
+	wsms "github.com/statechannels/go-nitro/client/engine/messageservice/ws-message-service"
+	"github.com/statechannels/go-nitro/client/engine/store"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// setupClientWithWsMessageService is a helper function that contructs a client whose message service is served by a new test server.
+func setupClientWithWsMessageService(pk []byte, chain *chainservice.MockChainService, logFile string) (client.Client, *wsms.WsMessageService, *httptest.Server) {
+	store := store.NewMemStore(pk)
+	messageservice := wsms.NewMessageService(store.GetChannelSigner(), nil, nil, newLogWriter(logFile))
+	server := httptest.NewServer(messageservice)
+	return client.New(messageservice, chain, store, newLogWriter(logFile), &engine.PermissivePolicy{}, nil), messageservice, server
+}
+
+func TestDirectFundWithWsMessageService(t *testing.T) {
+	logFile := "test_direct_fund_with_ws_ms.log"
+	truncateLog(logFile)
+
+	chain := chainservice.NewMockChain()
+	chainServiceA := chainservice.NewMockChainService(chain, alice.Address())
+	chainServiceB := chainservice.NewMockChainService(chain, bob.Address())
+
+	clientA, msgA, serverA := setupClientWithWsMessageService(alice.PrivateKey, chainServiceA, logFile)
+	clientB, msgB, serverB := setupClientWithWsMessageService(bob.PrivateKey, chainServiceB, logFile)
+	defer serverA.Close()
+	defer serverB.Close()
+	defer msgA.Close()
+	defer msgB.Close()
+
+	peers := map[types.Address]string{
+		alice.Address(): "ws" + strings.TrimPrefix(serverA.URL, "http"),
+		bob.Address():   "ws" + strings.TrimPrefix(serverB.URL, "http"),
+	}
+	msgA.AddPeers(peers)
+	msgB.AddPeers(peers)
+
+	directlyFundALedgerChannel(t, clientA, clientB)
+}
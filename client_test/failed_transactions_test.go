@@ -0,0 +1,68 @@
+package client_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/client/engine/chainservice"
+	"github.com/statechannels/go-nitro/client/engine/messageservice"
+	"github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/protocols"
+)
+
+// failingChainService is a ChainService whose transactions all revert.
+type failingChainService struct {
+	*chainservice.MockChainService
+	out chan chainservice.Event
+}
+
+func (cs failingChainService) SendTransaction(tx protocols.ChainTransaction) error {
+	go func() {
+		cs.out <- chainservice.NewTransactionFailedEvent(tx.ChannelId(), 0, common.Hash{}, "execution reverted")
+	}()
+	return nil
+}
+
+func (cs failingChainService) EventFeed() <-chan chainservice.Event {
+	return cs.out
+}
+
+func TestFailedTransactions(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_failed_transactions.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chain := chainservice.NewMockChain()
+	chainServiceA := failingChainService{chainservice.NewMockChainService(chain, alice.Address()), make(chan chainservice.Event)}
+	chainServiceB := chainservice.NewMockChainService(chain, bob.Address())
+	broker := messageservice.NewBroker()
+
+	clientA, _ := setupClient(alice.PrivateKey, chainServiceA, broker, logDestination, 0)
+	clientB, _ := setupClient(bob.PrivateKey, chainServiceB, broker, logDestination, 0)
+
+	// Alice deposits first, and each of her deposits fails
+	outcome := testdata.Outcomes.Create(alice.Address(), bob.Address(), ledgerChannelDeposit, ledgerChannelDeposit)
+	response := clientA.CreateLedgerChannel(bob.Address(), 0, outcome)
+
+	select {
+	case failed := <-clientA.FailedObjectives():
+		if failed.Id != response.Id || failed.Reason.Code != protocols.TransactionFailed {
+			t.Fatalf("expected objective %s to fail because its transactions failed, got %+v", response.Id, failed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected alice's objective to fail")
+	}
+
+	// Since no funds were deposited, alice abandons the objective and bob is notified
+	select {
+	case failed := <-clientB.FailedObjectives():
+		if failed.Id != response.Id || failed.Reason.Code != protocols.TransactionFailed {
+			t.Fatalf("expected bob to learn that objective %s failed, got %+v", response.Id, failed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected bob to learn that the objective failed")
+	}
+}
@@ -68,6 +68,22 @@ func waitTimeForCompletedObjectiveIds(t *testing.T, client *client.Client, timeo
 	}
 }
 
+// waitTimeForFailedObjective waits up to the given timeout for the objective to be reported as failed, and returns the failure.
+// If the timeout lapses first, the parent test will be failed.
+func waitTimeForFailedObjective(t *testing.T, client *client.Client, timeout time.Duration, id protocols.ObjectiveId) engine.FailedObjective {
+	deadline := time.After(timeout)
+	for {
+		select {
+		case failed := <-client.FailedObjectives():
+			if failed.Id == id {
+				return failed
+			}
+		case <-deadline:
+			t.Fatalf("Objective id %s failed to fail on client %s within %s", id, client.Address, timeout)
+		}
+	}
+}
+
 type BasicVoucherInfo struct {
 	Amount    *big.Int
 	ChannelId types.Destination
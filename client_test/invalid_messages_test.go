@@ -0,0 +1,66 @@
+package client_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/client/engine/chainservice"
+	"github.com/statechannels/go-nitro/client/engine/messageservice"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestInvalidMessagesAreDropped(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_invalid_messages.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chain := chainservice.NewMockChain()
+	chainServiceA := chainservice.NewMockChainService(chain, alice.Address())
+	broker := messageservice.NewBroker()
+
+	_, _ = setupClient(alice.PrivateKey, chainServiceA, broker, logDestination, 0)
+	messageserviceBr := messageservice.NewTestMessageService(brian.Address(), broker, 0)
+
+	reply := func() (protocols.Message, bool) {
+		select {
+		case msg := <-messageserviceBr.Out():
+			return msg, true
+		case <-time.After(time.Second):
+			return protocols.Message{}, false
+		}
+	}
+
+	// A payload from which no objective can be constructed is rejected
+	malformedId := protocols.ObjectiveId(directfund.ObjectivePrefix + "0x01")
+	malformed := protocols.Message{
+		To:                alice.Address(),
+		ObjectivePayloads: []protocols.ObjectivePayload{{ObjectiveId: malformedId, Type: directfund.SignedStatePayload, PayloadData: []byte("not a signed state")}},
+	}
+	testhelpers.Ok(t, malformed.Sign(brian.PrivateKey))
+	messageserviceBr.Send(malformed)
+	msg, ok := reply()
+	if !ok || len(msg.RejectedObjectives) != 1 || msg.RejectedObjectives[0].ObjectiveId != malformedId || msg.RejectedObjectives[0].Reason.Code != protocols.InvalidObjective {
+		t.Fatalf("expected alice to reject objective %s as invalid, got %+v", malformedId, msg.Summarize())
+	}
+
+	// Rejection notices for unknown objectives and vouchers for unknown channels are ignored
+	unknown := protocols.CreateRejectionNoticeMessage("unknown", protocols.RejectionReason{Code: protocols.Cancelled}, alice.Address())[0]
+	unknown.Payments = []payments.Voucher{{ChannelId: types.Destination{1}, Amount: big.NewInt(1)}}
+	testhelpers.Ok(t, unknown.Sign(brian.PrivateKey))
+	messageserviceBr.Send(unknown)
+
+	// Alice is still running, and joins a valid objective
+	valid, validId := ledgerProposalFromBrian(t, 1)
+	messageserviceBr.Send(valid)
+	msg, ok = reply()
+	if !ok || len(msg.ObjectivePayloads) != 1 || msg.ObjectivePayloads[0].ObjectiveId != validId {
+		t.Fatalf("expected alice to join objective %s, got %+v", validId, msg.Summarize())
+	}
+}
@@ -0,0 +1,117 @@
+package client_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/client"
+	"github.com/statechannels/go-nitro/client/engine"
+	"github.com/statechannels/go-nitro/client/engine/chainservice"
+	"github.com/statechannels/go-nitro/client/engine/messageservice"
+	"github.com/statechannels/go-nitro/client/engine/store"
+	"github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ledgerProposalFromBrian returns a signed message from brian to alice proposing a ledger channel with the given nonce.
+func ledgerProposalFromBrian(t *testing.T, nonce uint64) (protocols.Message, protocols.ObjectiveId) {
+	request := directfund.ObjectiveRequest{
+		CounterParty: alice.Address(),
+		Outcome:      testdata.Outcomes.Create(brian.Address(), alice.Address(), ledgerChannelDeposit, ledgerChannelDeposit),
+		Nonce:        nonce,
+		ChainId:      big.NewInt(1337),
+	}
+	noChannels := func(types.Address, *big.Int) []*channel.Channel { return nil }
+	noLedger := func(types.Address, *big.Int) (*consensus_channel.ConsensusChannel, bool) { return nil, false }
+	objective, err := directfund.NewObjective(request, true, brian.Address(), noChannels, noLedger)
+	testhelpers.Ok(t, err)
+	_, sideEffects, _, err := objective.Crank(brian.Signer())
+	testhelpers.Ok(t, err)
+
+	msg := sideEffects.MessagesToSend[0]
+	testhelpers.Ok(t, msg.Sign(brian.PrivateKey))
+	return msg, objective.Id()
+}
+
+func TestInboundLimits(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_inbound_limits.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chain := chainservice.NewMockChain()
+	chainServiceA := chainservice.NewMockChainService(chain, alice.Address())
+	broker := messageservice.NewBroker()
+
+	// The rates are low enough that no tokens are replenished during the test
+	limits := engine.InboundLimits{
+		MessagesPerSecond:      0.001,
+		MessageBurst:           6,
+		NewObjectivesPerSecond: 0.001,
+		NewObjectiveBurst:      2,
+		MaxOpenObjectives:      1,
+	}
+	messageserviceA := messageservice.NewTestMessageService(alice.Address(), broker, 0)
+	_ = client.NewWithOptions(messageserviceA, chainServiceA, store.NewMemStore(alice.PrivateKey), logDestination, &engine.PermissivePolicy{}, nil, engine.Options{Limits: &limits})
+	messageserviceBr := messageservice.NewTestMessageService(brian.Address(), broker, 0)
+
+	reply := func() (protocols.Message, bool) {
+		select {
+		case msg := <-messageserviceBr.Out():
+			return msg, true
+		case <-time.After(200 * time.Millisecond):
+			return protocols.Message{}, false
+		}
+	}
+	expectAccepted := func(id protocols.ObjectiveId) {
+		msg, ok := reply()
+		if !ok || len(msg.ObjectivePayloads) != 1 || msg.ObjectivePayloads[0].ObjectiveId != id {
+			t.Fatalf("expected alice to join objective %s, got %+v", id, msg.Summarize())
+		}
+	}
+	expectRejected := func(id protocols.ObjectiveId) {
+		msg, ok := reply()
+		if !ok || len(msg.RejectedObjectives) != 1 || msg.RejectedObjectives[0].ObjectiveId != id || msg.RejectedObjectives[0].Reason.Code != protocols.QuotaExceeded {
+			t.Fatalf("expected alice to reject objective %s as over quota, got %+v", id, msg.Summarize())
+		}
+	}
+	withdraw := func(id protocols.ObjectiveId) {
+		notice := protocols.CreateRejectionNoticeMessage(id, protocols.RejectionReason{Code: protocols.Cancelled}, alice.Address())[0]
+		testhelpers.Ok(t, notice.Sign(brian.PrivateKey))
+		messageserviceBr.Send(notice)
+	}
+
+	// Message 1: the first objective is accepted
+	first, firstId := ledgerProposalFromBrian(t, 1)
+	messageserviceBr.Send(first)
+	expectAccepted(firstId)
+
+	// Message 2: brian already has an open objective
+	second, secondId := ledgerProposalFromBrian(t, 2)
+	messageserviceBr.Send(second)
+	expectRejected(secondId)
+
+	// Messages 3 & 4: once the first objective is closed, brian may propose another
+	withdraw(firstId)
+	messageserviceBr.Send(second)
+	expectAccepted(secondId)
+
+	// Messages 5 & 6: brian has used up his quota of new objectives
+	withdraw(secondId)
+	third, thirdId := ledgerProposalFromBrian(t, 3)
+	messageserviceBr.Send(third)
+	expectRejected(thirdId)
+
+	// Message 7: brian has used up his quota of messages, so alice does not even reply
+	messageserviceBr.Send(third)
+	if msg, ok := reply(); ok {
+		t.Fatalf("expected alice to drop brian's message, got a reply %+v", msg.Summarize())
+	}
+}
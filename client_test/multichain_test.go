@@ -0,0 +1,153 @@
+package client_test
+
+import (
+	"errors"
+	"io"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/client"
+	"github.com/statechannels/go-nitro/client/engine"
+	"github.com/statechannels/go-nitro/client/engine/chainservice"
+	"github.com/statechannels/go-nitro/client/engine/messageservice"
+	"github.com/statechannels/go-nitro/client/engine/store"
+	"github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// setupMultiChainClient is like setupClient, but the client holds channels on each of the supplied chains.
+func setupMultiChainClient(pk []byte, chains []chainservice.ChainService, msgBroker messageservice.Broker, logDestination io.Writer) (client.Client, store.Store) {
+	myAddress := crypto.GetAddressFromSecretKeyBytes(pk)
+	messageservice := messageservice.NewTestMessageService(myAddress, msgBroker, 0)
+	store := store.NewMemStore(pk)
+	return client.NewMultiChain(messageservice, chains, store, logDestination, &engine.PermissivePolicy{}, nil, engine.Options{}), store
+}
+
+func TestMultiChain(t *testing.T) {
+	logFile := "test_multi_chain.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chainIdA, chainIdB := big.NewInt(1337), big.NewInt(9001)
+	chainA := chainservice.NewMockChainWithChainId(chainIdA)
+	chainB := chainservice.NewMockChainWithChainId(chainIdB)
+	chainsFor := func(address types.Address) []chainservice.ChainService {
+		return []chainservice.ChainService{chainservice.NewMockChainService(chainA, address), chainservice.NewMockChainService(chainB, address)}
+	}
+	broker := messageservice.NewBroker()
+
+	clientA, storeA := setupMultiChainClient(alice.PrivateKey, chainsFor(alice.Address()), broker, logDestination)
+	clientB, storeB := setupMultiChainClient(bob.PrivateKey, chainsFor(bob.Address()), broker, logDestination)
+	clientI, _ := setupMultiChainClient(irene.PrivateKey, chainsFor(irene.Address()), broker, logDestination)
+
+	// Alice and Bob hold a ledger channel on each chain
+	ledgers := make(map[string]types.Destination)
+	for _, chainId := range []*big.Int{chainIdA, chainIdB} {
+		outcome := testdata.Outcomes.Create(alice.Address(), bob.Address(), ledgerChannelDeposit, ledgerChannelDeposit)
+		response, err := clientA.CreateLedgerChannelOnChain(chainId, bob.Address(), 0, outcome)
+		if err != nil {
+			t.Fatal(err)
+		}
+		waitTimeForCompletedObjectiveIds(t, &clientA, defaultTimeout, response.Id)
+		waitTimeForCompletedObjectiveIds(t, &clientB, defaultTimeout, response.Id)
+		ledgers[chainId.String()] = response.ChannelId
+	}
+	if ledgers[chainIdA.String()] == ledgers[chainIdB.String()] {
+		t.Fatal("expected a different ledger channel on each chain")
+	}
+
+	// Each ledger channel is funded on its own chain, and found by its chain
+	for _, chain := range []*chainservice.MockChain{chainA, chainB} {
+		for _, store := range []store.Store{storeA, storeB} {
+			counterparty := bob.Address()
+			if *store.GetAddress() == bob.Address() {
+				counterparty = alice.Address()
+			}
+			ledger, ok := store.GetConsensusChannel(counterparty, chain.ChainId())
+			if !ok {
+				t.Fatalf("expected a consensus channel on chain %s", chain.ChainId())
+			}
+			if ledger.Id != ledgers[chain.ChainId().String()] {
+				t.Fatalf("expected consensus channel %s on chain %s, got %s", ledgers[chain.ChainId().String()], chain.ChainId(), ledger.Id)
+			}
+			if holdings := chain.GetHoldings(ledger.Id, types.Address{}); holdings.Cmp(big.NewInt(2*ledgerChannelDeposit)) != 0 {
+				t.Fatalf("expected ledger channel %s to hold %d on chain %s, got %s", ledger.Id, 2*ledgerChannelDeposit, chain.ChainId(), holdings)
+			}
+		}
+	}
+
+	// A virtual channel is funded by the ledger channels on the chain it was requested on
+	for _, pair := range [][2]client.Client{{clientA, clientI}, {clientI, clientB}} {
+		outcome := testdata.Outcomes.Create(*pair[0].Address, *pair[1].Address, ledgerChannelDeposit, ledgerChannelDeposit)
+		response, err := pair[0].CreateLedgerChannelOnChain(chainIdB, *pair[1].Address, 0, outcome)
+		if err != nil {
+			t.Fatal(err)
+		}
+		waitTimeForCompletedObjectiveIds(t, &pair[0], defaultTimeout, response.Id)
+		waitTimeForCompletedObjectiveIds(t, &pair[1], defaultTimeout, response.Id)
+	}
+	outcome := testdata.Outcomes.Create(alice.Address(), bob.Address(), 1, 0)
+	virtual, err := clientA.CreateVirtualPaymentChannelOnChain(chainIdB, []types.Address{irene.Address()}, bob.Address(), 0, outcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitTimeForCompletedObjectiveIds(t, &clientA, defaultTimeout, virtual.Id)
+	waitTimeForCompletedObjectiveIds(t, &clientB, defaultTimeout, virtual.Id)
+	waitTimeForCompletedObjectiveIds(t, &clientI, defaultTimeout, virtual.Id)
+
+	// Closing the ledger channel on one chain leaves the other chain's ledger channel alone
+	closeId := clientA.CloseLedgerChannel(ledgers[chainIdA.String()])
+	waitTimeForCompletedObjectiveIds(t, &clientA, defaultTimeout, closeId)
+	waitTimeForCompletedObjectiveIds(t, &clientB, defaultTimeout, closeId)
+	if _, ok := storeA.GetConsensusChannel(bob.Address(), chainIdA); ok {
+		t.Fatalf("expected the consensus channel on chain %s to be closed", chainIdA)
+	}
+	if _, ok := storeA.GetConsensusChannel(bob.Address(), chainIdB); !ok {
+		t.Fatalf("expected the consensus channel on chain %s to remain open", chainIdB)
+	}
+
+	// Channels cannot be created on chains the client has no chain service for
+	_, err = clientA.CreateLedgerChannelOnChain(big.NewInt(5), bob.Address(), 0, outcome)
+	if !errors.Is(err, engine.ErrUnsupportedChain) {
+		t.Fatalf("expected %v, got %v", engine.ErrUnsupportedChain, err)
+	}
+}
+
+func TestLedgerChannelOnUnsupportedChainIsRejected(t *testing.T) {
+	logFile := "test_unsupported_chain.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chainIdA, chainIdB := big.NewInt(1337), big.NewInt(9001)
+	chainA := chainservice.NewMockChainWithChainId(chainIdA)
+	chainB := chainservice.NewMockChainWithChainId(chainIdB)
+	broker := messageservice.NewBroker()
+
+	clientA, _ := setupMultiChainClient(alice.PrivateKey, []chainservice.ChainService{
+		chainservice.NewMockChainService(chainA, alice.Address()),
+		chainservice.NewMockChainService(chainB, alice.Address()),
+	}, broker, logDestination)
+	// Bob only has a chain service for the first chain
+	_, _ = setupClient(bob.PrivateKey, chainservice.NewMockChainService(chainA, bob.Address()), broker, logDestination, 0)
+
+	outcome := testdata.Outcomes.Create(alice.Address(), bob.Address(), ledgerChannelDeposit, ledgerChannelDeposit)
+	response, err := clientA.CreateLedgerChannelOnChain(chainIdB, bob.Address(), 0, outcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case failed := <-clientA.FailedObjectives():
+		if failed.Id != response.Id {
+			t.Fatalf("expected objective %s to fail, got %s", response.Id, failed.Id)
+		}
+		if failed.Reason.Code != protocols.UnsupportedChain {
+			t.Fatalf("expected rejection code %s, got %s", protocols.UnsupportedChain, failed.Reason.Code)
+		}
+	case <-time.After(defaultTimeout):
+		t.Fatal("expected a failed objective to be reported")
+	}
+}
@@ -18,7 +18,7 @@ import (
 // setupClientWithP2PMessageService is a helper function that contructs a client and returns the new client and its store.
 func setupClientWithP2PMessageService(pk []byte, port int, chain *chainservice.MockChainService, logDestination io.Writer) (client.Client, *p2pms.P2PMessageService) {
 
-	messageservice := p2pms.NewMessageService("127.0.0.1", port, pk)
+	messageservice := p2pms.NewMessageService("127.0.0.1", port, pk, logDestination)
 	storeA := store.NewMemStore(pk)
 	return client.New(messageservice, chain, storeA, logDestination, &engine.PermissivePolicy{}, nil), messageservice
 }
@@ -44,9 +44,17 @@ func TestPayments(t *testing.T) {
 		{Id: msgI.Id(), IpAddress: "127.0.0.1", Port: 3007, Address: irene.Address()},
 	}
 
-	msgA.AddPeers(peers)
-	msgB.AddPeers(peers)
-	msgI.AddPeers(peers)
+	// A peer id must be derived from the key of the address it is registered under
+	impostor := []p2pms.PeerInfo{{Id: msgI.Id(), IpAddress: "127.0.0.1", Port: 3007, Address: bob.Address()}}
+	if err := msgA.AddPeers(impostor); err == nil {
+		t.Fatal("expected AddPeers to reject a peer id which does not belong to the address")
+	}
+
+	for _, ms := range []*p2pms.P2PMessageService{msgA, msgB, msgI} {
+		if err := ms.AddPeers(peers); err != nil {
+			t.Fatal(err)
+		}
+	}
 
 	defer msgA.Close()
 	defer msgB.Close()
@@ -0,0 +1,117 @@
+package client_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/client"
+	"github.com/statechannels/go-nitro/client/engine"
+	"github.com/statechannels/go-nitro/client/engine/chainservice"
+	"github.com/statechannels/go-nitro/client/engine/messageservice"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// stoppableChainService forwards the events of a chain service until it is stopped, which takes the node's chain connection down.
+type stoppableChainService struct {
+	chainservice.ChainService
+	out  chan chainservice.Event
+	stop chan struct{}
+}
+
+func newStoppableChainService(cs chainservice.ChainService) *stoppableChainService {
+	s := &stoppableChainService{cs, make(chan chainservice.Event), make(chan struct{})}
+	go func() {
+		defer close(s.out)
+		for {
+			select {
+			case event := <-cs.EventFeed():
+				select {
+				case s.out <- event:
+				case <-s.stop:
+					return
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+	return s
+}
+
+func (s *stoppableChainService) EventFeed() <-chan chainservice.Event {
+	return s.out
+}
+
+func (s *stoppableChainService) Stop() {
+	close(s.stop)
+}
+
+func TestDepositWhileDownIsSeenAfterRestart(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_restart.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	sim, bindings, ethAccounts, err := chainservice.SetupSimulatedBackend(2)
+	testhelpers.Ok(t, err)
+	defer sim.Close()
+	chainId := big.NewInt(1337)
+
+	chainServiceA, err := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[0], logDestination)
+	testhelpers.Ok(t, err)
+	chainServiceBr, err := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[1], logDestination)
+	testhelpers.Ok(t, err)
+	stoppableA := newStoppableChainService(chainServiceA)
+	broker := messageservice.NewBroker()
+
+	_, storeA := setupClient(alice.PrivateKey, stoppableA, broker, logDestination, 0)
+	messageserviceBr := messageservice.NewTestMessageService(brian.Address(), broker, 0)
+
+	// Alice joins a ledger channel proposed by brian, who deposits first
+	proposal, id := ledgerProposalFromBrian(t, 1)
+	messageserviceBr.Send(proposal)
+	select {
+	case <-messageserviceBr.Out():
+	case <-time.After(defaultTimeout):
+		t.Fatal("expected alice to join the ledger channel")
+	}
+	objective, err := storeA.GetObjectiveById(id)
+	testhelpers.Ok(t, err)
+	channelId := objective.(*directfund.Objective).C.Id
+
+	deposit := func(amount int64) {
+		testhelpers.Ok(t, chainServiceBr.SendTransaction(protocols.NewDepositTransaction(channelId, types.Funds{common.Address{}: big.NewInt(amount)})))
+	}
+	waitFor := func(condition func() bool, description string) {
+		deadline := time.Now().Add(defaultTimeout)
+		for !condition() {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s", description)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	// Alice sees part of brian's deposit, which is not enough for her to deposit
+	deposit(1)
+	waitFor(func() bool { return storeA.GetLastBlockNumSeen(chainId) != 0 }, "alice to see the first deposit")
+
+	// Alice goes down, and brian deposits the rest while she is down
+	stoppableA.Stop()
+	deposit(ledgerChannelDeposit - 1)
+
+	// Alice restarts with her store, sees the rest of brian's deposit and makes her own
+	restartedA, err := chainservice.NewSimulatedBackendChainServiceWithStore(sim, bindings, ethAccounts[0], logDestination, storeA)
+	testhelpers.Ok(t, err)
+	_ = client.New(messageservice.NewTestMessageService(alice.Address(), broker, 0), restartedA, storeA, logDestination, &engine.PermissivePolicy{}, nil)
+	waitFor(func() bool {
+		holdings, err := chainServiceBr.GetHoldings(channelId, common.Address{})
+		return err == nil && holdings.Cmp(big.NewInt(2*ledgerChannelDeposit)) == 0
+	}, "alice to deposit")
+}
@@ -0,0 +1,148 @@
+package client_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/client/engine"
+	"github.com/statechannels/go-nitro/client/engine/chainservice"
+	"github.com/statechannels/go-nitro/client/engine/messageservice"
+	"github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+)
+
+func TestVersionNegotiation(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_version_negotiation.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chain := chainservice.NewMockChain()
+	chainServiceA := chainservice.NewMockChainService(chain, alice.Address())
+	broker := messageservice.NewBroker()
+
+	clientA, storeA := setupClient(alice.PrivateKey, chainServiceA, broker, logDestination, 0)
+	messageserviceB := messageservice.NewTestMessageService(bob.Address(), broker, 0)
+	messageserviceBr := messageservice.NewTestMessageService(brian.Address(), broker, 0)
+
+	receive := func(ms messageservice.TestMessageService) protocols.Message {
+		select {
+		case msg := <-ms.Out():
+			return msg
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for a message")
+			return protocols.Message{}
+		}
+	}
+
+	// Alice announces her versions in her first message to bob, and stamps the objective's version on its payload
+	outcome := testdata.Outcomes.Create(alice.Address(), bob.Address(), ledgerChannelDeposit, ledgerChannelDeposit)
+	toBobResponse := clientA.CreateLedgerChannel(bob.Address(), 0, outcome)
+	toBob := receive(messageserviceB)
+	if toBob.Versions == nil || toBob.Versions.Protocol != protocols.ProtocolVersion {
+		t.Fatalf("expected alice to announce her versions, got %+v", toBob.Versions)
+	}
+	if toBob.ObjectivePayloads[0].Version != 1 {
+		t.Fatalf("expected the payload to be stamped with version 1, got %d", toBob.ObjectivePayloads[0].Version)
+	}
+
+	// The negotiated version is stored with the objective, so that it survives a restart
+	stored, err := storeA.GetObjectiveById(toBobResponse.Id)
+	testhelpers.Ok(t, err)
+	if stored.(protocols.VersionedObjective).GetVersion() != 1 {
+		t.Fatalf("expected the objective to be stored with version 1, got %+v", stored)
+	}
+
+	// Brian only supports a version of directfund which alice does not
+	brianVersions := protocols.Versions{Protocol: protocols.ProtocolVersion, Objectives: map[string][]uint{directfund.ObjectivePrefix: {2}}}
+	fromBrian := protocols.Message{
+		To:                alice.Address(),
+		ObjectivePayloads: []protocols.ObjectivePayload{{ObjectiveId: directfund.ObjectivePrefix + "0x01", Type: directfund.SignedStatePayload, Version: 2}},
+		Versions:          &brianVersions,
+	}
+	testhelpers.Ok(t, fromBrian.Sign(brian.PrivateKey))
+	messageserviceBr.Send(fromBrian)
+
+	toBrian := receive(messageserviceBr)
+	if len(toBrian.RejectedObjectives) != 1 || toBrian.RejectedObjectives[0].Reason.Code != protocols.IncompatibleVersion {
+		t.Fatalf("expected alice to reject brian's objective as incompatible, got %+v", toBrian.Summarize())
+	}
+
+	// Since alice now knows brian's versions, she refuses to start a directfund objective with him
+	outcome = testdata.Outcomes.Create(alice.Address(), brian.Address(), ledgerChannelDeposit, ledgerChannelDeposit)
+	response := clientA.CreateLedgerChannel(brian.Address(), 0, outcome)
+	select {
+	case failed := <-clientA.FailedObjectives():
+		if failed.Id != response.Id || failed.Reason.Code != protocols.IncompatibleVersion {
+			t.Fatalf("expected objective %s to fail with an incompatible version, got %+v", response.Id, failed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the objective to fail")
+	}
+}
+
+func TestVersionsAreReannounced(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_version_reannouncement.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chain := chainservice.NewMockChain()
+	chainServiceA := chainservice.NewMockChainService(chain, alice.Address())
+	broker := messageservice.NewBroker()
+
+	_, _ = setupClient(alice.PrivateKey, chainServiceA, broker, logDestination, 0)
+	messageserviceBr := messageservice.NewTestMessageService(brian.Address(), broker, 0)
+
+	// propose sends alice a ledger channel proposal from brian, and returns whether alice's reply announces her versions
+	propose := func(nonce uint64, announce bool) (protocols.ObjectiveId, bool) {
+		msg, id := ledgerProposalFromBrian(t, nonce)
+		if announce {
+			versions := engine.SupportedVersions
+			msg.Versions = &versions
+			testhelpers.Ok(t, msg.Sign(brian.PrivateKey))
+		}
+		messageserviceBr.Send(msg)
+		select {
+		case reply := <-messageserviceBr.Out():
+			if len(reply.ObjectivePayloads) != 1 || reply.ObjectivePayloads[0].ObjectiveId != id {
+				t.Fatalf("expected alice to join objective %s, got %+v", id, reply.Summarize())
+			}
+			return id, reply.Versions != nil
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for alice to join objective %s", id)
+			return id, false
+		}
+	}
+
+	first, announced := propose(1, true)
+	if !announced {
+		t.Fatal("expected alice to announce her versions in her first message to brian")
+	}
+	second, announced := propose(2, false)
+	if announced {
+		t.Fatal("expected alice not to announce her versions again")
+	}
+
+	// Brian restarts, and announces his versions again
+	third, announced := propose(3, true)
+	if !announced {
+		t.Fatal("expected alice to announce her versions to brian after he announced his again")
+	}
+
+	// Once brian has no open objectives with alice, she forgets his versions, and announces hers in her next message to him
+	withdrawal := protocols.Message{To: alice.Address()}
+	for _, id := range []protocols.ObjectiveId{first, second, third} {
+		withdrawal.RejectedObjectives = append(withdrawal.RejectedObjectives, protocols.RejectionNotice{ObjectiveId: id, Reason: protocols.RejectionReason{Code: protocols.Cancelled}})
+	}
+	testhelpers.Ok(t, withdrawal.Sign(brian.PrivateKey))
+	messageserviceBr.Send(withdrawal)
+	_, announced = propose(4, false)
+	if !announced {
+		t.Fatal("expected alice to announce her versions to brian after forgetting his")
+	}
+}
@@ -183,9 +183,13 @@ func TestWhenVirtualDefundObjectiveIsRejected(t *testing.T) {
 		outcome,
 	)
 
-	waitTimeForCompletedObjectiveIds(t, &clientA, time.Second, response.Id)
-	waitTimeForCompletedObjectiveIds(t, &clientB, time.Second, response.Id)
-	waitTimeForCompletedObjectiveIds(t, &clientI, time.Second, response.Id)
+	// Bob rejects the objective, and he, Alice and Irene report it as failed
+	for _, c := range []*client.Client{&clientB, &clientA, &clientI} {
+		failed := waitTimeForFailedObjective(t, c, time.Second, response.Id)
+		if failed.Reason != rejectionReason {
+			t.Fatalf("expected rejection reason %v, got %v", rejectionReason, failed.Reason)
+		}
+	}
 
 	obj, _ := storeA.GetObjectiveById(response.Id)
 
@@ -1,13 +1,18 @@
 package client_test
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/statechannels/go-nitro/client"
+	"github.com/statechannels/go-nitro/client/engine"
 	"github.com/statechannels/go-nitro/client/engine/chainservice"
 	"github.com/statechannels/go-nitro/client/engine/messageservice"
+	"github.com/statechannels/go-nitro/client/engine/store"
 	td "github.com/statechannels/go-nitro/internal/testdata"
 	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directfund"
 	"github.com/statechannels/go-nitro/types"
 )
 
@@ -110,3 +115,108 @@ func clientsToAddresses(clients []client.Client) []types.Address {
 	}
 	return ret
 }
+
+// stallingPolicyMaker approves ledger channels, but does not decide on any other objective until released, and then rejects it.
+type stallingPolicyMaker struct {
+	release chan struct{}
+}
+
+func (pm *stallingPolicyMaker) ShouldApprove(obj protocols.Objective) (bool, protocols.RejectionReason) {
+	if directfund.IsDirectFundObjective(obj.Id()) {
+		return true, protocols.RejectionReason{}
+	}
+	<-pm.release
+	return false, rejectionReason
+}
+
+func TestCancelVirtualFundObjective(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_virtual_fund_cancel.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chain := chainservice.NewMockChain()
+	chainServiceA := chainservice.NewMockChainService(chain, alice.Address())
+	chainServiceB := chainservice.NewMockChainService(chain, bob.Address())
+	chainServiceI := chainservice.NewMockChainService(chain, irene.Address())
+	broker := messageservice.NewBroker()
+
+	clientA, storeA := setupClient(alice.PrivateKey, chainServiceA, broker, logDestination, 0)
+	clientI, storeI := setupClient(irene.PrivateKey, chainServiceI, broker, logDestination, 0)
+	// Bob does not respond to the virtual channel until the end of the test
+	policy := &stallingPolicyMaker{release: make(chan struct{})}
+	defer close(policy.release)
+	messageserviceB := messageservice.NewTestMessageService(bob.Address(), broker, 0)
+	clientB := client.New(messageserviceB, chainServiceB, store.NewMemStore(bob.PrivateKey), logDestination, policy, nil)
+
+	directlyFundALedgerChannel(t, clientA, clientI)
+	directlyFundALedgerChannel(t, clientI, clientB)
+	ledger, _ := storeA.GetConsensusChannel(irene.Address(), chain.ChainId())
+
+	outcome := td.Outcomes.Create(alice.Address(), bob.Address(), 1, 1)
+	response := clientA.CreateVirtualPaymentChannel([]types.Address{irene.Address()}, bob.Address(), 0, outcome)
+
+	// Only alice, who proposed the virtual channel, may cancel its funding
+	for start := time.Now(); time.Since(start) < defaultTimeout; time.Sleep(10 * time.Millisecond) {
+		if _, err := storeI.GetObjectiveById(response.Id); err == nil {
+			break
+		}
+	}
+	if err := clientI.CancelObjective(response.Id); !errors.Is(err, engine.ErrNotProposer) {
+		t.Fatalf("expected irene to be refused cancellation, got %v", err)
+	}
+
+	// Without bob's prefund signature, no guarantee has been proposed in the ledger channels, so the objective can be cancelled
+	err := clientA.CancelObjective(response.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed := waitTimeForFailedObjective(t, &clientA, defaultTimeout, response.Id)
+	if failed.Reason.Code != protocols.Cancelled {
+		t.Fatalf("expected objective %s to be cancelled, got %+v", response.Id, failed)
+	}
+	failed = waitTimeForFailedObjective(t, &clientI, defaultTimeout, response.Id)
+	if failed.Reason.Code != protocols.Cancelled {
+		t.Fatalf("expected irene to learn that objective %s was cancelled, got %+v", response.Id, failed)
+	}
+
+	obj, _ := storeA.GetObjectiveById(response.Id)
+	if obj.GetStatus() != protocols.Rejected {
+		t.Fatalf("expected objective to be rejected, got status %v", obj.GetStatus())
+	}
+	after, _ := storeA.GetConsensusChannel(irene.Address(), chain.ChainId())
+	if after.IncludesTarget(response.ChannelId) || after.ConsensusTurnNum() != ledger.ConsensusTurnNum() {
+		t.Fatal("expected the ledger channel to be unchanged")
+	}
+}
+
+func TestVirtualFundWithInsufficientLedgerCapacity(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_virtual_fund_insufficient_capacity.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chain := chainservice.NewMockChain()
+	chainServiceA := chainservice.NewMockChainService(chain, alice.Address())
+	chainServiceB := chainservice.NewMockChainService(chain, bob.Address())
+	chainServiceI := chainservice.NewMockChainService(chain, irene.Address())
+	broker := messageservice.NewBroker()
+
+	clientA, _ := setupClient(alice.PrivateKey, chainServiceA, broker, logDestination, 0)
+	clientI, _ := setupClient(irene.PrivateKey, chainServiceI, broker, logDestination, 0)
+	clientB, _ := setupClient(bob.PrivateKey, chainServiceB, broker, logDestination, 0)
+
+	directlyFundALedgerChannel(t, clientA, clientI)
+	directlyFundALedgerChannel(t, clientI, clientB)
+
+	// Alice's balance in her ledger channel with irene cannot cover her side of the virtual channel
+	outcome := td.Outcomes.Create(alice.Address(), bob.Address(), ledgerChannelDeposit+1, 1)
+	response := clientA.CreateVirtualPaymentChannel([]types.Address{irene.Address()}, bob.Address(), 0, outcome)
+
+	failed := waitTimeForFailedObjective(t, &clientA, defaultTimeout, response.Id)
+	if failed.Reason.Code != protocols.InsufficientLedgerCapacity {
+		t.Fatalf("expected objective %s to fail for lack of ledger capacity, got %+v", response.Id, failed)
+	}
+}
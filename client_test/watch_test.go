@@ -0,0 +1,128 @@
+package client_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/client"
+	"github.com/statechannels/go-nitro/client/engine"
+	"github.com/statechannels/go-nitro/client/engine/chainservice"
+	"github.com/statechannels/go-nitro/client/engine/messageservice"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// flakyChainService is a chain service whose first registration and first unregistration fail, and which counts the calls to each.
+type flakyChainService struct {
+	*chainservice.MockChainService
+	mu            sync.Mutex
+	registered    int
+	unregistered  int
+	registrations int
+}
+
+func (f *flakyChainService) RegisterChannel(channelId types.Destination) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.registered++
+	if f.registered == 1 {
+		return errors.New("connection refused")
+	}
+	f.registrations++
+	return f.MockChainService.RegisterChannel(channelId)
+}
+
+func (f *flakyChainService) UnregisterChannel(channelId types.Destination) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.unregistered++
+	if f.unregistered == 1 {
+		return errors.New("connection refused")
+	}
+	f.registrations--
+	return f.MockChainService.UnregisterChannel(channelId)
+}
+
+func (f *flakyChainService) calls() (registered, unregistered, registrations int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.registered, f.unregistered, f.registrations
+}
+
+func TestFailedRegistrationsAreRetried(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_failed_registrations.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chain := chainservice.NewMockChain()
+	chainServiceA := &flakyChainService{MockChainService: chainservice.NewMockChainService(chain, alice.Address())}
+	chainServiceB := chainservice.NewMockChainService(chain, bob.Address())
+	broker := messageservice.NewBroker()
+
+	clientA, _ := setupClient(alice.PrivateKey, chainServiceA, broker, logDestination, 0)
+	clientB, _ := setupClient(bob.PrivateKey, chainServiceB, broker, logDestination, 0)
+
+	// Alice's deposit is held until her channel is registered, so she does not miss its event
+	ledgerId := directlyFundALedgerChannel(t, clientA, clientB)
+
+	// The ledger channel stays registered once funded, so that a challenge of it is seen
+	registered, unregistered, registrations := chainServiceA.calls()
+	if registered != 2 || unregistered != 0 || registrations != 1 {
+		t.Fatalf("expected the funded ledger channel to stay registered, got %d calls to register, %d calls to unregister and %d registrations", registered, unregistered, registrations)
+	}
+
+	// It is unregistered once defunded
+	directlyDefundALedgerChannel(t, clientA, clientB, ledgerId)
+
+	deadline := time.Now().Add(defaultTimeout)
+	for {
+		registered, unregistered, registrations := chainServiceA.calls()
+		if unregistered == 2 {
+			// The chain service is only called when the channel's registration changes, not on every crank
+			if registered != 2 || registrations != 0 {
+				t.Fatalf("expected 2 calls to register the channel and none left registered, got %d calls and %d registrations", registered, registrations)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the failed unregistration to be retried, got %d calls to unregister", unregistered)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestLedgerChannelsAreWatchedAfterRestart(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_ledgers_watched_after_restart.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chain := chainservice.NewMockChain()
+	chainServiceA := chainservice.NewMockChainService(chain, alice.Address())
+	chainServiceB := chainservice.NewMockChainService(chain, bob.Address())
+	broker := messageservice.NewBroker()
+
+	clientA, storeA := setupClient(alice.PrivateKey, chainServiceA, broker, logDestination, 0)
+	clientB, _ := setupClient(bob.PrivateKey, chainServiceB, broker, logDestination, 0)
+	directlyFundALedgerChannel(t, clientA, clientB)
+
+	// Alice restarts with her store, and registers her ledger channel again (retrying the failed first attempt)
+	restartedA := &flakyChainService{MockChainService: chainservice.NewMockChainService(chain, alice.Address())}
+	_ = client.New(messageservice.NewTestMessageService(alice.Address(), broker, 0), restartedA, storeA, logDestination, &engine.PermissivePolicy{}, nil)
+
+	deadline := time.Now().Add(defaultTimeout)
+	for {
+		registered, _, registrations := restartedA.calls()
+		if registrations == 1 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the ledger channel to be registered after the restart, got %d calls to register", registered)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,137 @@
+// Command deploy-contracts deploys the Nitro contracts through a Create2Deployer, and prints their addresses and writes them to a JSON file.
+// A node reads the addresses back with deploy.ReadConfig, and passes them to chainservice.NewEthChainService.
+//
+// Usage:
+//
+//	deploy-contracts -rpc ws://127.0.0.1:8545 (-keystore <keystore file> | -mnemonic-file <file>) [-password-file <file>] [-deployer <address>] [-salt <hex>] [-config nitro-config.json]
+//
+// The deploying account's key is read from a go-ethereum keystore file, unlocked with the passphrase in -password-file, or derived from
+// the BIP-39 mnemonic in -mnemonic-file along -derivation-path, with the optional BIP-39 passphrase in -password-file. Secrets are read
+// from files rather than flags, so that they do not end up in the shell history or the process list.
+//
+// Without -deployer, the Create2Deployer is deployed as the deploying account's first transaction on the chain (or found, if it
+// is already deployed), which puts it at the same address on every chain. An account which is only used to deploy the Nitro
+// contracts therefore deploys them at the same addresses on every chain; the deployment fails if the account has already sent
+// other transactions on the chain. Pass -deployer to use a Create2Deployer which was deployed some other way.
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/statechannels/go-nitro/client/engine/chainservice/deploy"
+	nc "github.com/statechannels/go-nitro/crypto"
+)
+
+func main() {
+	rpcUrl := flag.String("rpc", "ws://127.0.0.1:8545", "the RPC endpoint of the chain to deploy to")
+	keystorePath := flag.String("keystore", "", "the go-ethereum keystore file holding the key of the deploying account")
+	mnemonicPath := flag.String("mnemonic-file", "", "a file holding the BIP-39 mnemonic from which the key of the deploying account is derived")
+	derivationPath := flag.String("derivation-path", nc.DefaultDerivationPath, "the BIP-32 derivation path of the deploying account's key within the mnemonic")
+	passwordPath := flag.String("password-file", "", "a file holding the passphrase of the keystore file, or the optional BIP-39 passphrase of the mnemonic")
+	deployerAddress := flag.String("deployer", "", "the address of an existing Create2Deployer")
+	salt := flag.String("salt", "", "the hex encoded salt for the deployments")
+	configPath := flag.String("config", "nitro-config.json", "the JSON file to write the contract addresses to")
+	flag.Parse()
+
+	ctx := context.Background()
+	key, err := loadKey(*keystorePath, *mnemonicPath, *derivationPath, *passwordPath)
+	if err != nil {
+		log.Fatalf("could not load the key of the deploying account: %v", err)
+	}
+	client, err := ethclient.DialContext(ctx, *rpcUrl)
+	if err != nil {
+		log.Fatal(err)
+	}
+	chainId, err := client.ChainID(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	txOpts, err := bind.NewKeyedTransactorWithChainID(key, chainId)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var deployer common.Address
+	if *deployerAddress == "" {
+		deployer, err = deploy.DeployCreate2Deployer(ctx, client, txOpts)
+		if err != nil {
+			log.Fatalf("could not deploy Create2Deployer: %v", err)
+		}
+		fmt.Printf("Using Create2Deployer at %s\n", deployer)
+	} else {
+		if !common.IsHexAddress(*deployerAddress) {
+			log.Fatalf("invalid deployer address %s", *deployerAddress)
+		}
+		deployer = common.HexToAddress(*deployerAddress)
+	}
+
+	addresses, err := deploy.Deploy(ctx, client, txOpts, deployer, common.HexToHash(*salt))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("NitroAdjudicator:  %s\nConsensusApp:      %s\nVirtualPaymentApp: %s\n", addresses.NitroAdjudicator, addresses.ConsensusApp, addresses.VirtualPaymentApp)
+
+	err = deploy.WriteConfig(*configPath, addresses)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Wrote contract addresses to %s\n", *configPath)
+}
+
+// loadKey returns the key in the keystore file, or the key derived from the mnemonic in the mnemonic file, whichever is supplied.
+func loadKey(keystorePath, mnemonicPath, derivationPath, passwordPath string) (*ecdsa.PrivateKey, error) {
+	if (keystorePath == "") == (mnemonicPath == "") {
+		return nil, errors.New("exactly one of -keystore and -mnemonic-file must be supplied")
+	}
+	password := ""
+	if passwordPath != "" {
+		p, err := readSecret(passwordPath)
+		if err != nil {
+			return nil, err
+		}
+		password = p
+	}
+
+	if keystorePath != "" {
+		keyJSON, err := os.ReadFile(keystorePath)
+		if err != nil {
+			return nil, err
+		}
+		key, err := keystore.DecryptKey(keyJSON, password)
+		if err != nil {
+			return nil, err
+		}
+		return key.PrivateKey, nil
+	}
+
+	mnemonic, err := readSecret(mnemonicPath)
+	if err != nil {
+		return nil, err
+	}
+	secretKey, err := nc.DeriveMnemonicKey(mnemonic, password, derivationPath)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.ToECDSA(secretKey)
+}
+
+// readSecret returns the contents of the file, without the trailing newline most editors add.
+func readSecret(path string) (string, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(contents), "\r\n"), nil
+}
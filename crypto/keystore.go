@@ -0,0 +1,28 @@
+package crypto
+
+import (
+	"os"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// NewKeystoreSigner returns a Signer for the key in a go-ethereum keystore file, which is unlocked with the supplied passphrase.
+//
+// The decrypted key is held only by the returned Signer; it is never written back to disk.
+func NewKeystoreSigner(path string, passphrase string) (*InMemorySigner, error) {
+	keyJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeystoreJSONSigner(keyJSON, passphrase)
+}
+
+// NewKeystoreJSONSigner returns a Signer for the key in the supplied keystore JSON, which is unlocked with the supplied passphrase.
+func NewKeystoreJSONSigner(keyJSON []byte, passphrase string) (*InMemorySigner, error) {
+	key, err := keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return NewInMemorySigner(crypto.FromECDSA(key.PrivateKey))
+}
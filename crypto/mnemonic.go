@@ -0,0 +1,94 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// DefaultDerivationPath is the BIP-44 derivation path of the first Ethereum account, as used by most wallets.
+const DefaultDerivationPath = "m/44'/60'/0'/0/0"
+
+// ErrInvalidDerivedKey is returned in the astronomically unlikely event that a BIP-32 derivation step produces an invalid key.
+var ErrInvalidDerivedKey = errors.New("derived key is invalid")
+
+// NewMnemonicSigner returns a Signer for the key derived from a BIP-39 mnemonic along the supplied BIP-32 derivation path (e.g. DefaultDerivationPath).
+// The passphrase is the optional BIP-39 passphrase, which is combined with the mnemonic to produce the seed.
+func NewMnemonicSigner(mnemonic string, passphrase string, derivationPath string) (*InMemorySigner, error) {
+	key, err := DeriveMnemonicKey(mnemonic, passphrase, derivationPath)
+	if err != nil {
+		return nil, err
+	}
+	return NewInMemorySigner(key)
+}
+
+// DeriveMnemonicKey returns the secret key derived from a BIP-39 mnemonic along the supplied BIP-32 derivation path, for callers
+// which need the key itself rather than a Signer, such as to sign Ethereum transactions (see NewMnemonicSigner).
+func DeriveMnemonicKey(mnemonic string, passphrase string, derivationPath string) ([]byte, error) {
+	path, err := accounts.ParseDerivationPath(derivationPath)
+	if err != nil {
+		return nil, err
+	}
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return deriveKey(seed, path)
+}
+
+// deriveKey derives the BIP-32 private key at the supplied path from the seed.
+func deriveKey(seed []byte, path accounts.DerivationPath) ([]byte, error) {
+	key, chainCode, err := splitHMAC([]byte("Bitcoin seed"), seed)
+	if err != nil {
+		return nil, err
+	}
+
+	n := crypto.S256().Params().N
+	for _, index := range path {
+		var data []byte
+		if index >= 0x80000000 {
+			// hardened child: derived from the parent private key
+			data = append([]byte{0}, math.PaddedBigBytes(key, 32)...)
+		} else {
+			// normal child: derived from the parent public key
+			secretKey, err := crypto.ToECDSA(math.PaddedBigBytes(key, 32))
+			if err != nil {
+				return nil, err
+			}
+			data = crypto.CompressPubkey(&secretKey.PublicKey)
+		}
+		data = binary.BigEndian.AppendUint32(data, index)
+
+		var tweak *big.Int
+		tweak, chainCode, err = splitHMAC(chainCode, data)
+		if err != nil {
+			return nil, err
+		}
+		key = tweak.Add(tweak, key)
+		key.Mod(key, n)
+		if key.Sign() == 0 {
+			return nil, ErrInvalidDerivedKey
+		}
+	}
+	return math.PaddedBigBytes(key, 32), nil
+}
+
+// splitHMAC computes HMAC-SHA512 of the data, returning the left half as a scalar and the right half as a chain code.
+func splitHMAC(key []byte, data []byte) (*big.Int, []byte, error) {
+	mac := hmac.New(sha512.New, key)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	scalar := new(big.Int).SetBytes(sum[:32])
+	if scalar.Sign() == 0 || scalar.Cmp(crypto.S256().Params().N) >= 0 {
+		return nil, nil, ErrInvalidDerivedKey
+	}
+	return scalar, sum[32:], nil
+}
@@ -0,0 +1,144 @@
+// Package remotesigner provides a crypto.Signer whose key is held by another process, reached over a socket.
+//
+// The signing process runs a Server around its own Signer, and go-nitro dials it with Dial. Requests and responses are JSON-RPC 1.0 messages.
+package remotesigner // import "github.com/statechannels/go-nitro/crypto/remotesigner"
+
+import (
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+
+	"github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// serviceName is the name under which the signing service is registered with the RPC server.
+const serviceName = "Signer"
+
+// SignMessageArgs are the arguments to the SignMessage RPC.
+type SignMessageArgs struct {
+	Message []byte
+}
+
+// SignHashArgs are the arguments to the RPCs which sign a hash.
+type SignHashArgs struct {
+	Hash types.Bytes32
+}
+
+// Service exposes a crypto.Signer over RPC.
+type Service struct {
+	signer crypto.Signer
+}
+
+// Address returns the address of the signer.
+func (s *Service) Address(_ struct{}, reply *types.Address) error {
+	*reply = s.signer.Address()
+	return nil
+}
+
+// SignStateHash signs the hash of a channel state.
+func (s *Service) SignStateHash(args SignHashArgs, reply *crypto.Signature) error {
+	sig, err := s.signer.SignStateHash(args.Hash)
+	*reply = sig
+	return err
+}
+
+// SignVoucher signs the hash of a payment voucher.
+func (s *Service) SignVoucher(args SignHashArgs, reply *crypto.Signature) error {
+	sig, err := s.signer.SignVoucher(args.Hash)
+	*reply = sig
+	return err
+}
+
+// SignChallenge signs the hash of a challenge message.
+func (s *Service) SignChallenge(args SignHashArgs, reply *crypto.Signature) error {
+	sig, err := s.signer.SignChallenge(args.Hash)
+	*reply = sig
+	return err
+}
+
+// SignMessage signs a serialized message to a peer.
+func (s *Service) SignMessage(args SignMessageArgs, reply *crypto.Signature) error {
+	sig, err := s.signer.SignMessage(args.Message)
+	*reply = sig
+	return err
+}
+
+// Server serves signing requests on behalf of a crypto.Signer. It stands in for a hardware wallet or key management service.
+type Server struct {
+	rpcServer *rpc.Server
+}
+
+// NewServer returns a Server which signs with the supplied signer.
+func NewServer(signer crypto.Signer) (*Server, error) {
+	rpcServer := rpc.NewServer()
+	err := rpcServer.RegisterName(serviceName, &Service{signer: signer})
+	if err != nil {
+		return nil, err
+	}
+	return &Server{rpcServer: rpcServer}, nil
+}
+
+// Serve accepts connections on the listener and serves requests on each of them, until the listener is closed.
+func (s *Server) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go s.rpcServer.ServeCodec(jsonrpc.NewServerCodec(conn))
+	}
+}
+
+// Signer is a crypto.Signer which forwards requests to a Server.
+type Signer struct {
+	client  *rpc.Client
+	address types.Address
+}
+
+// Dial connects to the Server at the given address, and fetches the address of its signer.
+func Dial(network, address string) (*Signer, error) {
+	client, err := jsonrpc.Dial(network, address)
+	if err != nil {
+		return nil, err
+	}
+	s := &Signer{client: client}
+	err = client.Call(serviceName+".Address", struct{}{}, &s.address)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close closes the connection to the Server.
+func (s *Signer) Close() error {
+	return s.client.Close()
+}
+
+func (s *Signer) Address() types.Address {
+	return s.address
+}
+
+func (s *Signer) SignStateHash(hash types.Bytes32) (crypto.Signature, error) {
+	return s.call("SignStateHash", SignHashArgs{Hash: hash})
+}
+
+func (s *Signer) SignVoucher(hash types.Bytes32) (crypto.Signature, error) {
+	return s.call("SignVoucher", SignHashArgs{Hash: hash})
+}
+
+func (s *Signer) SignChallenge(hash types.Bytes32) (crypto.Signature, error) {
+	return s.call("SignChallenge", SignHashArgs{Hash: hash})
+}
+
+func (s *Signer) SignMessage(message []byte) (crypto.Signature, error) {
+	return s.call("SignMessage", SignMessageArgs{Message: message})
+}
+
+// call invokes the named signing method on the Server.
+func (s *Signer) call(method string, args interface{}) (crypto.Signature, error) {
+	var sig crypto.Signature
+	err := s.client.Call(serviceName+"."+method, args, &sig)
+	return sig, err
+}
@@ -0,0 +1,66 @@
+package remotesigner
+
+import (
+	"net"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestRemoteSigner(t *testing.T) {
+	local, err := crypto.NewInMemorySigner(testactors.Alice.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server, err := NewServer(local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	socket := filepath.Join(t.TempDir(), "signer.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() { _ = server.Serve(l) }()
+
+	remote, err := Dial("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer remote.Close()
+
+	if remote.Address() != local.Address() {
+		t.Fatalf("expected address %s, got %s", local.Address(), remote.Address())
+	}
+
+	hash := types.Bytes32{1, 2, 3}
+	message := []byte("sign this")
+	type signFn func(crypto.Signer) (crypto.Signature, error)
+	cases := map[string]signFn{
+		"SignStateHash": func(s crypto.Signer) (crypto.Signature, error) { return s.SignStateHash(hash) },
+		"SignVoucher":   func(s crypto.Signer) (crypto.Signature, error) { return s.SignVoucher(hash) },
+		"SignChallenge": func(s crypto.Signer) (crypto.Signature, error) { return s.SignChallenge(hash) },
+		"SignMessage":   func(s crypto.Signer) (crypto.Signature, error) { return s.SignMessage(message) },
+	}
+	for name, sign := range cases {
+		t.Run(name, func(t *testing.T) {
+			want, err := sign(local)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := sign(remote)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("expected signature %+v, got %+v", want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package crypto
+
+import (
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// Signer produces signatures on behalf of a single Ethereum address.
+//
+// Every signature is made over an Ethereum signed message (see SignEthereumMessage). The kinds of data signed by go-nitro
+// have their own methods, so that a Signer which keeps its key outside of the go-nitro process can decide what it is willing to sign.
+type Signer interface {
+	// Address returns the address whose key makes the signatures.
+	Address() types.Address
+	// SignStateHash signs the hash of a channel state.
+	SignStateHash(hash types.Bytes32) (Signature, error)
+	// SignVoucher signs the hash of a payment voucher.
+	SignVoucher(hash types.Bytes32) (Signature, error)
+	// SignChallenge signs the hash of a challenge message, which authorizes a challenge on chain.
+	SignChallenge(hash types.Bytes32) (Signature, error)
+	// SignMessage signs a serialized message to a peer.
+	SignMessage(message []byte) (Signature, error)
+}
+
+// InMemorySigner is a Signer which holds its secret key in process memory.
+type InMemorySigner struct {
+	secretKey []byte
+	address   types.Address
+}
+
+// NewInMemorySigner returns a Signer for the supplied secret key.
+func NewInMemorySigner(secretKey []byte) (*InMemorySigner, error) {
+	ecdsaKey, err := crypto.ToECDSA(secretKey)
+	if err != nil {
+		return nil, err
+	}
+	return &InMemorySigner{
+		secretKey: append([]byte{}, secretKey...),
+		address:   crypto.PubkeyToAddress(ecdsaKey.PublicKey),
+	}, nil
+}
+
+func (s *InMemorySigner) Address() types.Address {
+	return s.address
+}
+
+func (s *InMemorySigner) SignStateHash(hash types.Bytes32) (Signature, error) {
+	return SignEthereumMessage(hash[:], s.secretKey)
+}
+
+func (s *InMemorySigner) SignVoucher(hash types.Bytes32) (Signature, error) {
+	return SignEthereumMessage(hash[:], s.secretKey)
+}
+
+func (s *InMemorySigner) SignChallenge(hash types.Bytes32) (Signature, error) {
+	return SignEthereumMessage(hash[:], s.secretKey)
+}
+
+func (s *InMemorySigner) SignMessage(message []byte) (Signature, error) {
+	return SignEthereumMessage(message, s.secretKey)
+}
@@ -0,0 +1,67 @@
+package crypto
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestNewKeystoreSigner(t *testing.T) {
+	secretKey, address := GeneratePrivateKeyAndAddress()
+	ecdsaKey, err := crypto.ToECDSA(secretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := &keystore.Key{Address: address, PrivateKey: ecdsaKey}
+	keyJSON, err := keystore.EncryptKey(key, "passphrase", keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "key.json")
+	if err := os.WriteFile(path, keyJSON, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := NewKeystoreSigner(path, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer.Address() != address {
+		t.Fatalf("expected address %s, got %s", address, signer.Address())
+	}
+
+	_, err = NewKeystoreSigner(path, "wrong passphrase")
+	if err != keystore.ErrDecrypt {
+		t.Fatalf("expected %v, got %v", keystore.ErrDecrypt, err)
+	}
+}
+
+func TestNewMnemonicSigner(t *testing.T) {
+	// The standard test mnemonic, with the addresses reported by common wallets
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	testCases := []struct {
+		path    string
+		address common.Address
+	}{
+		{DefaultDerivationPath, common.HexToAddress("0x9858EfFD232B4033E47d90003D41EC34EcaEda94")},
+		{"m/44'/60'/0'/0/1", common.HexToAddress("0x6Fac4D18c912343BF86fa7049364Dd4E424Ab9C0")},
+	}
+	for _, tc := range testCases {
+		signer, err := NewMnemonicSigner(mnemonic, "", tc.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if signer.Address() != tc.address {
+			t.Fatalf("%s: expected address %s, got %s", tc.path, tc.address, signer.Address())
+		}
+	}
+
+	_, err := NewMnemonicSigner("abandon abandon abandon", "", DefaultDerivationPath)
+	if err == nil {
+		t.Fatal("expected an invalid mnemonic to be rejected")
+	}
+}
@@ -21,6 +21,15 @@ func (a Actor) Address() types.Address {
 	return crypto.GetAddressFromSecretKeyBytes(a.PrivateKey)
 }
 
+// Signer returns a signer for the actor's private key.
+func (a Actor) Signer() crypto.Signer {
+	signer, err := crypto.NewInMemorySigner(a.PrivateKey)
+	if err != nil {
+		panic(err)
+	}
+	return signer
+}
+
 // Alice has the address 0xAAA6628Ec44A8a742987EF3A114dDFE2D4F7aDCE
 var Alice Actor = Actor{
 	common.Hex2Bytes(`2d999770f7b5d49b694080f987b82bbc9fc9ac2b4dcc10b0f8aba7d700f69c6d`),
@@ -23,8 +23,11 @@ var Channels channelCollection = channelCollection{
 	MockConsensusChannel: mockConsensusChannel,
 }
 
-func mockConsensusChannel(counterparty types.Address) (ledger *consensus_channel.ConsensusChannel, ok bool) {
+func mockConsensusChannel(counterparty types.Address, chainId *big.Int) (ledger *consensus_channel.ConsensusChannel, ok bool) {
 	ts := testState.Clone()
+	if ts.ChainId.Cmp(chainId) != 0 {
+		return &consensus_channel.ConsensusChannel{}, false
+	}
 	ts.TurnNum = 0
 	ss := state.NewSignedState(ts)
 	id := protocols.ObjectiveId(directfund.ObjectivePrefix + testState.ChannelId().String())
@@ -80,8 +83,12 @@ func (l LedgerNetwork) GetLedgerLookup(seeker types.Address) virtualfund.GetTwoP
 		}
 	}
 
-	return func(counterparty types.Address) (ledger *consensus_channel.ConsensusChannel, ok bool) {
+	return func(counterparty types.Address, chainId *big.Int) (ledger *consensus_channel.ConsensusChannel, ok bool) {
 		for _, ledger := range myLedgers {
+			if ledger.LeaderView.FixedPart().ChainId.Cmp(chainId) != 0 {
+				continue
+			}
+
 			if ledger.FollowerView.Follower() == seeker &&
 				ledger.FollowerView.Leader() == counterparty {
 				return &ledger.FollowerView, true
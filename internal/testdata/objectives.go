@@ -65,6 +65,7 @@ func genericVFO() virtualfund.Objective {
 	ts.Participants[2] = testactors.Bob.Address()
 
 	request := virtualfund.ObjectiveRequest{
+		ChainId:           ts.ChainId,
 		Intermediaries:    []types.Address{ts.Participants[1]},
 		CounterParty:      ts.Participants[2],
 		ChallengeDuration: ts.ChallengeDuration,
@@ -0,0 +1,242 @@
+// Package wire contains primitives for go-nitro's compact binary encoding.
+//
+// Values are written in order with no field tags. Variable length values are prefixed with their length as a uvarint.
+// Nil slices and nil big.Ints are distinguished from empty ones, so that decoded values re-encode (in any format) exactly as the originals did.
+package wire
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ErrTruncated is returned when the data ends before a value has been fully read.
+var ErrTruncated = errors.New("wire: unexpected end of data")
+
+// Writer accumulates binary encoded values.
+type Writer struct {
+	buf []byte
+}
+
+// Data returns the encoded values.
+func (w *Writer) Data() []byte {
+	return w.buf
+}
+
+// Uvarint writes an unsigned integer.
+func (w *Writer) Uvarint(x uint64) {
+	w.buf = binary.AppendUvarint(w.buf, x)
+}
+
+// Byte writes a single byte.
+func (w *Writer) Byte(b byte) {
+	w.buf = append(w.buf, b)
+}
+
+// Bool writes a boolean as a single byte.
+func (w *Writer) Bool(b bool) {
+	if b {
+		w.Byte(1)
+	} else {
+		w.Byte(0)
+	}
+}
+
+// Len writes the length of a slice, distinguishing nil slices from empty ones.
+func (w *Writer) Len(n int, isNil bool) {
+	if isNil {
+		w.Uvarint(0)
+		return
+	}
+	w.Uvarint(uint64(n) + 1)
+}
+
+// Bytes writes a byte slice, distinguishing nil from empty.
+func (w *Writer) Bytes(b []byte) {
+	w.Len(len(b), b == nil)
+	w.buf = append(w.buf, b...)
+}
+
+// String writes a string.
+func (w *Writer) String(s string) {
+	w.Uvarint(uint64(len(s)))
+	w.buf = append(w.buf, s...)
+}
+
+// BigInt writes a big.Int, distinguishing nil from zero.
+func (w *Writer) BigInt(x *big.Int) {
+	switch {
+	case x == nil:
+		w.Byte(0)
+	case x.Sign() < 0:
+		w.Byte(2)
+		w.Bytes(x.Bytes())
+	default:
+		w.Byte(1)
+		w.Bytes(x.Bytes())
+	}
+}
+
+// Address writes an address as 20 bytes.
+func (w *Writer) Address(a types.Address) {
+	w.buf = append(w.buf, a[:]...)
+}
+
+// Destination writes a destination as 32 bytes.
+func (w *Writer) Destination(d types.Destination) {
+	w.buf = append(w.buf, d[:]...)
+}
+
+// Signature writes a signature.
+func (w *Writer) Signature(s crypto.Signature) {
+	w.Bytes(s.R)
+	w.Bytes(s.S)
+	w.Byte(s.V)
+}
+
+// Reader decodes values written by a Writer.
+//
+// Errors are sticky: once a read fails, subsequent reads return zero values and Err returns the first error.
+type Reader struct {
+	data []byte
+	err  error
+}
+
+// NewReader returns a Reader for the given data.
+func NewReader(data []byte) *Reader {
+	return &Reader{data: data}
+}
+
+// Err returns the first error encountered while reading, if any.
+func (r *Reader) Err() error {
+	return r.err
+}
+
+// Done returns the first error encountered while reading, or an error if there is unread data.
+func (r *Reader) Done() error {
+	if r.err == nil && len(r.data) > 0 {
+		return fmt.Errorf("wire: %d unexpected trailing bytes", len(r.data))
+	}
+	return r.err
+}
+
+func (r *Reader) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+	r.data = nil
+}
+
+func (r *Reader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.data) {
+		r.fail(ErrTruncated)
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+// Uvarint reads an unsigned integer.
+func (r *Reader) Uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	x, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		r.fail(ErrTruncated)
+		return 0
+	}
+	r.data = r.data[n:]
+	return x
+}
+
+// Byte reads a single byte.
+func (r *Reader) Byte() byte {
+	b := r.next(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+// Bool reads a boolean.
+func (r *Reader) Bool() bool {
+	return r.Byte() == 1
+}
+
+// Len reads the length of a slice. isNil reports whether the slice was nil when written.
+func (r *Reader) Len() (n int, isNil bool) {
+	l := r.Uvarint()
+	if l == 0 {
+		return 0, true
+	}
+	// Each element occupies at least one byte, which bounds the allocations a malicious length can cause.
+	if l-1 > uint64(len(r.data)) {
+		r.fail(ErrTruncated)
+		return 0, true
+	}
+	return int(l - 1), false
+}
+
+// Bytes reads a byte slice.
+func (r *Reader) Bytes() []byte {
+	n, isNil := r.Len()
+	if isNil {
+		return nil
+	}
+	b := r.next(n)
+	if b == nil {
+		return nil
+	}
+	return append(make([]byte, 0, n), b...)
+}
+
+// String reads a string.
+func (r *Reader) String() string {
+	l := r.Uvarint()
+	if l > uint64(len(r.data)) {
+		r.fail(ErrTruncated)
+		return ""
+	}
+	return string(r.next(int(l)))
+}
+
+// BigInt reads a big.Int.
+func (r *Reader) BigInt() *big.Int {
+	switch r.Byte() {
+	case 0:
+		return nil
+	case 1:
+		return new(big.Int).SetBytes(r.Bytes())
+	case 2:
+		return new(big.Int).Neg(new(big.Int).SetBytes(r.Bytes()))
+	default:
+		r.fail(errors.New("wire: invalid big.Int"))
+		return nil
+	}
+}
+
+// Address reads a 20 byte address.
+func (r *Reader) Address() (a types.Address) {
+	copy(a[:], r.next(len(a)))
+	return a
+}
+
+// Destination reads a 32 byte destination.
+func (r *Reader) Destination() (d types.Destination) {
+	copy(d[:], r.next(len(d)))
+	return d
+}
+
+// Signature reads a signature.
+func (r *Reader) Signature() crypto.Signature {
+	return crypto.Signature{R: r.Bytes(), S: r.Bytes(), V: r.Byte()}
+}
@@ -51,13 +51,13 @@ func TestPaymentManager(t *testing.T) {
 	// Happy path: Payment manager can register channels and make payments
 	paymentMgr := NewVoucherManager(testactors.Alice.Address())
 
-	_, err := paymentMgr.Pay(channelId, payment, testactors.Alice.PrivateKey)
+	_, err := paymentMgr.Pay(channelId, payment, testactors.Alice.Signer())
 	Assert(t, err != nil, "channel must be registered to make payments")
 
 	Ok(t, paymentMgr.Register(channelId, testactors.Alice.Address(), testactors.Bob.Address(), deposit))
 	Equals(t, startingBalance, getBalance(paymentMgr))
 
-	firstVoucher, err := paymentMgr.Pay(channelId, payment, testactors.Alice.PrivateKey)
+	firstVoucher, err := paymentMgr.Pay(channelId, payment, testactors.Alice.Signer())
 	Ok(t, err)
 	Equals(t, testVoucher(channelId, payment, testactors.Alice), firstVoucher)
 	Equals(t, onePaymentMade, getBalance(paymentMgr))
@@ -86,7 +86,7 @@ func TestPaymentManager(t *testing.T) {
 	Equals(t, onePaymentMade, getBalance(receiptMgr))
 
 	// paying twice returns a larger voucher
-	secondVoucher, err := paymentMgr.Pay(channelId, payment, testactors.Alice.PrivateKey)
+	secondVoucher, err := paymentMgr.Pay(channelId, payment, testactors.Alice.Signer())
 	Ok(t, err)
 	Equals(t, testVoucher(channelId, doublePayment, testactors.Alice), secondVoucher)
 	Equals(t, twoPaymentsMade, getBalance(paymentMgr))
@@ -115,7 +115,7 @@ func TestPaymentManager(t *testing.T) {
 	// Only the payer can sign vouchers
 	err = receiptMgr.Register(anotherChannelId, testactors.Bob.Address(), testactors.Alice.Address(), deposit)
 	Ok(t, err)
-	_, err = paymentMgr.Pay(anotherChannelId, triplePayment, testactors.Bob.PrivateKey)
+	_, err = paymentMgr.Pay(anotherChannelId, triplePayment, testactors.Bob.Signer())
 	Assert(t, err != nil, "only payer can sign vouchers")
 
 	// Receiving a voucher for an unknown channel fails
@@ -6,6 +6,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/statechannels/go-nitro/client/engine/store/safesync"
+	"github.com/statechannels/go-nitro/crypto"
 	"github.com/statechannels/go-nitro/types"
 )
 
@@ -50,12 +51,11 @@ func (vm *VoucherManager) Remove(channelId types.Destination) {
 	vm.channels.Delete(channelId.String())
 }
 
-// Pay will deduct amount from balance and add it to paid, returning a signed voucher for the
-// total amount paid.
-func (vm *VoucherManager) Pay(channelId types.Destination, amount *big.Int, pk []byte) (Voucher, error) {
+// Pay will deduct amount from balance and add it to paid, returning a voucher for the
+// total amount paid, signed by signer.
+func (vm *VoucherManager) Pay(channelId types.Destination, amount *big.Int, signer crypto.Signer) (Voucher, error) {
 	pStatus, ok := vm.channels.Load(channelId.String())
 
-	voucher := Voucher{Amount: &big.Int{}}
 	if !ok {
 		return Voucher{}, fmt.Errorf("channel not found")
 	}
@@ -68,17 +68,16 @@ func (vm *VoucherManager) Pay(channelId types.Destination, amount *big.Int, pk [
 		return Voucher{}, fmt.Errorf("can only sign vouchers if we're the payer")
 	}
 
+	// The voucher is signed before the balance is updated, so that the balance is unchanged if signing fails
+	voucher := Voucher{ChannelId: channelId, Amount: new(big.Int).Add(pStatus.currentBalance.Paid, amount)}
+	if err := voucher.SignWith(signer); err != nil {
+		return Voucher{}, err
+	}
+
 	pStatus.currentBalance.Remaining.Sub(pStatus.currentBalance.Remaining, amount)
 	pStatus.currentBalance.Paid.Add(pStatus.currentBalance.Paid, amount)
 	pStatus.largestVoucher = voucher
 
-	voucher.Amount.Set(pStatus.currentBalance.Paid)
-	voucher.ChannelId = channelId
-
-	if err := voucher.Sign(pk); err != nil {
-		return voucher, err
-	}
-
 	return voucher, nil
 }
 
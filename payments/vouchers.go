@@ -9,6 +9,7 @@ import (
 	nitroAbi "github.com/statechannels/go-nitro/abi"
 	"github.com/statechannels/go-nitro/channel/state"
 	nitroCrypto "github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/internal/wire"
 	"github.com/statechannels/go-nitro/types"
 )
 
@@ -62,6 +63,20 @@ func (v *Voucher) Sign(pk []byte) error {
 	return nil
 }
 
+// SignWith signs the voucher using the supplied signer.
+func (v *Voucher) SignWith(signer nitroCrypto.Signer) error {
+	hash, err := v.Hash()
+	if err != nil {
+		return err
+	}
+	sig, err := signer.SignVoucher(hash)
+	if err != nil {
+		return err
+	}
+	v.Signature = sig
+	return nil
+}
+
 func (v *Voucher) RecoverSigner() (types.Address, error) {
 	h, error := v.Hash()
 	if error != nil {
@@ -74,3 +89,21 @@ func (v *Voucher) RecoverSigner() (types.Address, error) {
 func (v *Voucher) Equal(other *Voucher) bool {
 	return v.ChannelId == other.ChannelId && v.Amount.Cmp(other.Amount) == 0 && v.Signature.Equal(other.Signature)
 }
+
+// MarshalBinary encodes the voucher in go-nitro's compact binary format
+func (v Voucher) MarshalBinary() ([]byte, error) {
+	w := wire.Writer{}
+	w.Destination(v.ChannelId)
+	w.BigInt(v.Amount)
+	w.Signature(v.Signature)
+	return w.Data(), nil
+}
+
+// UnmarshalBinary populates the voucher with the binary-encoded data
+func (v *Voucher) UnmarshalBinary(data []byte) error {
+	r := wire.NewReader(data)
+	v.ChannelId = r.Destination()
+	v.Amount = r.BigInt()
+	v.Signature = r.Signature()
+	return r.Done()
+}
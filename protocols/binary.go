@@ -0,0 +1,225 @@
+package protocols
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/internal/wire"
+	"github.com/statechannels/go-nitro/payments"
+)
+
+// BinaryFormatVersion is the version of the binary encoding produced by Message.MarshalBinary.
+// It is the first byte of every binary-encoded message, and must be incremented whenever the encoding changes.
+const BinaryFormatVersion byte = 2
+
+// payloadEncoding identifies how an ObjectivePayload's PayloadData is binary-encoded.
+type payloadEncoding byte
+
+const (
+	rawPayload         payloadEncoding = iota // the serialized JSON, as is
+	signedStatePayload                        // a state.SignedState in its binary encoding
+)
+
+// MarshalBinary encodes the message in go-nitro's compact binary format.
+//
+// Decoding the result with UnmarshalBinary gives a message which serializes to exactly the same JSON as the original,
+// so the message's signature remains valid.
+func (m Message) MarshalBinary() ([]byte, error) {
+	w := wire.Writer{}
+	w.Byte(BinaryFormatVersion)
+	w.Address(m.To)
+	w.Address(m.From)
+
+	w.Len(len(m.ObjectivePayloads), m.ObjectivePayloads == nil)
+	for _, p := range m.ObjectivePayloads {
+		w.String(string(p.ObjectiveId))
+		w.String(string(p.Type))
+		encoding, data, err := compactPayloadData(p.PayloadData)
+		if err != nil {
+			return nil, err
+		}
+		w.Byte(byte(encoding))
+		w.Bytes(data)
+		w.Uvarint(uint64(p.Version))
+	}
+
+	w.Len(len(m.LedgerProposals), m.LedgerProposals == nil)
+	for _, sp := range m.LedgerProposals {
+		data, err := sp.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		w.Bytes(data)
+	}
+
+	w.Len(len(m.Payments), m.Payments == nil)
+	for _, v := range m.Payments {
+		data, err := v.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		w.Bytes(data)
+	}
+
+	w.Len(len(m.RejectedObjectives), m.RejectedObjectives == nil)
+	for _, n := range m.RejectedObjectives {
+		w.String(string(n.ObjectiveId))
+		w.String(string(n.Reason.Code))
+		w.String(n.Reason.Message)
+	}
+
+	w.Bool(m.Versions != nil)
+	if m.Versions != nil {
+		w.Uvarint(uint64(m.Versions.Protocol))
+		w.Len(len(m.Versions.Objectives), m.Versions.Objectives == nil)
+		objectiveTypes := make([]string, 0, len(m.Versions.Objectives))
+		for objectiveType := range m.Versions.Objectives {
+			objectiveTypes = append(objectiveTypes, objectiveType)
+		}
+		sort.Strings(objectiveTypes)
+		for _, objectiveType := range objectiveTypes {
+			versions := m.Versions.Objectives[objectiveType]
+			w.String(objectiveType)
+			w.Len(len(versions), versions == nil)
+			for _, v := range versions {
+				w.Uvarint(uint64(v))
+			}
+		}
+	}
+
+	w.Signature(m.Signature)
+	return w.Data(), nil
+}
+
+// UnmarshalBinary decodes a message encoded by MarshalBinary.
+func (m *Message) UnmarshalBinary(data []byte) error {
+	r := wire.NewReader(data)
+	if version := r.Byte(); version != BinaryFormatVersion {
+		return fmt.Errorf("unsupported binary message version %d", version)
+	}
+	*m = Message{}
+	m.To = r.Address()
+	m.From = r.Address()
+
+	n, isNil := r.Len()
+	if !isNil {
+		m.ObjectivePayloads = make([]ObjectivePayload, n)
+	}
+	for i := range m.ObjectivePayloads {
+		p := &m.ObjectivePayloads[i]
+		p.ObjectiveId = ObjectiveId(r.String())
+		p.Type = PayloadType(r.String())
+		encoding := payloadEncoding(r.Byte())
+		data := r.Bytes()
+		if r.Err() != nil {
+			return r.Err()
+		}
+		payloadData, err := expandPayloadData(encoding, data)
+		if err != nil {
+			return err
+		}
+		p.PayloadData = payloadData
+		p.Version = uint(r.Uvarint())
+	}
+
+	n, isNil = r.Len()
+	if !isNil {
+		m.LedgerProposals = make([]consensus_channel.SignedProposal, n)
+	}
+	for i := range m.LedgerProposals {
+		data := r.Bytes()
+		if r.Err() != nil {
+			return r.Err()
+		}
+		if err := m.LedgerProposals[i].UnmarshalBinary(data); err != nil {
+			return err
+		}
+	}
+
+	n, isNil = r.Len()
+	if !isNil {
+		m.Payments = make([]payments.Voucher, n)
+	}
+	for i := range m.Payments {
+		data := r.Bytes()
+		if r.Err() != nil {
+			return r.Err()
+		}
+		if err := m.Payments[i].UnmarshalBinary(data); err != nil {
+			return err
+		}
+	}
+
+	n, isNil = r.Len()
+	if !isNil {
+		m.RejectedObjectives = make([]RejectionNotice, n)
+	}
+	for i := range m.RejectedObjectives {
+		notice := &m.RejectedObjectives[i]
+		notice.ObjectiveId = ObjectiveId(r.String())
+		notice.Reason.Code = RejectionCode(r.String())
+		notice.Reason.Message = r.String()
+	}
+
+	if r.Bool() {
+		m.Versions = &Versions{Protocol: uint(r.Uvarint())}
+		n, isNil = r.Len()
+		if !isNil {
+			m.Versions.Objectives = make(map[string][]uint, n)
+		}
+		for i := 0; i < n; i++ {
+			objectiveType := r.String()
+			count, isNil := r.Len()
+			var versions []uint
+			if !isNil {
+				versions = make([]uint, count)
+			}
+			for j := range versions {
+				versions[j] = uint(r.Uvarint())
+			}
+			m.Versions.Objectives[objectiveType] = versions
+		}
+	}
+
+	m.Signature = r.Signature()
+	return r.Done()
+}
+
+// compactPayloadData returns the binary encoding of the payload data if it is a serialized state.SignedState,
+// or the payload data unchanged otherwise.
+//
+// The binary encoding is only used if the SignedState serializes back to exactly the same JSON,
+// since the message signature covers the JSON.
+func compactPayloadData(payloadData []byte) (payloadEncoding, []byte, error) {
+	ss := state.SignedState{}
+	if json.Unmarshal(payloadData, &ss) != nil {
+		return rawPayload, payloadData, nil
+	}
+	roundTripped, err := json.Marshal(ss)
+	if err != nil || !bytes.Equal(roundTripped, payloadData) {
+		return rawPayload, payloadData, nil
+	}
+	data, err := ss.MarshalBinary()
+	return signedStatePayload, data, err
+}
+
+// expandPayloadData reverses compactPayloadData.
+func expandPayloadData(encoding payloadEncoding, data []byte) ([]byte, error) {
+	switch encoding {
+	case rawPayload:
+		return data, nil
+	case signedStatePayload:
+		ss := state.SignedState{}
+		err := ss.UnmarshalBinary(data)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(ss)
+	default:
+		return nil, fmt.Errorf("unknown payload encoding %d", encoding)
+	}
+}
@@ -12,14 +12,16 @@ import (
 	"github.com/statechannels/go-nitro/channel/consensus_channel"
 	"github.com/statechannels/go-nitro/channel/state"
 	"github.com/statechannels/go-nitro/client/engine/chainservice"
+	"github.com/statechannels/go-nitro/crypto"
 	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/types"
 )
 
 const (
-	WaitingForFinalization protocols.WaitingFor = "WaitingForFinalization"
-	WaitingForWithdraw     protocols.WaitingFor = "WaitingForWithdraw"
-	WaitingForNothing      protocols.WaitingFor = "WaitingForNothing" // Finished
+	WaitingForFinalization     protocols.WaitingFor = "WaitingForFinalization"
+	WaitingForWithdraw         protocols.WaitingFor = "WaitingForWithdraw"
+	WaitingForChallengeCleared protocols.WaitingFor = "WaitingForChallengeCleared"
+	WaitingForNothing          protocols.WaitingFor = "WaitingForNothing" // Finished
 )
 const (
 	SignedStatePayload protocols.PayloadType = "SignedStatePayload"
@@ -36,9 +38,17 @@ var (
 // Objective is a cache of data computed by reading from the store. It stores (potentially) infinite data
 type Objective struct {
 	Status               protocols.ObjectiveStatus
+	Version              uint // the version of the objective's protocol negotiated by its participants, or zero if none has been recorded
 	C                    *channel.Channel
 	finalTurnNum         uint64
-	transactionSubmitted bool // whether a transition for the objective has been submitted or not
+	latestBlockNumber    uint64                 // the latest block number we've seen
+	unknownHoldings      map[types.Address]bool // assets whose holdings could not be read after a reorg
+	transactionSubmitted bool                   // whether a transition for the objective has been submitted or not
+	failedTransactions   uint                   // the number of our withdrawal transactions which have failed
+	transactionFailure   string                 // the reason our most recent withdrawal transaction failed
+	challenged           bool                   // whether the channel has been challenged by another participant, without the challenge being cleared
+	challengedWith       uint64                 // the turn number of the state the channel was challenged with
+	checkpointSubmitted  bool                   // whether our checkpoint transaction clearing the challenge has been submitted
 }
 
 // isInConsensusOrFinalState returns true if the channel has a final state or latest state that is supported
@@ -168,12 +178,12 @@ func (o *Objective) Approve() protocols.Objective {
 	return &updated
 }
 
-func (o *Objective) Reject() (protocols.Objective, protocols.SideEffects) {
+func (o *Objective) Reject(reason protocols.RejectionReason) (protocols.Objective, protocols.SideEffects) {
 	updated := o.clone()
 	updated.Status = protocols.Rejected
 	peer := o.C.Participants[1-o.C.MyIndex]
 
-	sideEffects := protocols.SideEffects{MessagesToSend: protocols.CreateRejectionNoticeMessage(o.Id(), peer)}
+	sideEffects := protocols.SideEffects{MessagesToSend: protocols.CreateRejectionNoticeMessage(o.Id(), reason, peer)}
 	return &updated, sideEffects
 }
 
@@ -182,11 +192,28 @@ func (ddo Objective) OwnsChannel() types.Destination {
 	return ddo.C.Id
 }
 
+// Participants returns the participants in the channel being defunded.
+func (ddo Objective) Participants() []types.Address {
+	return ddo.C.Participants
+}
+
 // GetStatus returns the status of the objective.
 func (ddo Objective) GetStatus() protocols.ObjectiveStatus {
 	return ddo.Status
 }
 
+// GetVersion returns the version of the objective's protocol negotiated by its participants, or zero if none has been recorded.
+func (ddo Objective) GetVersion() uint {
+	return ddo.Version
+}
+
+// WithVersion returns a copy of the objective which records the negotiated version of its protocol.
+func (ddo Objective) WithVersion(version uint) protocols.Objective {
+	updated := ddo.clone()
+	updated.Version = version
+	return &updated
+}
+
 func (o *Objective) Related() []protocols.Storable {
 	return []protocols.Storable{o.C}
 }
@@ -221,15 +248,56 @@ func (o *Objective) Update(p protocols.ObjectivePayload) (protocols.Objective, e
 
 // UpdateWithChainEvent updates the objective with observed on-chain data.
 //
-// Only Allocation Updated events are currently handled.
+// Allocation Updated events update the channel's holdings. When our withdrawal transaction fails,
+// the objective withdraws again when next cranked, unless too many transactions have failed.
+// When a reorg retracts an Allocation Updated event, the holdings are reset to those on the new chain.
+// When another participant challenges the channel, the objective clears the challenge with a checkpoint before doing anything else.
 func (o *Objective) UpdateWithChainEvent(event chainservice.Event) (protocols.Objective, error) {
 	updated := o.clone()
 	switch e := event.(type) {
 	case chainservice.AllocationUpdatedEvent:
-		// todo: check block number
-		updated.C.OnChainFunding[e.AssetAddress] = e.AssetAmount
+		// A withdrawal updates the allocation of every asset in the same block, so only events from earlier blocks are stale
+		if e.BlockNum >= updated.latestBlockNumber {
+			updated.C.OnChainFunding[e.AssetAddress] = e.AssetAmount
+			updated.latestBlockNumber = e.BlockNum
+			delete(updated.unknownHoldings, e.AssetAddress)
+		}
 	case chainservice.ConcludedEvent:
 		break
+	case chainservice.RetractedEvent:
+		if _, ok := e.Retracted.(chainservice.ChallengeRegisteredEvent); ok {
+			updated.challenged = false
+			updated.checkpointSubmitted = false
+		}
+		if _, ok := e.Retracted.(chainservice.AllocationUpdatedEvent); ok {
+			if e.NowHeld == nil {
+				// The holdings on the new chain could not be read, so we cannot tell whether the channel is still funded
+				updated.markHoldingsUnknown(e.AssetAddress)
+			} else {
+				updated.C.OnChainFunding[e.AssetAddress] = e.NowHeld
+				delete(updated.unknownHoldings, e.AssetAddress)
+			}
+			if updated.latestBlockNumber >= e.BlockNum && e.BlockNum > 0 {
+				updated.latestBlockNumber = e.BlockNum - 1
+			}
+		}
+	case chainservice.TransactionConfirmedEvent:
+		break
+	case chainservice.ChallengeRegisteredEvent:
+		updated.challenged = true
+		updated.challengedWith = e.TurnNumRecord
+		updated.checkpointSubmitted = false
+	case chainservice.ChallengeClearedEvent:
+		updated.challenged = false
+		updated.checkpointSubmitted = false
+	case chainservice.TransactionFailedEvent:
+		if updated.checkpointSubmitted {
+			updated.checkpointSubmitted = false
+		} else {
+			updated.transactionSubmitted = false
+		}
+		updated.failedTransactions++
+		updated.transactionFailure = e.Reason
 	default:
 		return &updated, fmt.Errorf("objective %+v cannot handle event %+v", updated, event)
 	}
@@ -238,7 +306,7 @@ func (o *Objective) UpdateWithChainEvent(event chainservice.Event) (protocols.Ob
 }
 
 // Crank inspects the extended state and declares a list of Effects to be executed
-func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.SideEffects, protocols.WaitingFor, error) {
+func (o *Objective) Crank(signer crypto.Signer) (protocols.Objective, protocols.SideEffects, protocols.WaitingFor, error) {
 	updated := o.clone()
 
 	sideEffects := protocols.SideEffects{}
@@ -247,6 +315,10 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 		return &updated, sideEffects, WaitingForNothing, protocols.ErrNotApproved
 	}
 
+	if updated.challenged {
+		return updated.clearChallenge(sideEffects)
+	}
+
 	latestSignedState, err := updated.C.LatestSignedState()
 	if err != nil {
 		return &updated, sideEffects, WaitingForNothing, errors.New("the channel must contain at least one signed state to crank the defund objective")
@@ -259,7 +331,7 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 			stateToSign.TurnNum += 1
 			stateToSign.IsFinal = true
 		}
-		ss, err := updated.C.SignAndAddState(stateToSign, secretKey)
+		ss, err := updated.C.SignAndAddState(stateToSign, signer)
 		if err != nil {
 			return &updated, protocols.SideEffects{}, WaitingForFinalization, fmt.Errorf("could not sign final state %w", err)
 		}
@@ -276,9 +348,13 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 	}
 
 	// Withdrawal of funds
+	if len(updated.unknownHoldings) > 0 {
+		// Wait until an allocation update tells us the holdings, rather than withdraw or complete on a guess
+		return &updated, sideEffects, WaitingForWithdraw, nil
+	}
 	if !updated.fullyWithdrawn() {
 		// The first participant in the channel submits the withdrawAll transaction
-		if updated.C.MyIndex == 0 && !updated.transactionSubmitted {
+		if updated.C.MyIndex == 0 && !updated.transactionSubmitted && updated.failedTransactions < protocols.MaxTransactionAttempts {
 			withdrawAll := protocols.NewWithdrawAllTransaction(updated.C.Id, latestSignedState)
 			sideEffects.TransactionsToSubmit = append(sideEffects.TransactionsToSubmit, withdrawAll)
 			updated.transactionSubmitted = true
@@ -291,6 +367,45 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 	return &updated, sideEffects, WaitingForNothing, nil
 }
 
+// clearChallenge checkpoints the channel with our latest supported state, clearing the challenge registered by another participant
+// with an earlier state. If our latest supported state is not later, the challenge cannot be cleared (see Failure).
+func (o *Objective) clearChallenge(sideEffects protocols.SideEffects) (protocols.Objective, protocols.SideEffects, protocols.WaitingFor, error) {
+	latest, err := o.C.LatestSupportedSignedState()
+	if err != nil {
+		return o, sideEffects, WaitingForChallengeCleared, fmt.Errorf("could not find a state to clear the challenge with: %w", err)
+	}
+	if latest.State().TurnNum > o.challengedWith && !o.checkpointSubmitted && o.failedTransactions < protocols.MaxTransactionAttempts {
+		checkpoint := protocols.NewCheckpointTransaction(o.C.Id, latest, []state.SignedState{})
+		sideEffects.TransactionsToSubmit = append(sideEffects.TransactionsToSubmit, checkpoint)
+		o.checkpointSubmitted = true
+	}
+	return o, sideEffects, WaitingForChallengeCleared, nil
+}
+
+// canClearChallenge returns true if our latest supported state is later than the state the channel was challenged with.
+func (o *Objective) canClearChallenge() bool {
+	latest, err := o.C.LatestSupportedSignedState()
+	return err == nil && latest.State().TurnNum > o.challengedWith
+}
+
+// Failure returns a reason if so many of our withdrawal transactions have failed that the objective should not submit another,
+// or if another participant has challenged the channel with a state which we cannot clear.
+func (o *Objective) Failure() (protocols.RejectionReason, bool) {
+	if o.challenged && !o.canClearChallenge() {
+		return protocols.RejectionReason{
+			Code:    protocols.DisputeRequired,
+			Message: fmt.Sprintf("channel %s was challenged with turn %d, which our latest supported state cannot clear", o.C.Id, o.challengedWith),
+		}, true
+	}
+	if o.failedTransactions < protocols.MaxTransactionAttempts {
+		return protocols.RejectionReason{}, false
+	}
+	return protocols.RejectionReason{
+		Code:    protocols.TransactionFailed,
+		Message: fmt.Sprintf("%d withdrawal transactions failed, most recently because %s", o.failedTransactions, o.transactionFailure),
+	}, true
+}
+
 // IsDirectDefundObjective inspects a objective id and returns true if the objective id is for a direct defund objective.
 func IsDirectDefundObjective(id protocols.ObjectiveId) bool {
 	return strings.HasPrefix(string(id), ObjectivePrefix)
@@ -317,15 +432,36 @@ func (o *Objective) fullyWithdrawn() bool {
 	return !o.C.OnChainFunding.IsNonZero()
 }
 
+// markHoldingsUnknown records that the on chain holdings of the asset are not known.
+func (o *Objective) markHoldingsUnknown(asset types.Address) {
+	if o.unknownHoldings == nil {
+		o.unknownHoldings = make(map[types.Address]bool)
+	}
+	o.unknownHoldings[asset] = true
+}
+
 // clone returns a deep copy of the receiver.
 func (o *Objective) clone() Objective {
 	clone := Objective{}
 	clone.Status = o.Status
+	clone.Version = o.Version
 
 	cClone := o.C.Clone()
 	clone.C = cClone
 	clone.finalTurnNum = o.finalTurnNum
+	clone.latestBlockNumber = o.latestBlockNumber
+	if o.unknownHoldings != nil {
+		clone.unknownHoldings = make(map[types.Address]bool, len(o.unknownHoldings))
+		for asset := range o.unknownHoldings {
+			clone.unknownHoldings[asset] = true
+		}
+	}
 	clone.transactionSubmitted = o.transactionSubmitted
+	clone.failedTransactions = o.failedTransactions
+	clone.transactionFailure = o.transactionFailure
+	clone.challenged = o.challenged
+	clone.challengedWith = o.challengedWith
+	clone.checkpointSubmitted = o.checkpointSubmitted
 
 	return clone
 }
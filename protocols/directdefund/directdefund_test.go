@@ -70,7 +70,7 @@ func signedTestState(s state.State, toSign []bool) (state.SignedState, error) {
 
 // newTestObjective returns a directdefund Objective constructed with a MockConsensusChannel.
 func newTestObjective() (Objective, error) {
-	cc, _ := testdata.Channels.MockConsensusChannel(alice.Address())
+	cc, _ := testdata.Channels.MockConsensusChannel(alice.Address(), big.NewInt(9001)) // the chain of the testdata states
 
 	getConsensusChannel := func(id types.Destination) (channel *consensus_channel.ConsensusChannel, err error) {
 		return cc, nil
@@ -134,7 +134,7 @@ func TestCrankAlice(t *testing.T) {
 	o, _ := newTestObjective()
 
 	// The first crank. Alice is expected to create and sign a final state
-	updated, se, wf, err := o.Crank(&alice.PrivateKey)
+	updated, se, wf, err := o.Crank(alice.Signer())
 
 	if err != nil {
 		t.Error(err)
@@ -168,7 +168,7 @@ func TestCrankAlice(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	updated, se, wf, err = updated.Crank(&alice.PrivateKey)
+	updated, se, wf, err = updated.Crank(alice.Signer())
 	if err != nil {
 		t.Error(err)
 	}
@@ -188,7 +188,7 @@ func TestCrankAlice(t *testing.T) {
 
 	// The third crank. Alice is expected to enter the terminal state of the defunding protocol.
 	updated.(*Objective).C.OnChainFunding = types.Funds{}
-	_, se, wf, err = updated.Crank(&alice.PrivateKey)
+	_, se, wf, err = updated.Crank(alice.Signer())
 	if err != nil {
 		t.Error(err)
 	}
@@ -223,7 +223,7 @@ func TestCrankBob(t *testing.T) {
 	}
 
 	// The first crank. Bob is expected to create and sign a final state
-	updated, se, wf, err := updated.Crank(&bob.PrivateKey)
+	updated, se, wf, err := updated.Crank(bob.Signer())
 
 	if err != nil {
 		t.Fatal(err)
@@ -248,7 +248,7 @@ func TestCrankBob(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	updated, se, wf, err = updated.Crank(&bob.PrivateKey)
+	updated, se, wf, err = updated.Crank(bob.Signer())
 	if err != nil {
 		t.Error(err)
 	}
@@ -274,7 +274,7 @@ func TestCrankBob(t *testing.T) {
 		t.Error(err)
 	}
 
-	_, se, wf, err = updated.Crank(&bob.PrivateKey)
+	_, se, wf, err = updated.Crank(bob.Signer())
 	if err != nil {
 		t.Error(err)
 	}
@@ -288,6 +288,54 @@ func TestCrankBob(t *testing.T) {
 	}
 }
 
+func TestRetractedWithdrawal(t *testing.T) {
+	o, _ := newTestObjective()
+	o.C.MyIndex = 1
+	asset := common.Address{}
+
+	// Bob countersigns Alice's final state
+	finalState := testState.Clone()
+	finalState.TurnNum = 2
+	finalState.IsFinal = true
+	finalStateSignedByAlice, _ := signedTestState(finalState, []bool{true, false})
+	updated, err := o.Update(protocols.CreateObjectivePayload(o.Id(), SignedStatePayload, finalStateSignedByAlice))
+	testhelpers.Ok(t, err)
+	updated, _, _, err = updated.Crank(bob.Signer())
+	testhelpers.Ok(t, err)
+
+	withdrawn := chainservice.NewAllocationUpdatedEvent(o.C.Id, 5, asset, common.Big0)
+	updated, err = updated.(*Objective).UpdateWithChainEvent(withdrawn)
+	testhelpers.Ok(t, err)
+
+	// An event from an earlier block is stale
+	stale := chainservice.NewAllocationUpdatedEvent(o.C.Id, 3, asset, big.NewInt(10))
+	updated, err = updated.(*Objective).UpdateWithChainEvent(stale)
+	testhelpers.Ok(t, err)
+	if got := updated.(*Objective).C.OnChainFunding[asset]; got.Cmp(common.Big0) != 0 {
+		t.Fatalf("expected a stale allocation update to be ignored, got holdings of %v", got)
+	}
+
+	// A reorg removes the withdrawal, and the holdings on the new chain cannot be read
+	retracted := chainservice.NewRetractedEvent(withdrawn, 5, asset, nil)
+	updated, err = updated.(*Objective).UpdateWithChainEvent(retracted)
+	testhelpers.Ok(t, err)
+	_, _, wf, err := updated.Crank(bob.Signer())
+	testhelpers.Ok(t, err)
+	if wf != WaitingForWithdraw {
+		t.Fatalf(`WaitingFor: expected %v, got %v`, WaitingForWithdraw, wf)
+	}
+
+	// The withdrawal is mined again on the new chain
+	withdrawn = chainservice.NewAllocationUpdatedEvent(o.C.Id, 6, asset, common.Big0)
+	updated, err = updated.(*Objective).UpdateWithChainEvent(withdrawn)
+	testhelpers.Ok(t, err)
+	_, _, wf, err = updated.Crank(bob.Signer())
+	testhelpers.Ok(t, err)
+	if wf != WaitingForNothing {
+		t.Fatalf(`WaitingFor: expected %v, got %v`, WaitingForNothing, wf)
+	}
+}
+
 func TestMarshalJSON(t *testing.T) {
 	ddfo, _ := newTestObjective()
 
@@ -321,12 +369,18 @@ func TestApproveReject(t *testing.T) {
 	if approved.GetStatus() != protocols.Approved {
 		t.Errorf("Expected approved status, got %v", approved.GetStatus())
 	}
-	rejected, sideEffects := o.Reject()
+	reason := protocols.RejectionReason{Code: protocols.CounterpartyNotAllowed, Message: "test"}
+	rejected, sideEffects := o.Reject(reason)
 	if rejected.GetStatus() != protocols.Rejected {
 		t.Errorf("Expected rejceted status, got %v", approved.GetStatus())
 	}
 	if len(sideEffects.MessagesToSend) != 1 {
 		t.Errorf("Expected to send one message")
 	}
+	for _, msg := range sideEffects.MessagesToSend {
+		if len(msg.RejectedObjectives) != 1 || msg.RejectedObjectives[0].Reason != reason {
+			t.Errorf("Expected rejection notice with reason %v, got %v", reason, msg.RejectedObjectives)
+		}
+	}
 
 }
@@ -12,9 +12,17 @@ import (
 // the channel's ID, making jsonObjective suitable for serialization
 type jsonObjective struct {
 	Status                protocols.ObjectiveStatus
+	Version               uint
 	C                     types.Destination
 	FinalTurnNum          uint64
+	LatestBlockNumber     uint64
+	UnknownHoldings       map[types.Address]bool
 	TransactionSumbmitted bool
+	FailedTransactions    uint
+	TransactionFailure    string
+	Challenged            bool
+	ChallengedWith        uint64
+	CheckpointSubmitted   bool
 }
 
 // MarshalJSON returns a JSON representation of the DirectDefundObjective
@@ -23,9 +31,17 @@ type jsonObjective struct {
 func (o Objective) MarshalJSON() ([]byte, error) {
 	jsonDDFO := jsonObjective{
 		o.Status,
+		o.Version,
 		o.C.Id,
 		o.finalTurnNum,
+		o.latestBlockNumber,
+		o.unknownHoldings,
 		o.transactionSubmitted,
+		o.failedTransactions,
+		o.transactionFailure,
+		o.challenged,
+		o.challengedWith,
+		o.checkpointSubmitted,
 	}
 
 	return json.Marshal(jsonDDFO)
@@ -50,9 +66,17 @@ func (o *Objective) UnmarshalJSON(data []byte) error {
 	o.C = &channel.Channel{}
 
 	o.Status = jsonDDFO.Status
+	o.Version = jsonDDFO.Version
 	o.C.Id = jsonDDFO.C
 	o.finalTurnNum = jsonDDFO.FinalTurnNum
+	o.latestBlockNumber = jsonDDFO.LatestBlockNumber
+	o.unknownHoldings = jsonDDFO.UnknownHoldings
 	o.transactionSubmitted = jsonDDFO.TransactionSumbmitted
+	o.failedTransactions = jsonDDFO.FailedTransactions
+	o.transactionFailure = jsonDDFO.TransactionFailure
+	o.challenged = jsonDDFO.Challenged
+	o.challengedWith = jsonDDFO.ChallengedWith
+	o.checkpointSubmitted = jsonDDFO.CheckpointSubmitted
 
 	return nil
 }
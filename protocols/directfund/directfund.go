@@ -13,6 +13,7 @@ import (
 	"github.com/statechannels/go-nitro/channel/state"
 	"github.com/statechannels/go-nitro/channel/state/outcome"
 	"github.com/statechannels/go-nitro/client/engine/chainservice"
+	"github.com/statechannels/go-nitro/crypto"
 	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/types"
 )
@@ -22,6 +23,7 @@ const (
 	WaitingForMyTurnToFund     protocols.WaitingFor = "WaitingForMyTurnToFund"
 	WaitingForCompleteFunding  protocols.WaitingFor = "WaitingForCompleteFunding"
 	WaitingForCompletePostFund protocols.WaitingFor = "WaitingForCompletePostFund"
+	WaitingForChallengeCleared protocols.WaitingFor = "WaitingForChallengeCleared"
 	WaitingForNothing          protocols.WaitingFor = "WaitingForNothing" // Finished
 )
 
@@ -37,28 +39,35 @@ func FundOnChainEffect(cId types.Destination, asset string, amount types.Funds)
 
 // Objective is a cache of data computed by reading from the store. It stores (potentially) infinite data
 type Objective struct {
-	Status protocols.ObjectiveStatus
-	C      *channel.Channel
-
-	myDepositSafetyThreshold types.Funds // if the on chain holdings are equal to this amount it is safe for me to deposit
-	myDepositTarget          types.Funds // I want to get the on chain holdings up to this much
-	fullyFundedThreshold     types.Funds // if the on chain holdings are equal
-	latestBlockNumber        uint64      // the latest block number we've seen
-	transactionSubmitted     bool        // whether a transition for the objective has been submitted or not
+	Status  protocols.ObjectiveStatus
+	Version uint // the version of the objective's protocol negotiated by its participants, or zero if none has been recorded
+	C       *channel.Channel
+
+	myDepositSafetyThreshold types.Funds            // if the on chain holdings are equal to this amount it is safe for me to deposit
+	myDepositTarget          types.Funds            // I want to get the on chain holdings up to this much
+	fullyFundedThreshold     types.Funds            // if the on chain holdings are equal
+	latestBlockNumber        uint64                 // the latest block number we've seen
+	unknownHoldings          map[types.Address]bool // assets whose holdings could not be read after a reorg
+	transactionSubmitted     bool                   // whether a transition for the objective has been submitted or not
+	failedTransactions       uint                   // the number of our deposit transactions which have failed
+	transactionFailure       string                 // the reason our most recent deposit transaction failed
+	challenged               bool                   // whether the channel has been challenged by another participant, without the challenge being cleared
+	challengedWith           uint64                 // the turn number of the state the channel was challenged with
+	checkpointSubmitted      bool                   // whether our checkpoint transaction clearing the challenge has been submitted
 }
 
-// GetChannelByIdFunction specifies a function that can be used to retrieve channels from a store.
-type GetChannelsByParticipantFunction func(participant types.Address) []*channel.Channel
+// GetChannelByIdFunction specifies a function that can be used to retrieve the channels on a chain from a store.
+type GetChannelsByParticipantFunction func(participant types.Address, chainId *big.Int) []*channel.Channel
 
-// GetTwoPartyConsensusLedgerFuncion describes functions which return a ConsensusChannel ledger channel between
+// GetTwoPartyConsensusLedgerFuncion describes functions which return a ConsensusChannel ledger channel on the given chain between
 // the calling client and the given counterparty, if such a channel exists.
-type GetTwoPartyConsensusLedgerFunction func(counterparty types.Address) (ledger *consensus_channel.ConsensusChannel, ok bool)
+type GetTwoPartyConsensusLedgerFunction func(counterparty types.Address, chainId *big.Int) (ledger *consensus_channel.ConsensusChannel, ok bool)
 
 // NewObjective creates a new direct funding objective from a given request.
 func NewObjective(request ObjectiveRequest, preApprove bool, myAddress types.Address, getChannels GetChannelsByParticipantFunction, getTwoPartyConsensusLedger GetTwoPartyConsensusLedgerFunction) (Objective, error) {
 
 	initialState := state.State{
-		ChainId:           big.NewInt(1337), // TODO https://github.com/statechannels/go-nitro/issues/601
+		ChainId:           request.ChainId,
 		Participants:      []types.Address{myAddress, request.CounterParty},
 		ChannelNonce:      request.Nonce,
 		AppDefinition:     request.AppDefinition,
@@ -82,16 +91,16 @@ func NewObjective(request ObjectiveRequest, preApprove bool, myAddress types.Add
 	if err != nil {
 		return Objective{}, fmt.Errorf("could not create new objective: %w", err)
 	}
-	if channelsExistWithCounterparty(request.CounterParty, getChannels, getTwoPartyConsensusLedger) {
-		return Objective{}, fmt.Errorf("a channel already exists with counterparty %s", request.CounterParty)
+	if channelsExistWithCounterparty(request.CounterParty, request.ChainId, getChannels, getTwoPartyConsensusLedger) {
+		return Objective{}, fmt.Errorf("a channel already exists with counterparty %s on chain %s", request.CounterParty, request.ChainId)
 	}
 	return objective, nil
 }
 
-// channelsExistWithCounterparty returns true if a channel or consensus_channel exists with the counterparty
-func channelsExistWithCounterparty(counterparty types.Address, getChannels GetChannelsByParticipantFunction, getTwoPartyConsensusLedger GetTwoPartyConsensusLedgerFunction) bool {
+// channelsExistWithCounterparty returns true if a channel or consensus_channel exists with the counterparty on the chain
+func channelsExistWithCounterparty(counterparty types.Address, chainId *big.Int, getChannels GetChannelsByParticipantFunction, getTwoPartyConsensusLedger GetTwoPartyConsensusLedgerFunction) bool {
 	// check for any channels that may be in the process of direct funding
-	channels := getChannels(counterparty)
+	channels := getChannels(counterparty, chainId)
 
 	for _, c := range channels {
 		// We only want to find directly funded channels that would have two participants
@@ -100,7 +109,7 @@ func channelsExistWithCounterparty(counterparty types.Address, getChannels GetCh
 		}
 	}
 
-	_, ok := getTwoPartyConsensusLedger(counterparty)
+	_, ok := getTwoPartyConsensusLedger(counterparty, chainId)
 
 	return ok
 }
@@ -176,11 +185,28 @@ func (dfo *Objective) OwnsChannel() types.Destination {
 	return dfo.C.Id
 }
 
+// Participants returns the participants in the channel being funded.
+func (dfo *Objective) Participants() []types.Address {
+	return dfo.C.Participants
+}
+
 // GetStatus returns the status of the objective.
 func (dfo *Objective) GetStatus() protocols.ObjectiveStatus {
 	return dfo.Status
 }
 
+// GetVersion returns the version of the objective's protocol negotiated by its participants, or zero if none has been recorded.
+func (dfo *Objective) GetVersion() uint {
+	return dfo.Version
+}
+
+// WithVersion returns a copy of the objective which records the negotiated version of its protocol.
+func (dfo *Objective) WithVersion(version uint) protocols.Objective {
+	updated := dfo.clone()
+	updated.Version = version
+	return &updated
+}
+
 // CreateConsensusChannel creates a ConsensusChannel from the Objective by extracting signatures and a single asset outcome from the post fund state.
 func (dfo *Objective) CreateConsensusChannel() (*consensus_channel.ConsensusChannel, error) {
 	ledger := dfo.C
@@ -243,16 +269,61 @@ func (o *Objective) Approve() protocols.Objective {
 	return &updated
 }
 
-func (o *Objective) Reject() (protocols.Objective, protocols.SideEffects) {
+func (o *Objective) Reject(reason protocols.RejectionReason) (protocols.Objective, protocols.SideEffects) {
 	updated := o.clone()
 
 	updated.Status = protocols.Rejected
 	peer := o.C.Participants[1-o.C.MyIndex]
 
-	sideEffects := protocols.SideEffects{MessagesToSend: protocols.CreateRejectionNoticeMessage(o.Id(), peer)}
+	sideEffects := protocols.SideEffects{MessagesToSend: protocols.CreateRejectionNoticeMessage(o.Id(), reason, peer)}
 	return &updated, sideEffects
 }
 
+// SafeToAbandon returns true if we have neither submitted a deposit transaction (which has not failed) nor signed the postfund state,
+// and none of our deposits have been observed on chain.
+func (o *Objective) SafeToAbandon() bool {
+	return !o.transactionSubmitted && !o.C.PostFundSignedByMe() && !o.depositObserved()
+}
+
+// clearChallenge checkpoints the channel with our latest supported state, clearing the challenge registered by another participant
+// with an earlier state. If our latest supported state is not later, the challenge cannot be cleared (see Failure).
+func (o *Objective) clearChallenge(sideEffects protocols.SideEffects) (protocols.Objective, protocols.SideEffects, protocols.WaitingFor, error) {
+	latest, err := o.C.LatestSupportedSignedState()
+	if err != nil {
+		return o, sideEffects, WaitingForChallengeCleared, fmt.Errorf("could not find a state to clear the challenge with: %w", err)
+	}
+	if latest.State().TurnNum > o.challengedWith && !o.checkpointSubmitted && o.failedTransactions < protocols.MaxTransactionAttempts {
+		checkpoint := protocols.NewCheckpointTransaction(o.C.Id, latest, []state.SignedState{})
+		sideEffects.TransactionsToSubmit = append(sideEffects.TransactionsToSubmit, checkpoint)
+		o.checkpointSubmitted = true
+	}
+	return o, sideEffects, WaitingForChallengeCleared, nil
+}
+
+// canClearChallenge returns true if our latest supported state is later than the state the channel was challenged with.
+func (o *Objective) canClearChallenge() bool {
+	latest, err := o.C.LatestSupportedSignedState()
+	return err == nil && latest.State().TurnNum > o.challengedWith
+}
+
+// Failure returns a reason if so many of our deposit transactions have failed that the objective should not submit another,
+// or if another participant has challenged the channel with a state which we cannot clear.
+func (o *Objective) Failure() (protocols.RejectionReason, bool) {
+	if o.challenged && !o.canClearChallenge() {
+		return protocols.RejectionReason{
+			Code:    protocols.DisputeRequired,
+			Message: fmt.Sprintf("channel %s was challenged with turn %d, which our latest supported state cannot clear", o.C.Id, o.challengedWith),
+		}, true
+	}
+	if o.failedTransactions < protocols.MaxTransactionAttempts {
+		return protocols.RejectionReason{}, false
+	}
+	return protocols.RejectionReason{
+		Code:    protocols.TransactionFailed,
+		Message: fmt.Sprintf("%d deposit transactions failed, most recently because %s", o.failedTransactions, o.transactionFailure),
+	}, true
+}
+
 // Update receives an ObjectivePayload, applies all applicable data to the DirectFundingObjectiveState,
 // and returns the updated state
 func (o *Objective) Update(p protocols.ObjectivePayload) (protocols.Objective, error) {
@@ -273,23 +344,71 @@ func (o *Objective) Update(p protocols.ObjectivePayload) (protocols.Objective, e
 
 // UpdateWithChainEvent updates the objective with observed on-chain data.
 //
-// Only Channel Deposit events are currently handled.
+// Channel Deposit events update the channel's holdings. When one of our deposit transactions fails,
+// the objective deposits again when next cranked, unless too many transactions have failed.
+// When a reorg retracts a Deposited event, the holdings are reset to those on the new chain, and
+// Deposited events from the retracted block onwards are accepted again.
+// When another participant challenges the channel, the objective clears the challenge with a checkpoint before doing anything else.
 func (o *Objective) UpdateWithChainEvent(event chainservice.Event) (protocols.Objective, error) {
 	updated := o.clone()
 
-	de, ok := event.(chainservice.DepositedEvent)
-	if !ok {
+	switch e := event.(type) {
+	case chainservice.DepositedEvent:
+		if e.BlockNum > updated.latestBlockNumber {
+			updated.C.OnChainFunding[e.AssetAddress] = e.NowHeld
+			updated.latestBlockNumber = e.BlockNum
+			delete(updated.unknownHoldings, e.AssetAddress)
+		}
+	case chainservice.RetractedEvent:
+		if _, ok := e.Retracted.(chainservice.ChallengeRegisteredEvent); ok {
+			updated.challenged = false
+			updated.checkpointSubmitted = false
+		}
+		if _, ok := e.Retracted.(chainservice.DepositedEvent); ok {
+			if e.NowHeld == nil {
+				// The holdings on the new chain could not be read, so we cannot tell how much is left to deposit
+				updated.markHoldingsUnknown(e.AssetAddress)
+			} else {
+				updated.C.OnChainFunding[e.AssetAddress] = e.NowHeld
+				delete(updated.unknownHoldings, e.AssetAddress)
+			}
+			if updated.latestBlockNumber >= e.BlockNum && e.BlockNum > 0 {
+				updated.latestBlockNumber = e.BlockNum - 1
+			}
+		}
+	case chainservice.TransactionConfirmedEvent:
+		break
+	case chainservice.ChallengeRegisteredEvent:
+		updated.challenged = true
+		updated.challengedWith = e.TurnNumRecord
+		updated.checkpointSubmitted = false
+	case chainservice.ChallengeClearedEvent:
+		updated.challenged = false
+		updated.checkpointSubmitted = false
+	case chainservice.TransactionFailedEvent:
+		if updated.checkpointSubmitted {
+			updated.checkpointSubmitted = false
+		} else {
+			updated.transactionSubmitted = false
+		}
+		updated.failedTransactions++
+		updated.transactionFailure = e.Reason
+	default:
 		return &updated, fmt.Errorf("objective %+v cannot handle event %+v", updated, event)
 	}
-	if de.BlockNum > updated.latestBlockNumber {
-		updated.C.OnChainFunding[de.AssetAddress] = de.NowHeld
-		updated.latestBlockNumber = de.BlockNum
-	}
 
 	return &updated, nil
 
 }
 
+// markHoldingsUnknown records that the on chain holdings of the asset are not known.
+func (o *Objective) markHoldingsUnknown(asset types.Address) {
+	if o.unknownHoldings == nil {
+		o.unknownHoldings = make(map[types.Address]bool)
+	}
+	o.unknownHoldings[asset] = true
+}
+
 func (o *Objective) otherParticipants() []types.Address {
 	others := make([]types.Address, 0)
 	for i, p := range o.C.Participants {
@@ -303,7 +422,7 @@ func (o *Objective) otherParticipants() []types.Address {
 // Crank inspects the extended state and declares a list of Effects to be executed
 // It's like a state machine transition function where the finite / enumerable state is returned (computed from the extended state)
 // rather than being independent of the extended state; and where there is only one type of event ("the crank") with no data on it at all
-func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.SideEffects, protocols.WaitingFor, error) {
+func (o *Objective) Crank(signer crypto.Signer) (protocols.Objective, protocols.SideEffects, protocols.WaitingFor, error) {
 	updated := o.clone()
 
 	sideEffects := protocols.SideEffects{}
@@ -312,9 +431,13 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 		return &updated, protocols.SideEffects{}, WaitingForNothing, protocols.ErrNotApproved
 	}
 
+	if updated.challenged {
+		return updated.clearChallenge(sideEffects)
+	}
+
 	// Prefunding
 	if !updated.C.PreFundSignedByMe() {
-		ss, err := updated.C.SignAndAddPrefund(secretKey)
+		ss, err := updated.C.SignAndAddPrefund(signer)
 		if err != nil {
 			return &updated, protocols.SideEffects{}, WaitingForCompletePrefund, fmt.Errorf("could not sign prefund %w", err)
 		}
@@ -327,6 +450,11 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 	}
 
 	// Funding
+	if len(updated.unknownHoldings) > 0 {
+		// Wait until a deposit tells us the holdings, rather than deposit or sign the postfund state on a guess
+		return &updated, sideEffects, WaitingForCompleteFunding, nil
+	}
+
 	fundingComplete := updated.fundingComplete() // note all information stored in state (since there are no real events)
 	amountToDeposit := updated.amountToDeposit()
 	safeToDeposit := updated.safeToDeposit()
@@ -335,7 +463,7 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 		return &updated, sideEffects, WaitingForMyTurnToFund, nil
 	}
 
-	if !fundingComplete && safeToDeposit && amountToDeposit.IsNonZero() && !updated.transactionSubmitted {
+	if !fundingComplete && safeToDeposit && amountToDeposit.IsNonZero() && !updated.transactionSubmitted && updated.failedTransactions < protocols.MaxTransactionAttempts {
 		deposit := protocols.NewDepositTransaction(updated.C.Id, amountToDeposit)
 		updated.transactionSubmitted = true
 		sideEffects.TransactionsToSubmit = append(sideEffects.TransactionsToSubmit, deposit)
@@ -348,7 +476,7 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 	// Postfunding
 	if !updated.C.PostFundSignedByMe() {
 
-		ss, err := updated.C.SignAndAddPostfund(secretKey)
+		ss, err := updated.C.SignAndAddPostfund(signer)
 
 		if err != nil {
 			return &updated, protocols.SideEffects{}, WaitingForCompletePostFund, fmt.Errorf("could not sign postfund %w", err)
@@ -407,6 +535,18 @@ func (o *Objective) safeToDeposit() bool {
 	return true
 }
 
+// depositObserved returns true if the recorded OnChainHoldings of any asset exceed the threshold for my deposit to be safe,
+// which means that my deposit has been made.
+func (o *Objective) depositObserved() bool {
+	for asset, safetyThreshold := range o.myDepositSafetyThreshold {
+		chainHolding, ok := o.C.OnChainFunding[asset]
+		if ok && types.Gt(chainHolding, safetyThreshold) {
+			return true
+		}
+	}
+	return false
+}
+
 // amountToDeposit computes the appropriate amount to deposit given the current recorded OnChainHoldings
 func (o *Objective) amountToDeposit() types.Funds {
 	deposits := make(types.Funds, len(o.C.OnChainFunding))
@@ -426,6 +566,7 @@ func (o *Objective) amountToDeposit() types.Funds {
 func (o *Objective) clone() Objective {
 	clone := Objective{}
 	clone.Status = o.Status
+	clone.Version = o.Version
 
 	cClone := o.C.Clone()
 	clone.C = cClone
@@ -434,7 +575,18 @@ func (o *Objective) clone() Objective {
 	clone.myDepositTarget = o.myDepositTarget.Clone()
 	clone.fullyFundedThreshold = o.fullyFundedThreshold.Clone()
 	clone.latestBlockNumber = o.latestBlockNumber
+	if o.unknownHoldings != nil {
+		clone.unknownHoldings = make(map[types.Address]bool, len(o.unknownHoldings))
+		for asset := range o.unknownHoldings {
+			clone.unknownHoldings[asset] = true
+		}
+	}
 	clone.transactionSubmitted = o.transactionSubmitted
+	clone.failedTransactions = o.failedTransactions
+	clone.transactionFailure = o.transactionFailure
+	clone.challenged = o.challenged
+	clone.challengedWith = o.challengedWith
+	clone.checkpointSubmitted = o.checkpointSubmitted
 	return clone
 }
 
@@ -445,6 +597,7 @@ func IsDirectFundObjective(id protocols.ObjectiveId) bool {
 
 // ObjectiveRequest represents a request to create a new direct funding objective.
 type ObjectiveRequest struct {
+	ChainId           *big.Int // the chain on which the channel is funded
 	CounterParty      types.Address
 	ChallengeDuration uint32
 	Outcome           outcome.Exit
@@ -455,7 +608,7 @@ type ObjectiveRequest struct {
 
 // Id returns the objective id for the request.
 func (r ObjectiveRequest) Id(myAddress types.Address) protocols.ObjectiveId {
-	fixedPart := state.FixedPart{ChainId: big.NewInt(9001), // TODO add this field to the request and pull it from there. https://github.com/statechannels/go-nitro/issues/601
+	fixedPart := state.FixedPart{ChainId: r.ChainId,
 		Participants:      []types.Address{myAddress, r.CounterParty},
 		ChannelNonce:      r.Nonce,
 		ChallengeDuration: r.ChallengeDuration}
@@ -473,7 +626,7 @@ type ObjectiveResponse struct {
 // Response computes and returns the appropriate response from the request.
 func (r ObjectiveRequest) Response(myAddress types.Address) ObjectiveResponse {
 	fixedPart := state.FixedPart{
-		ChainId:           big.NewInt(1337), // TODO add this field to the request and pull it from there. https://github.com/statechannels/go-nitro/issues/601
+		ChainId:           r.ChainId,
 		Participants:      []types.Address{myAddress, r.CounterParty},
 		ChannelNonce:      r.Nonce,
 		ChallengeDuration: r.ChallengeDuration,
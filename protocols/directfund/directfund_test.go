@@ -50,13 +50,14 @@ var testState = state.State{
 // TestNew tests the constructor using a TestState fixture
 func TestNew(t *testing.T) {
 
-	getByParticipant := func(id types.Address) []*channel.Channel {
+	getByParticipant := func(id types.Address, chainId *big.Int) []*channel.Channel {
 		return []*channel.Channel{}
 	}
-	getByConsensus := func(id types.Address) (*consensus_channel.ConsensusChannel, bool) {
+	getByConsensus := func(id types.Address, chainId *big.Int) (*consensus_channel.ConsensusChannel, bool) {
 		return nil, false
 	}
 	request := ObjectiveRequest{
+		ChainId:           testState.ChainId,
 		CounterParty:      testState.Participants[1],
 		ChallengeDuration: testState.ChallengeDuration,
 		Outcome:           testState.Outcome,
@@ -68,7 +69,7 @@ func TestNew(t *testing.T) {
 		t.Error(err)
 	}
 
-	getByParticipantHasChannel := func(id types.Address) []*channel.Channel {
+	getByParticipantHasChannel := func(id types.Address, chainId *big.Int) []*channel.Channel {
 		c, _ := channel.New(testState, 0)
 		return []*channel.Channel{c}
 	}
@@ -77,7 +78,7 @@ func TestNew(t *testing.T) {
 		t.Errorf("Expected an error when constructing with an objective when an existing channel exists")
 	}
 
-	getByConsensusHasChannel := func(id types.Address) (*consensus_channel.ConsensusChannel, bool) {
+	getByConsensusHasChannel := func(id types.Address, chainId *big.Int) (*consensus_channel.ConsensusChannel, bool) {
 		return nil, true
 	}
 	if _, err := NewObjective(request, false, testState.Participants[0], getByParticipant, getByConsensusHasChannel); err == nil {
@@ -212,7 +213,7 @@ func TestCrank(t *testing.T) {
 	// END test data preparation
 
 	// Assert that cranking an unapproved objective returns an error
-	if _, _, _, err := s.Crank(&alice.PrivateKey); err == nil {
+	if _, _, _, err := s.Crank(alice.Signer()); err == nil {
 		t.Error(`Expected error when cranking unapproved objective, but got nil`)
 	}
 
@@ -225,7 +226,7 @@ func TestCrank(t *testing.T) {
 	//  - what side effects are declared.
 
 	// Initial Crank
-	_, sideEffects, waitingFor, err := o.Crank(&alice.PrivateKey)
+	_, sideEffects, waitingFor, err := o.Crank(alice.Signer())
 	if err != nil {
 		t.Error(err)
 	}
@@ -242,21 +243,27 @@ func TestCrank(t *testing.T) {
 	o.C.AddStateWithSignature(o.C.PreFundState(), correctSignatureByBobOnPreFund)
 
 	// Cranking should move us to the next waiting point
-	_, _, waitingFor, err = o.Crank(&alice.PrivateKey)
+	_, _, waitingFor, err = o.Crank(alice.Signer())
 	if err != nil {
 		t.Error(err)
 	}
 	if waitingFor != WaitingForMyTurnToFund {
 		t.Fatalf(`WaitingFor: expected %v, got %v`, WaitingForMyTurnToFund, waitingFor)
 	}
+	if !o.SafeToAbandon() {
+		t.Error("Expected objective to be safe to abandon before depositing")
+	}
 
 	// Manually make the first "deposit"
 	o.C.OnChainFunding[testState.Outcome[0].Asset] = testState.Outcome[0].Allocations[0].Amount
-	updated, sideEffects, waitingFor, err := o.Crank(&alice.PrivateKey)
+	updated, sideEffects, waitingFor, err := o.Crank(alice.Signer())
 
 	if !updated.(*Objective).transactionSubmitted {
 		t.Fatalf("Expected transactionSubmitted flag to be set to true")
 	}
+	if updated.(*Objective).SafeToAbandon() {
+		t.Error("Expected objective not to be safe to abandon after depositing")
+	}
 	if err != nil {
 		t.Error(err)
 	}
@@ -271,7 +278,7 @@ func TestCrank(t *testing.T) {
 	// Manually make the second "deposit"
 	totalAmountAllocated := testState.Outcome[0].TotalAllocated()
 	o.C.OnChainFunding[testState.Outcome[0].Asset] = totalAmountAllocated
-	_, sideEffects, waitingFor, err = o.Crank(&alice.PrivateKey)
+	_, sideEffects, waitingFor, err = o.Crank(alice.Signer())
 	if err != nil {
 		t.Error(err)
 	}
@@ -288,7 +295,7 @@ func TestCrank(t *testing.T) {
 
 	// This should be the final crank
 	o.C.OnChainFunding[testState.Outcome[0].Asset] = totalAmountAllocated
-	_, _, waitingFor, err = o.Crank(&alice.PrivateKey)
+	_, _, waitingFor, err = o.Crank(alice.Signer())
 	if err != nil {
 		t.Error(err)
 	}
@@ -297,6 +304,169 @@ func TestCrank(t *testing.T) {
 	}
 }
 
+func TestTransactionFailure(t *testing.T) {
+	id := protocols.ObjectiveId(ObjectivePrefix + testState.ChannelId().String())
+	op := protocols.CreateObjectivePayload(id, SignedStatePayload, state.NewSignedState(testState))
+	s, _ := ConstructFromPayload(false, op, testState.Participants[0])
+	o := s.Approve().(*Objective)
+
+	// Progress the objective to the point where it is our turn to deposit
+	aliceSig, _ := o.C.PreFundState().Sign(alice.PrivateKey)
+	bobSig, _ := o.C.PreFundState().Sign(bob.PrivateKey)
+	o.C.AddStateWithSignature(o.C.PreFundState(), aliceSig)
+	o.C.AddStateWithSignature(o.C.PreFundState(), bobSig)
+	o.C.OnChainFunding[testState.Outcome[0].Asset] = testState.Outcome[0].Allocations[0].Amount
+
+	var objective protocols.Objective = o
+	for attempt := 1; attempt <= protocols.MaxTransactionAttempts; attempt++ {
+		cranked, sideEffects, _, err := objective.Crank(alice.Signer())
+		testhelpers.Ok(t, err)
+		if len(sideEffects.TransactionsToSubmit) != 1 {
+			t.Fatalf("attempt %d: expected a deposit to be submitted, got %+v", attempt, sideEffects.TransactionsToSubmit)
+		}
+
+		failed := chainservice.NewTransactionFailedEvent(o.C.Id, 0, common.Hash{}, "execution reverted")
+		objective, err = cranked.(*Objective).UpdateWithChainEvent(failed)
+		testhelpers.Ok(t, err)
+	}
+
+	updated := objective.(*Objective)
+	reason, failed := updated.Failure()
+	if !failed || reason.Code != protocols.TransactionFailed {
+		t.Fatalf("expected the objective to fail after %d failed transactions, got %+v", protocols.MaxTransactionAttempts, reason)
+	}
+	if !updated.SafeToAbandon() {
+		t.Error("expected the objective to be safe to abandon, since no deposit was made")
+	}
+	_, sideEffects, _, err := updated.Crank(alice.Signer())
+	testhelpers.Ok(t, err)
+	if len(sideEffects.TransactionsToSubmit) != 0 {
+		t.Fatalf("expected no further deposits to be submitted, got %+v", sideEffects.TransactionsToSubmit)
+	}
+}
+
+func TestRetractedDeposit(t *testing.T) {
+	id := protocols.ObjectiveId(ObjectivePrefix + testState.ChannelId().String())
+	op := protocols.CreateObjectivePayload(id, SignedStatePayload, state.NewSignedState(testState))
+	s, _ := ConstructFromPayload(false, op, testState.Participants[0])
+	o := s.Approve().(*Objective)
+	asset := testState.Outcome[0].Asset
+	amount := testState.Outcome[0].Allocations[0].Amount
+
+	deposited := chainservice.NewDepositedEvent(o.C.Id, 5, asset, amount, amount)
+	updated, err := o.UpdateWithChainEvent(deposited)
+	testhelpers.Ok(t, err)
+
+	// A reorg removes the deposit
+	retracted := chainservice.NewRetractedEvent(deposited, 5, asset, big.NewInt(0))
+	updated, err = updated.(*Objective).UpdateWithChainEvent(retracted)
+	testhelpers.Ok(t, err)
+	if got := updated.(*Objective).C.OnChainFunding[asset]; got.Cmp(big.NewInt(0)) != 0 {
+		t.Fatalf("expected holdings of 0 after the retraction, got %v", got)
+	}
+
+	// The deposit is mined again in the same block on the new chain
+	updated, err = updated.(*Objective).UpdateWithChainEvent(deposited)
+	testhelpers.Ok(t, err)
+	if got := updated.(*Objective).C.OnChainFunding[asset]; got.Cmp(amount) != 0 {
+		t.Fatalf("expected holdings of %v after the deposit is mined again, got %v", amount, got)
+	}
+}
+
+func TestRetractionWithUnknownHoldings(t *testing.T) {
+	id := protocols.ObjectiveId(ObjectivePrefix + testState.ChannelId().String())
+	op := protocols.CreateObjectivePayload(id, SignedStatePayload, state.NewSignedState(testState))
+	s, _ := ConstructFromPayload(false, op, testState.Participants[0])
+	o := s.Approve().(*Objective)
+	asset := testState.Outcome[0].Asset
+
+	// Progress the objective to the point where it is our turn to deposit
+	aliceSig, _ := o.C.PreFundState().Sign(alice.PrivateKey)
+	bobSig, _ := o.C.PreFundState().Sign(bob.PrivateKey)
+	o.C.AddStateWithSignature(o.C.PreFundState(), aliceSig)
+	o.C.AddStateWithSignature(o.C.PreFundState(), bobSig)
+	theirDeposit := testState.Outcome[0].Allocations[0].Amount
+	deposited := chainservice.NewDepositedEvent(o.C.Id, 5, asset, theirDeposit, theirDeposit)
+	o.C.OnChainFunding[asset] = theirDeposit
+
+	// A reorg removes their deposit, and the holdings on the new chain cannot be read
+	retracted := chainservice.NewRetractedEvent(deposited, 5, asset, nil)
+	updated, err := o.UpdateWithChainEvent(retracted)
+	testhelpers.Ok(t, err)
+
+	_, sideEffects, waitingFor, err := updated.Crank(alice.Signer())
+	testhelpers.Ok(t, err)
+	if waitingFor != WaitingForCompleteFunding || len(sideEffects.TransactionsToSubmit) != 0 {
+		t.Fatalf("expected the objective to wait without depositing, got %v and %+v", waitingFor, sideEffects.TransactionsToSubmit)
+	}
+
+	// A later deposit event tells us the holdings, and we make our deposit
+	deposited = chainservice.NewDepositedEvent(o.C.Id, 6, asset, theirDeposit, theirDeposit)
+	updated, err = updated.(*Objective).UpdateWithChainEvent(deposited)
+	testhelpers.Ok(t, err)
+	_, sideEffects, _, err = updated.Crank(alice.Signer())
+	testhelpers.Ok(t, err)
+	if len(sideEffects.TransactionsToSubmit) != 1 {
+		t.Fatalf("expected a deposit to be submitted, got %+v", sideEffects.TransactionsToSubmit)
+	}
+}
+
+func TestChallengeResponse(t *testing.T) {
+	id := protocols.ObjectiveId(ObjectivePrefix + testState.ChannelId().String())
+	op := protocols.CreateObjectivePayload(id, SignedStatePayload, state.NewSignedState(testState))
+	s, _ := ConstructFromPayload(false, op, testState.Participants[0])
+	o := s.Approve().(*Objective)
+
+	// The prefund state is supported
+	aliceSig, _ := o.C.PreFundState().Sign(alice.PrivateKey)
+	bobSig, _ := o.C.PreFundState().Sign(bob.PrivateKey)
+	o.C.AddStateWithSignature(o.C.PreFundState(), aliceSig)
+	o.C.AddStateWithSignature(o.C.PreFundState(), bobSig)
+
+	// A challenge with the prefund state cannot be cleared, so a dispute is required
+	challenged := chainservice.NewChallengeRegisteredEvent(o.C.Id, 5, 0, 100, false)
+	updated, err := o.UpdateWithChainEvent(challenged)
+	testhelpers.Ok(t, err)
+	if reason, failed := updated.(*Objective).Failure(); !failed || reason.Code != protocols.DisputeRequired {
+		t.Fatalf("expected a dispute to be required, got %+v", reason)
+	}
+
+	// Once the postfund state is supported, the challenge is cleared with a checkpoint
+	aliceSig, _ = o.C.PostFundState().Sign(alice.PrivateKey)
+	bobSig, _ = o.C.PostFundState().Sign(bob.PrivateKey)
+	updated.(*Objective).C.AddStateWithSignature(o.C.PostFundState(), aliceSig)
+	updated.(*Objective).C.AddStateWithSignature(o.C.PostFundState(), bobSig)
+	if _, failed := updated.(*Objective).Failure(); failed {
+		t.Fatal("expected the challenge to be clearable")
+	}
+	cranked, sideEffects, waitingFor, err := updated.Crank(alice.Signer())
+	testhelpers.Ok(t, err)
+	if waitingFor != WaitingForChallengeCleared || len(sideEffects.TransactionsToSubmit) != 1 {
+		t.Fatalf("expected a checkpoint to be submitted, got %v and %+v", waitingFor, sideEffects.TransactionsToSubmit)
+	}
+	checkpoint, ok := sideEffects.TransactionsToSubmit[0].(protocols.CheckpointTransaction)
+	if !ok || checkpoint.Candidate.State().TurnNum != 1 {
+		t.Fatalf("expected a checkpoint with the postfund state, got %+v", sideEffects.TransactionsToSubmit[0])
+	}
+
+	// The checkpoint is only submitted once
+	_, sideEffects, _, err = cranked.Crank(alice.Signer())
+	testhelpers.Ok(t, err)
+	if len(sideEffects.TransactionsToSubmit) != 0 {
+		t.Fatalf("expected no further transactions, got %+v", sideEffects.TransactionsToSubmit)
+	}
+
+	// Once the challenge is cleared, the objective carries on funding the channel
+	cleared := chainservice.NewChallengeClearedEvent(o.C.Id, 6, 1)
+	updated, err = cranked.(*Objective).UpdateWithChainEvent(cleared)
+	testhelpers.Ok(t, err)
+	_, _, waitingFor, err = updated.Crank(alice.Signer())
+	testhelpers.Ok(t, err)
+	if waitingFor != WaitingForMyTurnToFund {
+		t.Fatalf("expected the objective to carry on funding, got %v", waitingFor)
+	}
+}
+
 func TestClone(t *testing.T) {
 	compareObjectives := func(a, b protocols.Objective) string {
 		return cmp.Diff(&a, &b, cmp.AllowUnexported(Objective{}, channel.Channel{}, big.Int{}, state.SignedState{}))
@@ -359,11 +529,17 @@ func TestApproveReject(t *testing.T) {
 	if approved.GetStatus() != protocols.Approved {
 		t.Errorf("Expected approved status, got %v", approved.GetStatus())
 	}
-	rejected, sideEffects := o.Reject()
+	reason := protocols.RejectionReason{Code: protocols.CounterpartyNotAllowed, Message: "test"}
+	rejected, sideEffects := o.Reject(reason)
 	if rejected.GetStatus() != protocols.Rejected {
 		t.Errorf("Expected rejceted status, got %v", approved.GetStatus())
 	}
 	if len(sideEffects.MessagesToSend) != 1 {
 		t.Errorf("Expected to send one message")
 	}
+	for _, msg := range sideEffects.MessagesToSend {
+		if len(msg.RejectedObjectives) != 1 || msg.RejectedObjectives[0].Reason != reason {
+			t.Errorf("Expected rejection notice with reason %v, got %v", reason, msg.RejectedObjectives)
+		}
+	}
 }
@@ -11,14 +11,21 @@ import (
 // jsonObjective replaces the directfund.Objective's channel pointer with the
 // channel's ID, making jsonObjective suitable for serialization
 type jsonObjective struct {
-	Status protocols.ObjectiveStatus
-	C      types.Destination
+	Status  protocols.ObjectiveStatus
+	Version uint
+	C       types.Destination
 
 	MyDepositSafetyThreshold types.Funds
 	MyDepositTarget          types.Funds
 	FullyFundedThreshold     types.Funds
 	LatestBlockNumber        uint64
+	UnknownHoldings          map[types.Address]bool
 	TransactionSumbmitted    bool
+	FailedTransactions       uint
+	TransactionFailure       string
+	Challenged               bool
+	ChallengedWith           uint64
+	CheckpointSubmitted      bool
 }
 
 // MarshalJSON returns a JSON representation of the DirectFundObjective
@@ -27,12 +34,19 @@ type jsonObjective struct {
 func (o Objective) MarshalJSON() ([]byte, error) {
 	jsonDFO := jsonObjective{
 		o.Status,
+		o.Version,
 		o.C.Id,
 		o.myDepositSafetyThreshold,
 		o.myDepositTarget,
 		o.fullyFundedThreshold,
 		o.latestBlockNumber,
+		o.unknownHoldings,
 		o.transactionSubmitted,
+		o.failedTransactions,
+		o.transactionFailure,
+		o.challenged,
+		o.challengedWith,
+		o.checkpointSubmitted,
 	}
 	return json.Marshal(jsonDFO)
 }
@@ -57,11 +71,18 @@ func (o *Objective) UnmarshalJSON(data []byte) error {
 	o.C.Id = jsonDFO.C
 
 	o.Status = jsonDFO.Status
+	o.Version = jsonDFO.Version
 	o.fullyFundedThreshold = jsonDFO.FullyFundedThreshold
 	o.myDepositTarget = jsonDFO.MyDepositTarget
 	o.myDepositSafetyThreshold = jsonDFO.MyDepositSafetyThreshold
 	o.latestBlockNumber = jsonDFO.LatestBlockNumber
+	o.unknownHoldings = jsonDFO.UnknownHoldings
 	o.transactionSubmitted = jsonDFO.TransactionSumbmitted
+	o.failedTransactions = jsonDFO.FailedTransactions
+	o.transactionFailure = jsonDFO.TransactionFailure
+	o.challenged = jsonDFO.Challenged
+	o.challengedWith = jsonDFO.ChallengedWith
+	o.checkpointSubmitted = jsonDFO.CheckpointSubmitted
 
 	return nil
 }
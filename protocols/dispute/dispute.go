@@ -0,0 +1,235 @@
+// Package dispute implements an on-chain protocol to recover the funds of a channel whose other participants have stopped cooperating:
+// the channel is challenged with its latest supported state, and its outcome is paid out once the challenge finalizes.
+package dispute // import "github.com/statechannels/go-nitro/protocols/dispute"
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/client/engine/chainservice"
+	NitroAdjudicator "github.com/statechannels/go-nitro/client/engine/chainservice/adjudicator"
+	"github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+const (
+	WaitingForChallenge    protocols.WaitingFor = "WaitingForChallenge"
+	WaitingForFinalization protocols.WaitingFor = "WaitingForFinalization"
+	WaitingForPayout       protocols.WaitingFor = "WaitingForPayout"
+	WaitingForNothing      protocols.WaitingFor = "WaitingForNothing" // Finished
+)
+
+const ObjectivePrefix = "Dispute-"
+
+// Objective challenges a channel on chain, and pays out its outcome once the challenge has finalized.
+//
+// The objective learns of the challenge and the payout through chain events, and of the passing of time on chain through UpdateWithChainTime.
+type Objective struct {
+	Status             protocols.ObjectiveStatus
+	Candidate          state.SignedState // the latest supported state of the channel, with which it is challenged and paid out
+	challengeSubmitted bool              // whether our challenge transaction has been submitted since the channel was last unchallenged
+	finalizesAt        uint64            // the time at which the challenge with our candidate finalizes, or zero if no such challenge is registered
+	chainTime          uint64            // the latest time on chain we've been told of
+	transferSubmitted  bool              // whether our transferAll transaction has been submitted
+	paidOut            bool              // whether the channel's outcome has been paid out
+	supersededBy       uint64            // the turn number of a later state than our candidate which the channel was challenged or checkpointed with, if any
+	failedTransactions uint              // the number of our transactions which have failed
+	transactionFailure string            // the reason our most recent transaction failed
+}
+
+// NewObjective creates an approved objective which disputes the channel of the supported candidate state.
+func NewObjective(candidate state.SignedState) (Objective, error) {
+	if !candidate.HasAllSignatures() {
+		return Objective{}, fmt.Errorf("cannot dispute channel %s with a state which is not supported", candidate.ChannelId())
+	}
+	return Objective{Status: protocols.Approved, Candidate: candidate}, nil
+}
+
+// Id returns the unique id of the objective.
+func (o *Objective) Id() protocols.ObjectiveId {
+	return protocols.ObjectiveId(ObjectivePrefix + o.OwnsChannel().String())
+}
+
+// Approve returns an approved copy of the objective. Dispute objectives are created approved, since they are only started by us.
+func (o *Objective) Approve() protocols.Objective {
+	updated := o.clone()
+	updated.Status = protocols.Approved
+	return &updated
+}
+
+// Reject returns a rejected copy of the objective. No other participant is notified, since the dispute takes place on chain.
+func (o *Objective) Reject(reason protocols.RejectionReason) (protocols.Objective, protocols.SideEffects) {
+	updated := o.clone()
+	updated.Status = protocols.Rejected
+	return &updated, protocols.SideEffects{}
+}
+
+// Update returns an error, since disputes are not updated by messages from other participants.
+func (o *Objective) Update(p protocols.ObjectivePayload) (protocols.Objective, error) {
+	return o, fmt.Errorf("dispute objective %s cannot be updated with a payload", o.Id())
+}
+
+// OwnsChannel returns the channel being disputed.
+func (o Objective) OwnsChannel() types.Destination {
+	return o.Candidate.ChannelId()
+}
+
+// Participants returns the participants in the channel being disputed.
+func (o Objective) Participants() []types.Address {
+	return o.Candidate.State().Participants
+}
+
+// GetStatus returns the status of the objective.
+func (o Objective) GetStatus() protocols.ObjectiveStatus {
+	return o.Status
+}
+
+// Related returns nothing, since the objective holds the state it needs and leaves the channel in the store untouched.
+func (o *Objective) Related() []protocols.Storable {
+	return []protocols.Storable{}
+}
+
+// UpdateWithChainEvent updates the objective with observed on-chain data.
+//
+// A challenge with our candidate state records when the channel finalizes. A challenge with an earlier state, or a checkpoint which
+// clears the challenge, leads the objective to challenge again, while a challenge or checkpoint with a later state means our candidate
+// can no longer be paid out. Once the channel has finalized, an Allocation Updated event means its outcome has been paid out.
+// When one of our transactions fails, the objective submits it again when next cranked, unless too many transactions have failed.
+func (o *Objective) UpdateWithChainEvent(event chainservice.Event) (protocols.Objective, error) {
+	updated := o.clone()
+	turnNum := updated.Candidate.State().TurnNum
+
+	switch e := event.(type) {
+	case chainservice.ChallengeRegisteredEvent:
+		switch {
+		case e.TurnNumRecord == turnNum:
+			updated.challengeSubmitted = true
+			updated.finalizesAt = e.FinalizesAt
+		case e.TurnNumRecord > turnNum:
+			updated.supersededBy = e.TurnNumRecord
+		default:
+			updated.challengeSubmitted = false
+			updated.finalizesAt = 0
+		}
+	case chainservice.ChallengeClearedEvent:
+		if e.NewTurnNumRecord > turnNum {
+			updated.supersededBy = e.NewTurnNumRecord
+		}
+		updated.challengeSubmitted = false
+		updated.finalizesAt = 0
+	case chainservice.AllocationUpdatedEvent:
+		if updated.finalizesAt != 0 {
+			updated.paidOut = true
+		}
+	case chainservice.RetractedEvent:
+		switch e.Retracted.(type) {
+		case chainservice.ChallengeRegisteredEvent:
+			updated.challengeSubmitted = false
+			updated.finalizesAt = 0
+		case chainservice.AllocationUpdatedEvent:
+			updated.transferSubmitted = false
+			updated.paidOut = false
+		}
+	case chainservice.DepositedEvent, chainservice.ConcludedEvent, chainservice.TransactionConfirmedEvent:
+		break
+	case chainservice.TransactionFailedEvent:
+		if updated.finalizesAt == 0 {
+			updated.challengeSubmitted = false
+		} else {
+			updated.transferSubmitted = false
+		}
+		updated.failedTransactions++
+		updated.transactionFailure = e.Reason
+	default:
+		return &updated, fmt.Errorf("objective %+v cannot handle event %+v", updated, event)
+	}
+	return &updated, nil
+}
+
+// UpdateWithChainTime records the time (in seconds since the Unix epoch) of the chain's latest block, so that the objective
+// can tell whether its challenge has finalized.
+func (o *Objective) UpdateWithChainTime(now uint64) *Objective {
+	updated := o.clone()
+	if now > updated.chainTime {
+		updated.chainTime = now
+	}
+	return &updated
+}
+
+// Crank inspects the extended state and declares a list of Effects to be executed
+func (o *Objective) Crank(signer crypto.Signer) (protocols.Objective, protocols.SideEffects, protocols.WaitingFor, error) {
+	updated := o.clone()
+	sideEffects := protocols.SideEffects{}
+
+	if updated.Status != protocols.Approved {
+		return &updated, sideEffects, WaitingForNothing, protocols.ErrNotApproved
+	}
+	if updated.paidOut {
+		updated.Status = protocols.Completed
+		return &updated, sideEffects, WaitingForNothing, nil
+	}
+	canSubmit := updated.supersededBy == 0 && updated.failedTransactions < protocols.MaxTransactionAttempts
+
+	// Challenge the channel with our candidate, unless it is already challenged with it
+	if updated.finalizesAt == 0 {
+		if !updated.challengeSubmitted && canSubmit {
+			challengerSig, err := NitroAdjudicator.SignChallengeMessage(updated.Candidate.State(), signer)
+			if err != nil {
+				return &updated, sideEffects, WaitingForChallenge, fmt.Errorf("could not sign challenge: %w", err)
+			}
+			challenge := protocols.NewChallengeTransaction(updated.OwnsChannel(), updated.Candidate, []state.SignedState{}, challengerSig)
+			sideEffects.TransactionsToSubmit = append(sideEffects.TransactionsToSubmit, challenge)
+			updated.challengeSubmitted = true
+		}
+		return &updated, sideEffects, WaitingForChallenge, nil
+	}
+
+	if updated.chainTime < updated.finalizesAt {
+		return &updated, sideEffects, WaitingForFinalization, nil
+	}
+
+	// Pay out the outcome of the finalized channel
+	if !updated.transferSubmitted && canSubmit {
+		transferAll := protocols.NewTransferAllTransaction(updated.OwnsChannel(), updated.Candidate.State())
+		sideEffects.TransactionsToSubmit = append(sideEffects.TransactionsToSubmit, transferAll)
+		updated.transferSubmitted = true
+	}
+	return &updated, sideEffects, WaitingForPayout, nil
+}
+
+// Failure returns a reason if the objective can no longer recover the channel's funds: because so many of our transactions
+// have failed that it should not submit another, or because the channel was challenged or checkpointed with a later state than ours.
+func (o *Objective) Failure() (protocols.RejectionReason, bool) {
+	if o.supersededBy != 0 {
+		return protocols.RejectionReason{
+			Code:    protocols.DisputeRequired,
+			Message: fmt.Sprintf("channel %s was challenged or checkpointed with turn %d, later than our latest supported turn %d", o.OwnsChannel(), o.supersededBy, o.Candidate.State().TurnNum),
+		}, true
+	}
+	if o.failedTransactions < protocols.MaxTransactionAttempts {
+		return protocols.RejectionReason{}, false
+	}
+	return protocols.RejectionReason{
+		Code:    protocols.TransactionFailed,
+		Message: fmt.Sprintf("%d dispute transactions failed, most recently because %s", o.failedTransactions, o.transactionFailure),
+	}, true
+}
+
+// IsDisputeObjective inspects a objective id and returns true if the objective id is for a dispute objective.
+func IsDisputeObjective(id protocols.ObjectiveId) bool {
+	return strings.HasPrefix(string(id), ObjectivePrefix)
+}
+
+// ObjectiveIdFor returns the id of the objective which disputes the channel.
+func ObjectiveIdFor(channelId types.Destination) protocols.ObjectiveId {
+	return protocols.ObjectiveId(ObjectivePrefix + channelId.String())
+}
+
+// clone returns a deep copy of the receiver.
+func (o *Objective) clone() Objective {
+	clone := *o
+	clone.Candidate = o.Candidate.Clone()
+	return clone
+}
@@ -0,0 +1,160 @@
+package dispute
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/go-cmp/cmp"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/client/engine/chainservice"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+var alice, bob testactors.Actor = testactors.Alice, testactors.Bob
+
+var testState = state.State{
+	ChainId:           big.NewInt(9001),
+	Participants:      []types.Address{alice.Address(), bob.Address()},
+	ChannelNonce:      37140676580,
+	AppDefinition:     common.HexToAddress(`0x5e29E5Ab8EF33F050c7cc10B5a0456D975C5F88d`),
+	ChallengeDuration: 60,
+	AppData:           []byte{},
+	Outcome: outcome.Exit{
+		outcome.SingleAssetExit{
+			Asset: types.Address{},
+			Allocations: outcome.Allocations{
+				outcome.Allocation{Destination: alice.Destination(), Amount: big.NewInt(5)},
+				outcome.Allocation{Destination: bob.Destination(), Amount: big.NewInt(5)},
+			},
+		},
+	},
+	TurnNum: 1,
+	IsFinal: false,
+}
+
+// supported returns the test state signed by both participants.
+func supported(t *testing.T) state.SignedState {
+	ss := state.NewSignedState(testState)
+	for _, pk := range [][]byte{alice.PrivateKey, bob.PrivateKey} {
+		sig, err := testState.Sign(pk)
+		testhelpers.Ok(t, err)
+		testhelpers.Ok(t, ss.AddSignature(sig))
+	}
+	return ss
+}
+
+func TestNew(t *testing.T) {
+	if _, err := NewObjective(state.NewSignedState(testState)); err == nil {
+		t.Fatal("expected an error when disputing with a state which is not supported")
+	}
+	o, err := NewObjective(supported(t))
+	testhelpers.Ok(t, err)
+	if o.GetStatus() != protocols.Approved || o.Id() != ObjectiveIdFor(testState.ChannelId()) {
+		t.Fatalf("expected an approved objective disputing channel %s, got %+v", testState.ChannelId(), o)
+	}
+}
+
+func TestCrank(t *testing.T) {
+	o, err := NewObjective(supported(t))
+	testhelpers.Ok(t, err)
+	channelId := testState.ChannelId()
+
+	// The channel is challenged with our candidate
+	cranked, sideEffects, waitingFor, err := o.Crank(alice.Signer())
+	testhelpers.Ok(t, err)
+	if waitingFor != WaitingForChallenge || len(sideEffects.TransactionsToSubmit) != 1 {
+		t.Fatalf("expected a challenge to be submitted, got %v and %+v", waitingFor, sideEffects.TransactionsToSubmit)
+	}
+	if _, ok := sideEffects.TransactionsToSubmit[0].(protocols.ChallengeTransaction); !ok {
+		t.Fatalf("expected a challenge transaction, got %T", sideEffects.TransactionsToSubmit[0])
+	}
+	_, sideEffects, _, err = cranked.Crank(alice.Signer())
+	testhelpers.Ok(t, err)
+	if len(sideEffects.TransactionsToSubmit) != 0 {
+		t.Fatalf("expected the challenge to be submitted once, got %+v", sideEffects.TransactionsToSubmit)
+	}
+
+	// The challenge is registered, and the objective waits for it to finalize
+	registered := chainservice.NewChallengeRegisteredEvent(channelId, 5, testState.TurnNum, 1000, false)
+	updated, err := cranked.(*Objective).UpdateWithChainEvent(registered)
+	testhelpers.Ok(t, err)
+	_, sideEffects, waitingFor, err = updated.(*Objective).UpdateWithChainTime(999).Crank(alice.Signer())
+	testhelpers.Ok(t, err)
+	if waitingFor != WaitingForFinalization || len(sideEffects.TransactionsToSubmit) != 0 {
+		t.Fatalf("expected the objective to wait for finalization, got %v and %+v", waitingFor, sideEffects.TransactionsToSubmit)
+	}
+
+	// Once the challenge has finalized, the outcome is paid out
+	cranked, sideEffects, waitingFor, err = updated.(*Objective).UpdateWithChainTime(1000).Crank(alice.Signer())
+	testhelpers.Ok(t, err)
+	if waitingFor != WaitingForPayout || len(sideEffects.TransactionsToSubmit) != 1 {
+		t.Fatalf("expected a transferAll to be submitted, got %v and %+v", waitingFor, sideEffects.TransactionsToSubmit)
+	}
+	transfer, ok := sideEffects.TransactionsToSubmit[0].(protocols.TransferAllTransaction)
+	if !ok || !cmp.Equal(transfer.State, testState) {
+		t.Fatalf("expected a transferAll with our candidate, got %+v", sideEffects.TransactionsToSubmit[0])
+	}
+
+	paidOut := chainservice.NewAllocationUpdatedEvent(channelId, 6, common.Address{}, big.NewInt(0))
+	updated, err = cranked.(*Objective).UpdateWithChainEvent(paidOut)
+	testhelpers.Ok(t, err)
+	completed, _, waitingFor, err := updated.Crank(alice.Signer())
+	testhelpers.Ok(t, err)
+	if waitingFor != WaitingForNothing || completed.GetStatus() != protocols.Completed {
+		t.Fatalf("expected the objective to complete, got %v", waitingFor)
+	}
+}
+
+func TestChallengeWithOtherStates(t *testing.T) {
+	o, err := NewObjective(supported(t))
+	testhelpers.Ok(t, err)
+	channelId := testState.ChannelId()
+	cranked, _, _, err := o.Crank(alice.Signer())
+	testhelpers.Ok(t, err)
+
+	// A challenge with an earlier state is superseded by challenging again with ours
+	earlier := chainservice.NewChallengeRegisteredEvent(channelId, 5, testState.TurnNum-1, 1000, false)
+	updated, err := cranked.(*Objective).UpdateWithChainEvent(earlier)
+	testhelpers.Ok(t, err)
+	_, sideEffects, waitingFor, err := updated.Crank(alice.Signer())
+	testhelpers.Ok(t, err)
+	if waitingFor != WaitingForChallenge || len(sideEffects.TransactionsToSubmit) != 1 {
+		t.Fatalf("expected the channel to be challenged again, got %v and %+v", waitingFor, sideEffects.TransactionsToSubmit)
+	}
+
+	// A checkpoint with a later state means our candidate can no longer be paid out
+	cleared := chainservice.NewChallengeClearedEvent(channelId, 6, testState.TurnNum+1)
+	updated, err = updated.(*Objective).UpdateWithChainEvent(cleared)
+	testhelpers.Ok(t, err)
+	if reason, failed := updated.(*Objective).Failure(); !failed || reason.Code != protocols.DisputeRequired {
+		t.Fatalf("expected the objective to fail, got %+v", reason)
+	}
+	_, sideEffects, _, err = updated.Crank(alice.Signer())
+	testhelpers.Ok(t, err)
+	if len(sideEffects.TransactionsToSubmit) != 0 {
+		t.Fatalf("expected no further transactions, got %+v", sideEffects.TransactionsToSubmit)
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	o, err := NewObjective(supported(t))
+	testhelpers.Ok(t, err)
+	registered := chainservice.NewChallengeRegisteredEvent(testState.ChannelId(), 5, testState.TurnNum, 1000, false)
+	updated, err := o.UpdateWithChainEvent(registered)
+	testhelpers.Ok(t, err)
+	want := updated.(*Objective).UpdateWithChainTime(900)
+
+	encoded, err := want.MarshalJSON()
+	testhelpers.Ok(t, err)
+	got := Objective{}
+	testhelpers.Ok(t, got.UnmarshalJSON(encoded))
+
+	if diff := cmp.Diff(*want, got, cmp.AllowUnexported(Objective{}, state.SignedState{}, big.Int{})); diff != "" {
+		t.Fatalf("Objective mismatch after unmarshalling (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,64 @@
+package dispute
+
+import (
+	"encoding/json"
+
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/protocols"
+)
+
+// jsonObjective exposes the dispute.Objective's unexported fields, making jsonObjective suitable for serialization
+type jsonObjective struct {
+	Status             protocols.ObjectiveStatus
+	Candidate          state.SignedState
+	ChallengeSubmitted bool
+	FinalizesAt        uint64
+	ChainTime          uint64
+	TransferSubmitted  bool
+	PaidOut            bool
+	SupersededBy       uint64
+	FailedTransactions uint
+	TransactionFailure string
+}
+
+// MarshalJSON returns a JSON representation of the dispute Objective
+func (o Objective) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonObjective{
+		o.Status,
+		o.Candidate,
+		o.challengeSubmitted,
+		o.finalizesAt,
+		o.chainTime,
+		o.transferSubmitted,
+		o.paidOut,
+		o.supersededBy,
+		o.failedTransactions,
+		o.transactionFailure,
+	})
+}
+
+// UnmarshalJSON populates the calling dispute Objective with the json-encoded data
+func (o *Objective) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var jsonO jsonObjective
+	err := json.Unmarshal(data, &jsonO)
+	if err != nil {
+		return err
+	}
+
+	o.Status = jsonO.Status
+	o.Candidate = jsonO.Candidate
+	o.challengeSubmitted = jsonO.ChallengeSubmitted
+	o.finalizesAt = jsonO.FinalizesAt
+	o.chainTime = jsonO.ChainTime
+	o.transferSubmitted = jsonO.TransferSubmitted
+	o.paidOut = jsonO.PaidOut
+	o.supersededBy = jsonO.SupersededBy
+	o.failedTransactions = jsonO.FailedTransactions
+	o.transactionFailure = jsonO.TransactionFailure
+
+	return nil
+}
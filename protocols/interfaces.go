@@ -3,9 +3,11 @@ package protocols
 import (
 	"encoding/json"
 	"errors"
+	"math/big"
 
 	"github.com/statechannels/go-nitro/channel/consensus_channel"
 	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/crypto"
 	"github.com/statechannels/go-nitro/types"
 )
 
@@ -13,6 +15,9 @@ var (
 	ErrNotApproved = errors.New("objective not approved")
 )
 
+// MaxTransactionAttempts is the number of times an objective submits a chain transaction which fails, before the objective itself fails.
+const MaxTransactionAttempts = 3
+
 // ChainTransaction defines the interface that every transaction must implement
 type ChainTransaction interface {
 	ChannelId() types.Destination
@@ -45,6 +50,39 @@ func NewWithdrawAllTransaction(channelId types.Destination, signedState state.Si
 	return WithdrawAllTransaction{SignedState: signedState, ChainTransaction: ChainTransactionBase{channelId: channelId}}
 }
 
+// ChallengeTransaction registers a challenge with the candidate state, which finalizes the channel unless the challenge is cleared within the channel's challenge duration.
+type ChallengeTransaction struct {
+	ChainTransaction
+	Candidate     state.SignedState
+	Proof         []state.SignedState
+	ChallengerSig crypto.Signature // the challenger's signature on the hash of the candidate and "forceMove"
+}
+
+func NewChallengeTransaction(channelId types.Destination, candidate state.SignedState, proof []state.SignedState, challengerSig crypto.Signature) ChallengeTransaction {
+	return ChallengeTransaction{ChainTransaction: ChainTransactionBase{channelId: channelId}, Candidate: candidate, Proof: proof, ChallengerSig: challengerSig}
+}
+
+// CheckpointTransaction records the candidate state's turn number on chain, clearing any ongoing challenge with an earlier state.
+type CheckpointTransaction struct {
+	ChainTransaction
+	Candidate state.SignedState
+	Proof     []state.SignedState
+}
+
+func NewCheckpointTransaction(channelId types.Destination, candidate state.SignedState, proof []state.SignedState) CheckpointTransaction {
+	return CheckpointTransaction{ChainTransaction: ChainTransactionBase{channelId: channelId}, Candidate: candidate, Proof: proof}
+}
+
+// TransferAllTransaction pays out the outcome of a channel which has finalized with the supplied (challenged) state.
+type TransferAllTransaction struct {
+	ChainTransaction
+	State state.State
+}
+
+func NewTransferAllTransaction(channelId types.Destination, s state.State) TransferAllTransaction {
+	return TransferAllTransaction{ChainTransaction: ChainTransactionBase{channelId: channelId}, State: s}
+}
+
 // SideEffects are effects to be executed by an imperative shell
 type SideEffects struct {
 	MessagesToSend       []Message
@@ -58,9 +96,14 @@ type WaitingFor string
 // AdjudicationStatus mirrors the on chain adjudication status of a particular channel.
 // Everything that is stored on chain, other than holdings.
 type AdjudicationStatus struct {
-	TurnNumRecord uint
-	// TODO This struct is a placeholder for the time being, until we add a chain-service
-	// TODO eventually this struct will contain the other fields stored in (or committed to by) the adjudicator
+	TurnNumRecord uint64
+	FinalizesAt   uint64   // The time (in seconds since the Unix epoch) at which the channel finalizes, or zero if it has not been challenged or concluded
+	Fingerprint   *big.Int // The last 160 bits of the hash of the state hash and outcome hash stored by the adjudicator
+}
+
+// IsFinalized returns true if the channel has finalized on chain by the supplied time (in seconds since the Unix epoch).
+func (as AdjudicationStatus) IsFinalized(now uint64) bool {
+	return as.FinalizesAt != 0 && as.FinalizesAt <= now
 }
 
 // Storable is an object that can be stored by the store.
@@ -79,10 +122,10 @@ type Storable interface {
 type Objective interface {
 	Id() ObjectiveId
 
-	Approve() Objective                                                  // returns an updated Objective (a copy, no mutation allowed), does not declare effects
-	Reject() (Objective, SideEffects)                                    // returns an updated Objective (a copy, no mutation allowed), does not declare effects
-	Update(payload ObjectivePayload) (Objective, error)                  // returns an updated Objective (a copy, no mutation allowed), does not declare effects
-	Crank(secretKey *[]byte) (Objective, SideEffects, WaitingFor, error) // does *not* accept an event, but *does* accept a pointer to a signing key; declare side effects; return an updated Objective
+	Approve() Objective                                                     // returns an updated Objective (a copy, no mutation allowed), does not declare effects
+	Reject(reason RejectionReason) (Objective, SideEffects)                 // returns an updated Objective (a copy, no mutation allowed), declares a rejection notice for the other participants
+	Update(payload ObjectivePayload) (Objective, error)                     // returns an updated Objective (a copy, no mutation allowed), does not declare effects
+	Crank(signer crypto.Signer) (Objective, SideEffects, WaitingFor, error) // does *not* accept an event, but *does* accept a signer; declare side effects; return an updated Objective
 
 	// Related returns a slice of related objects that need to be stored along with the objective
 	Related() []Storable
@@ -90,6 +133,8 @@ type Objective interface {
 
 	// OwnsChannel returns the channel the objective exclusively owns.
 	OwnsChannel() types.Destination
+	// Participants returns the addresses of the participants in the channel the objective owns.
+	Participants() []types.Address
 	// GetStatus returns the status of the objective.
 	GetStatus() ObjectiveStatus
 }
@@ -102,6 +147,31 @@ type ProposalReceiver interface {
 	ReceiveProposal(signedProposal consensus_channel.SignedProposal) (ProposalReceiver, error)
 }
 
+// AbandonableObjective is an Objective that can report whether it is safe to abandon.
+// Objectives that do not implement this interface are never considered safe to abandon.
+type AbandonableObjective interface {
+	Objective
+	// SafeToAbandon returns true if the objective has not yet committed any funds (on chain or in a ledger channel), so that it can be rejected without putting funds at risk.
+	SafeToAbandon() bool
+}
+
+// VersionedObjective is an Objective that records the version of its protocol negotiated by its participants, so that the version
+// survives a restart.
+type VersionedObjective interface {
+	Objective
+	// GetVersion returns the negotiated version of the objective's protocol, or zero if none has been recorded.
+	GetVersion() uint
+	// WithVersion returns a copy of the objective which records the negotiated version of its protocol.
+	WithVersion(version uint) Objective
+}
+
+// FailableObjective is an Objective that can fail of its own accord, for example because its chain transactions keep failing.
+type FailableObjective interface {
+	Objective
+	// Failure returns the reason that the objective can make no further progress, and false if it can still make progress.
+	Failure() (RejectionReason, bool)
+}
+
 // ObjectiveId is a unique identifier for an Objective.
 type ObjectiveId string
 
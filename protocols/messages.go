@@ -3,9 +3,12 @@ package protocols
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"sort"
 
 	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/crypto"
 	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/types"
 )
@@ -19,6 +22,8 @@ type ObjectivePayload struct {
 	// Type is the type of the payload the message contains.
 	// This is useful when a protocol wants to handle different types of payloads.
 	Type PayloadType
+	// Version is the version of the objective's protocol negotiated by its participants. See ObjectiveVersion.
+	Version uint `json:",omitempty"`
 }
 
 type PayloadType string
@@ -37,6 +42,8 @@ func CreateObjectivePayload(id ObjectiveId, payloadType PayloadType, p interface
 // Message is an object to be sent across the wire.
 type Message struct {
 	To types.Address
+	// From is the address of the sender. It must match the address recovered from Signature.
+	From types.Address
 	// ObjectivePayloads contains a collection of payloads for various objectives.
 	// Protocols are responsible for parsing the payload.
 	ObjectivePayloads []ObjectivePayload
@@ -46,8 +53,50 @@ type Message struct {
 	// Payments contains a collection of signed vouchers representing payments.
 	// Payments are handled outside of any objective.
 	Payments []payments.Voucher
-	// RejectedObjectives is a collection of objectives that have been rejected.
-	RejectedObjectives []ObjectiveId
+	// RejectedObjectives is a collection of notices for objectives that have been rejected.
+	RejectedObjectives []RejectionNotice
+	// Versions announces the versions supported by the sender. It is included in the first message sent to each peer.
+	Versions *Versions `json:",omitempty"`
+	// Signature is the sender's signature on the rest of the message, made with their channel key.
+	Signature state.Signature
+}
+
+// RejectionCode is a machine-readable code describing why an objective was rejected.
+type RejectionCode string
+
+const (
+	Unspecified                RejectionCode = "Unspecified"
+	InsufficientLedgerCapacity RejectionCode = "InsufficientLedgerCapacity"
+	CounterpartyNotAllowed     RejectionCode = "CounterpartyNotAllowed"
+	InvalidObjective           RejectionCode = "InvalidObjective"
+	TimedOut                   RejectionCode = "TimedOut"
+	DisputeRequired            RejectionCode = "DisputeRequired"
+	Cancelled                  RejectionCode = "Cancelled"
+	IncompatibleVersion        RejectionCode = "IncompatibleVersion"
+	QuotaExceeded              RejectionCode = "QuotaExceeded"
+	TransactionFailed          RejectionCode = "TransactionFailed"
+	UnsupportedChain           RejectionCode = "UnsupportedChain"
+)
+
+// RejectionReason explains why an objective was rejected.
+type RejectionReason struct {
+	Code RejectionCode
+	// Message is a human-readable explanation to accompany the Code.
+	Message string
+}
+
+// String returns a human-readable representation of the reason.
+func (r RejectionReason) String() string {
+	if r.Message == "" {
+		return string(r.Code)
+	}
+	return fmt.Sprintf("%s: %s", r.Code, r.Message)
+}
+
+// RejectionNotice informs a peer that an objective has been rejected, and why.
+type RejectionNotice struct {
+	ObjectiveId ObjectiveId
+	Reason      RejectionReason
 }
 
 // SortedProposals sorts the proposals by channelId and then by turn number.
@@ -71,6 +120,51 @@ func (m Message) SortedProposals() []consensus_channel.SignedProposal {
 	return signedProposals
 }
 
+// Sign sets the From field of the message to the address of the supplied secret key, and signs the message with that key.
+func (m *Message) Sign(secretKey []byte) error {
+	m.From = crypto.GetAddressFromSecretKeyBytes(secretKey)
+	unsigned, err := m.signingPayload()
+	if err != nil {
+		return err
+	}
+	sig, err := crypto.SignEthereumMessage(unsigned, secretKey)
+	if err != nil {
+		return err
+	}
+	m.Signature = sig
+	return nil
+}
+
+// SignWith sets the From field of the message to the signer's address, and signs the message with the signer.
+func (m *Message) SignWith(signer crypto.Signer) error {
+	m.From = signer.Address()
+	unsigned, err := m.signingPayload()
+	if err != nil {
+		return err
+	}
+	sig, err := signer.SignMessage(unsigned)
+	if err != nil {
+		return err
+	}
+	m.Signature = sig
+	return nil
+}
+
+// RecoverSigner computes the address of the key which signed the message.
+func (m Message) RecoverSigner() (types.Address, error) {
+	unsigned, err := m.signingPayload()
+	if err != nil {
+		return types.Address{}, err
+	}
+	return crypto.RecoverEthereumMessageSigner(unsigned, m.Signature)
+}
+
+// signingPayload returns the serialized message, excluding the signature.
+func (m Message) signingPayload() ([]byte, error) {
+	m.Signature = state.Signature{}
+	return json.Marshal(m)
+}
+
 // Serialize serializes the message into a string.
 func (m Message) Serialize() (string, error) {
 	bytes, err := json.Marshal(m)
@@ -120,12 +214,11 @@ func CreateObjectivePayloadMessage(id ObjectiveId, p interface{}, payloadType Pa
 	return messages
 }
 
-// CreateSignedProposalMessage returns a signed proposal message addressed to the counterparty in the given ledger
-// It contains the provided signed proposals and any proposals in the proposal queue.
-func CreateRejectionNoticeMessage(oId ObjectiveId, recipients ...types.Address) []Message {
+// CreateRejectionNoticeMessage returns a message for each recipient notifying them that the objective has been rejected for the given reason.
+func CreateRejectionNoticeMessage(oId ObjectiveId, reason RejectionReason, recipients ...types.Address) []Message {
 	messages := make([]Message, 0)
 	for _, recipient := range recipients {
-		message := Message{To: recipient, RejectedObjectives: []ObjectiveId{oId}}
+		message := Message{To: recipient, RejectedObjectives: []RejectionNotice{{ObjectiveId: oId, Reason: reason}}}
 		messages = append(messages, message)
 	}
 
@@ -152,6 +245,32 @@ func CreateVoucherMessage(voucher payments.Voucher, recipients ...types.Address)
 	return messages
 }
 
+// BatchMessages combines messages to the same recipient into a single message per recipient.
+// Payloads, proposals, payments and rejections keep the order in which they appear in messages,
+// and recipients appear in the order in which they were first messaged.
+// The returned messages are unsigned.
+func BatchMessages(messages []Message) []Message {
+	batched := []Message{}
+	index := make(map[types.Address]int)
+	for _, m := range messages {
+		i, ok := index[m.To]
+		if !ok {
+			index[m.To] = len(batched)
+			batched = append(batched, Message{To: m.To})
+			i = len(batched) - 1
+		}
+		b := &batched[i]
+		b.ObjectivePayloads = append(b.ObjectivePayloads, m.ObjectivePayloads...)
+		b.LedgerProposals = append(b.LedgerProposals, m.LedgerProposals...)
+		b.Payments = append(b.Payments, m.Payments...)
+		b.RejectedObjectives = append(b.RejectedObjectives, m.RejectedObjectives...)
+		if m.Versions != nil {
+			b.Versions = m.Versions
+		}
+	}
+	return batched
+}
+
 // DeserializeMessage deserializes the passed string into a protocols.Message.
 func DeserializeMessage(s string) (Message, error) {
 	msg := Message{}
@@ -163,6 +282,7 @@ func DeserializeMessage(s string) (Message, error) {
 // MessageSummary is a summary of a message suitable for logging.
 type MessageSummary struct {
 	To               string
+	From             string
 	PayloadSummaries []ObjectivePayloadSummary
 
 	ProposalSummaries []ProposalSummary
@@ -197,6 +317,7 @@ type PaymentSummary struct {
 func (m Message) Summarize() MessageSummary {
 	s := MessageSummary{}
 	s.To = m.To.String()
+	s.From = m.From.String()
 
 	s.PayloadSummaries = make([]ObjectivePayloadSummary, len(m.ObjectivePayloads))
 	for i, p := range m.ObjectivePayloads {
@@ -219,7 +340,7 @@ func (m Message) Summarize() MessageSummary {
 
 	s.RejectedObjectives = make([]string, len(m.RejectedObjectives))
 	for i, o := range m.RejectedObjectives {
-		s.RejectedObjectives[i] = string(o)
+		s.RejectedObjectives[i] = fmt.Sprintf("%s (%s)", o.ObjectiveId, o.Reason.Code)
 	}
 	return s
 }
@@ -8,6 +8,7 @@ import (
 
 	"github.com/statechannels/go-nitro/channel/consensus_channel"
 	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/internal/testactors"
 	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/types"
 )
@@ -50,11 +51,11 @@ func TestMessage(t *testing.T) {
 		}},
 		LedgerProposals:    []consensus_channel.SignedProposal{addProposal(), removeProposal()},
 		Payments:           []payments.Voucher{{ChannelId: types.Destination{'d'}, Amount: big.NewInt(123), Signature: state.Signature{}}},
-		RejectedObjectives: []ObjectiveId{"say-hello-to-my-little-friend2"},
+		RejectedObjectives: []RejectionNotice{{ObjectiveId: "say-hello-to-my-little-friend2", Reason: RejectionReason{Code: InsufficientLedgerCapacity, Message: "not enough funds"}}},
 	}
 
 	msgString :=
-		`{"To":"0x6100000000000000000000000000000000000000","ObjectivePayloads":[{"PayloadData":"eyJTdGF0ZSI6eyJDaGFpbklkIjo5MDAxLCJQYXJ0aWNpcGFudHMiOlsiMHhmNWExYmI1NjA3YzlkMDc5ZTQ2ZDFiM2RjMzNmMjU3ZDkzN2I0M2JkIiwiMHg3NjBiZjI3Y2Q0NTAzNmE2YzQ4NjgwMmQzMGI1ZDkwY2ZmYmUzMWZlIl0sIkNoYW5uZWxOb25jZSI6MzcxNDA2NzY1ODAsIkFwcERlZmluaXRpb24iOiIweDVlMjllNWFiOGVmMzNmMDUwYzdjYzEwYjVhMDQ1NmQ5NzVjNWY4OGQiLCJDaGFsbGVuZ2VEdXJhdGlvbiI6NjAsIkFwcERhdGEiOiIiLCJPdXRjb21lIjpbeyJBc3NldCI6IjB4MDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMCIsIk1ldGFkYXRhIjpudWxsLCJBbGxvY2F0aW9ucyI6W3siRGVzdGluYXRpb24iOiIweDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMGY1YTFiYjU2MDdjOWQwNzllNDZkMWIzZGMzM2YyNTdkOTM3YjQzYmQiLCJBbW91bnQiOjUsIkFsbG9jYXRpb25UeXBlIjowLCJNZXRhZGF0YSI6bnVsbH0seyJEZXN0aW5hdGlvbiI6IjB4MDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwZWUxOGZmMTU3NTA1NTY5MTAwOWFhMjQ2YWU2MDgxMzJjNTdhNDIyYyIsIkFtb3VudCI6NSwiQWxsb2NhdGlvblR5cGUiOjAsIk1ldGFkYXRhIjpudWxsfV19XSwiVHVybk51bSI6NSwiSXNGaW5hbCI6ZmFsc2V9LCJTaWdzIjp7fX0=","ObjectiveId":"say-hello-to-my-little-friend","Type":""}],"LedgerProposals":[{"R":null,"S":null,"V":0,"Proposal":{"LedgerID":"0x6c00000000000000000000000000000000000000000000000000000000000000","ToAdd":{"Guarantee":{"Amount":1,"Target":"0x6100000000000000000000000000000000000000000000000000000000000000","Left":"0x6200000000000000000000000000000000000000000000000000000000000000","Right":"0x6300000000000000000000000000000000000000000000000000000000000000"},"LeftDeposit":1},"ToRemove":{"Target":"0x0000000000000000000000000000000000000000000000000000000000000000","LeftAmount":null}},"TurnNum":0},{"R":null,"S":null,"V":0,"Proposal":{"LedgerID":"0x6c00000000000000000000000000000000000000000000000000000000000000","ToAdd":{"Guarantee":{"Amount":null,"Target":"0x0000000000000000000000000000000000000000000000000000000000000000","Left":"0x0000000000000000000000000000000000000000000000000000000000000000","Right":"0x0000000000000000000000000000000000000000000000000000000000000000"},"LeftDeposit":null},"ToRemove":{"Target":"0x6100000000000000000000000000000000000000000000000000000000000000","LeftAmount":1}},"TurnNum":0}],"Payments":[{"ChannelId":"0x6400000000000000000000000000000000000000000000000000000000000000","Amount":123,"Signature":{"R":null,"S":null,"V":0}}],"RejectedObjectives":["say-hello-to-my-little-friend2"]}`
+		`{"To":"0x6100000000000000000000000000000000000000","From":"0x0000000000000000000000000000000000000000","ObjectivePayloads":[{"PayloadData":"eyJTdGF0ZSI6eyJDaGFpbklkIjo5MDAxLCJQYXJ0aWNpcGFudHMiOlsiMHhmNWExYmI1NjA3YzlkMDc5ZTQ2ZDFiM2RjMzNmMjU3ZDkzN2I0M2JkIiwiMHg3NjBiZjI3Y2Q0NTAzNmE2YzQ4NjgwMmQzMGI1ZDkwY2ZmYmUzMWZlIl0sIkNoYW5uZWxOb25jZSI6MzcxNDA2NzY1ODAsIkFwcERlZmluaXRpb24iOiIweDVlMjllNWFiOGVmMzNmMDUwYzdjYzEwYjVhMDQ1NmQ5NzVjNWY4OGQiLCJDaGFsbGVuZ2VEdXJhdGlvbiI6NjAsIkFwcERhdGEiOiIiLCJPdXRjb21lIjpbeyJBc3NldCI6IjB4MDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMCIsIk1ldGFkYXRhIjpudWxsLCJBbGxvY2F0aW9ucyI6W3siRGVzdGluYXRpb24iOiIweDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMGY1YTFiYjU2MDdjOWQwNzllNDZkMWIzZGMzM2YyNTdkOTM3YjQzYmQiLCJBbW91bnQiOjUsIkFsbG9jYXRpb25UeXBlIjowLCJNZXRhZGF0YSI6bnVsbH0seyJEZXN0aW5hdGlvbiI6IjB4MDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwZWUxOGZmMTU3NTA1NTY5MTAwOWFhMjQ2YWU2MDgxMzJjNTdhNDIyYyIsIkFtb3VudCI6NSwiQWxsb2NhdGlvblR5cGUiOjAsIk1ldGFkYXRhIjpudWxsfV19XSwiVHVybk51bSI6NSwiSXNGaW5hbCI6ZmFsc2V9LCJTaWdzIjp7fX0=","ObjectiveId":"say-hello-to-my-little-friend","Type":""}],"LedgerProposals":[{"R":null,"S":null,"V":0,"Proposal":{"LedgerID":"0x6c00000000000000000000000000000000000000000000000000000000000000","ToAdd":{"Guarantee":{"Amount":1,"Target":"0x6100000000000000000000000000000000000000000000000000000000000000","Left":"0x6200000000000000000000000000000000000000000000000000000000000000","Right":"0x6300000000000000000000000000000000000000000000000000000000000000"},"LeftDeposit":1},"ToRemove":{"Target":"0x0000000000000000000000000000000000000000000000000000000000000000","LeftAmount":null}},"TurnNum":0},{"R":null,"S":null,"V":0,"Proposal":{"LedgerID":"0x6c00000000000000000000000000000000000000000000000000000000000000","ToAdd":{"Guarantee":{"Amount":null,"Target":"0x0000000000000000000000000000000000000000000000000000000000000000","Left":"0x0000000000000000000000000000000000000000000000000000000000000000","Right":"0x0000000000000000000000000000000000000000000000000000000000000000"},"LeftDeposit":null},"ToRemove":{"Target":"0x6100000000000000000000000000000000000000000000000000000000000000","LeftAmount":1}},"TurnNum":0}],"Payments":[{"ChannelId":"0x6400000000000000000000000000000000000000000000000000000000000000","Amount":123,"Signature":{"R":null,"S":null,"V":0}}],"RejectedObjectives":[{"ObjectiveId":"say-hello-to-my-little-friend2","Reason":{"Code":"InsufficientLedgerCapacity","Message":"not enough funds"}}],"Signature":{"R":null,"S":null,"V":0}}`
 
 	t.Run(`serialize`, func(t *testing.T) {
 		got, err := msg.Serialize()
@@ -78,4 +79,216 @@ func TestMessage(t *testing.T) {
 		}
 	})
 
+	t.Run(`sign and recover`, func(t *testing.T) {
+		signed := msg
+		err := signed.Sign(testactors.Alice.PrivateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if signed.From != testactors.Alice.Address() {
+			t.Fatalf("expected From to be %s, got %s", testactors.Alice.Address(), signed.From)
+		}
+
+		// The signature should survive a serialization round trip
+		raw, err := signed.Serialize()
+		if err != nil {
+			t.Fatal(err)
+		}
+		received, err := DeserializeMessage(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signer, err := received.RecoverSigner()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if signer != testactors.Alice.Address() {
+			t.Fatalf("expected signer to be %s, got %s", testactors.Alice.Address(), signer)
+		}
+
+		// Tampering with the message should change the recovered signer
+		received.RejectedObjectives = nil
+		signer, _ = received.RecoverSigner()
+		if signer == testactors.Alice.Address() {
+			t.Fatal("expected a tampered message not to recover to the original signer")
+		}
+	})
+
+	t.Run(`binary round trip`, func(t *testing.T) {
+		encoded, err := msg.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if encoded[0] != BinaryFormatVersion {
+			t.Fatalf("expected the encoding to start with version %d, got %d", BinaryFormatVersion, encoded[0])
+		}
+		got := Message{}
+		err = got.UnmarshalBinary(encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Decoded messages must serialize to exactly the same JSON, since that is what the message signature covers
+		gotString, _ := got.Serialize()
+		if gotString != msgString {
+			t.Fatalf("incorrect binary round trip: got:\n%v\nwanted:\n%v", gotString, msgString)
+		}
+
+		if err := got.UnmarshalBinary(encoded[:len(encoded)-1]); err == nil {
+			t.Fatal("expected truncated data to fail to decode")
+		}
+		encoded[0] = BinaryFormatVersion + 1
+		if err := got.UnmarshalBinary(encoded); err == nil {
+			t.Fatal("expected an unknown version to fail to decode")
+		}
+	})
+
+	t.Run(`binary round trip preserves signatures`, func(t *testing.T) {
+		signed := benchmarkMessage(t)
+		encoded, err := signed.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := Message{}
+		err = got.UnmarshalBinary(encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signer, err := got.RecoverSigner()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if signer != testactors.Alice.Address() {
+			t.Fatalf("expected signer to be %s, got %s", testactors.Alice.Address(), signer)
+		}
+		raw, _ := signed.Serialize()
+		if len(encoded) >= len(raw) {
+			t.Fatalf("expected the binary encoding (%d bytes) to be smaller than JSON (%d bytes)", len(encoded), len(raw))
+		}
+	})
+
+	t.Run(`binary round trip preserves versions`, func(t *testing.T) {
+		versioned := benchmarkMessage(t)
+		versioned.ObjectivePayloads[0].Version = 2
+		versioned.Versions = &Versions{Protocol: ProtocolVersion, Objectives: map[string][]uint{"VirtualFund-": {1, 2}, "DirectFunding-": {1}}}
+		if err := versioned.Sign(testactors.Alice.PrivateKey); err != nil {
+			t.Fatal(err)
+		}
+		encoded, err := versioned.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := Message{}
+		err = got.UnmarshalBinary(encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(versioned.Versions, got.Versions) {
+			t.Fatalf("incorrect versions: got %+v, wanted %+v", got.Versions, versioned.Versions)
+		}
+		if got.ObjectivePayloads[0].Version != 2 {
+			t.Fatalf("expected payload version 2, got %d", got.ObjectivePayloads[0].Version)
+		}
+		signer, _ := got.RecoverSigner()
+		if signer != testactors.Alice.Address() {
+			t.Fatalf("expected signer to be %s, got %s", testactors.Alice.Address(), signer)
+		}
+	})
+}
+
+func TestBatchMessages(t *testing.T) {
+	alice, bob := types.Address{'a'}, types.Address{'b'}
+	payload := func(id ObjectiveId) ObjectivePayload { return ObjectivePayload{ObjectiveId: id} }
+	voucher := payments.Voucher{ChannelId: types.Destination{'d'}, Amount: big.NewInt(1)}
+
+	messages := []Message{
+		{To: bob, ObjectivePayloads: []ObjectivePayload{payload("1")}},
+		{To: alice, LedgerProposals: []consensus_channel.SignedProposal{addProposal()}},
+		{To: bob, LedgerProposals: []consensus_channel.SignedProposal{addProposal(), removeProposal()}},
+		{To: bob, ObjectivePayloads: []ObjectivePayload{payload("2")}, Payments: []payments.Voucher{voucher}},
+		{To: alice, RejectedObjectives: []RejectionNotice{{ObjectiveId: "3"}}},
+	}
+
+	want := []Message{
+		{
+			To:                bob,
+			ObjectivePayloads: []ObjectivePayload{payload("1"), payload("2")},
+			LedgerProposals:   []consensus_channel.SignedProposal{addProposal(), removeProposal()},
+			Payments:          []payments.Voucher{voucher},
+		},
+		{
+			To:                 alice,
+			LedgerProposals:    []consensus_channel.SignedProposal{addProposal()},
+			RejectedObjectives: []RejectionNotice{{ObjectiveId: "3"}},
+		},
+	}
+
+	got := BatchMessages(messages)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("incorrect batching: got:\n%+v\nwanted:\n%+v", got, want)
+	}
+	if len(BatchMessages(nil)) != 0 {
+		t.Fatal("expected no messages to be batched into no messages")
+	}
+}
+
+// benchmarkMessage returns a signed message typical of those exchanged while funding a virtual channel:
+// a fully signed state, two ledger proposals and a voucher.
+func benchmarkMessage(tb testing.TB) Message {
+	ss := state.NewSignedState(state.TestState)
+	for _, pk := range [][]byte{testactors.Alice.PrivateKey, testactors.Bob.PrivateKey} {
+		sig, err := state.TestState.Sign(pk)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		_ = ss.AddSignature(sig)
+	}
+	proposals := []consensus_channel.SignedProposal{addProposal(), removeProposal()}
+	for i := range proposals {
+		proposals[i].Signature = ss.Signatures()[0]
+	}
+	voucher := payments.Voucher{ChannelId: types.Destination{'d'}, Amount: big.NewInt(123)}
+	_ = voucher.Sign(testactors.Alice.PrivateKey)
+
+	msg := Message{
+		To:                testactors.Bob.Address(),
+		ObjectivePayloads: []ObjectivePayload{CreateObjectivePayload("VirtualFund-0x01", "SignedStatePayload", ss)},
+		LedgerProposals:   proposals,
+		Payments:          []payments.Voucher{voucher},
+	}
+	err := msg.Sign(testactors.Alice.PrivateKey)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return msg
+}
+
+func BenchmarkMessageEncoding(b *testing.B) {
+	msg := benchmarkMessage(b)
+
+	b.Run("json/encode", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			raw, _ := msg.Serialize()
+			b.ReportMetric(float64(len(raw)), "bytes/msg")
+		}
+	})
+	b.Run("binary/encode", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			raw, _ := msg.MarshalBinary()
+			b.ReportMetric(float64(len(raw)), "bytes/msg")
+		}
+	})
+
+	rawJSON, _ := msg.Serialize()
+	rawBinary, _ := msg.MarshalBinary()
+	b.Run("json/decode", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = DeserializeMessage(rawJSON)
+		}
+	})
+	b.Run("binary/decode", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			m := Message{}
+			_ = m.UnmarshalBinary(rawBinary)
+		}
+	})
 }
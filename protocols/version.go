@@ -0,0 +1,80 @@
+package protocols
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ProtocolVersion is the version of the messaging protocol spoken by this node: how messages are structured, signed and handled.
+// Nodes only exchange messages with peers which speak the same protocol version.
+const ProtocolVersion uint = 1
+
+// DefaultObjectiveVersion is the version of an objective whose payloads do not specify one, as sent by nodes which predate versioning.
+const DefaultObjectiveVersion uint = 1
+
+// Versions describes the protocol and objective versions supported by a node.
+// Nodes announce their Versions to each peer with the first message they send it.
+type Versions struct {
+	Protocol uint
+	// Objectives maps each type of objective (identified by its ObjectiveId prefix, e.g. "DirectFunding-") to the versions of it supported.
+	Objectives map[string][]uint
+}
+
+// Supports returns true if the given version of the objective type is supported.
+func (v Versions) Supports(objectiveType string, version uint) bool {
+	for _, supported := range v.Objectives[objectiveType] {
+		if supported == version {
+			return true
+		}
+	}
+	return false
+}
+
+// Negotiate returns the highest version of the objective type supported by v and by every one of the peers.
+// An error is returned if a peer speaks a different protocol version, or if there is no commonly supported version.
+func (v Versions) Negotiate(objectiveType string, peers ...Versions) (uint, error) {
+	for _, peer := range peers {
+		if peer.Protocol != v.Protocol {
+			return 0, fmt.Errorf("peer speaks protocol version %d, but we speak version %d", peer.Protocol, v.Protocol)
+		}
+	}
+
+	best, found := uint(0), false
+	for _, candidate := range v.Objectives[objectiveType] {
+		if found && candidate <= best {
+			continue
+		}
+		supportedByAll := true
+		for _, peer := range peers {
+			if !peer.Supports(objectiveType, candidate) {
+				supportedByAll = false
+				break
+			}
+		}
+		if supportedByAll {
+			best, found = candidate, true
+		}
+	}
+
+	if !found {
+		return 0, fmt.Errorf("no version of %s objectives is supported by all participants", strings.TrimSuffix(objectiveType, "-"))
+	}
+	return best, nil
+}
+
+// ObjectiveType returns the type of the objective with the given id, which is the prefix of the id up to and including the first "-".
+func ObjectiveType(id ObjectiveId) string {
+	prefix, _, found := strings.Cut(string(id), "-")
+	if !found {
+		return ""
+	}
+	return prefix + "-"
+}
+
+// ObjectiveVersion returns the version of the objective protocol the payload was created under.
+func (p ObjectivePayload) ObjectiveVersion() uint {
+	if p.Version == 0 {
+		return DefaultObjectiveVersion
+	}
+	return p.Version
+}
@@ -0,0 +1,53 @@
+package protocols
+
+import "testing"
+
+func TestNegotiate(t *testing.T) {
+	ours := Versions{Protocol: 1, Objectives: map[string][]uint{"DirectFunding-": {1, 2, 3}}}
+
+	tests := []struct {
+		name    string
+		peers   []Versions
+		want    uint
+		wantErr bool
+	}{
+		{"no known peers", nil, 3, false},
+		{"highest common version", []Versions{
+			{Protocol: 1, Objectives: map[string][]uint{"DirectFunding-": {1, 2}}},
+			{Protocol: 1, Objectives: map[string][]uint{"DirectFunding-": {2, 3}}},
+		}, 2, false},
+		{"no common version", []Versions{
+			{Protocol: 1, Objectives: map[string][]uint{"DirectFunding-": {4}}},
+		}, 0, true},
+		{"objective type not supported", []Versions{
+			{Protocol: 1, Objectives: map[string][]uint{"VirtualFund-": {1}}},
+		}, 0, true},
+		{"different protocol version", []Versions{
+			{Protocol: 2, Objectives: map[string][]uint{"DirectFunding-": {1, 2, 3}}},
+		}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ours.Negotiate("DirectFunding-", tt.peers...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error: %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Fatalf("expected version %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestObjectiveType(t *testing.T) {
+	if got := ObjectiveType("DirectFunding-0x1234"); got != "DirectFunding-" {
+		t.Fatalf("expected DirectFunding-, got %s", got)
+	}
+	if got := ObjectiveType("say-hello-to-my-little-friend"); got != "say-" {
+		t.Fatalf("expected say-, got %s", got)
+	}
+	if got := ObjectiveType("unprefixed"); got != "" {
+		t.Fatalf("expected no type, got %s", got)
+	}
+}
@@ -51,11 +51,11 @@ func generateStoreGetters(myRole uint, vId types.Destination, vFinal state.State
 		}
 		return c, true
 	}
-	fun2 := func(address types.Address) (*consensus_channel.ConsensusChannel, bool) {
-		if left != nil && (left.Participants()[0] == address || left.Participants()[1] == address) {
+	fun2 := func(address types.Address, chainId *big.Int) (*consensus_channel.ConsensusChannel, bool) {
+		if left != nil && left.FixedPart().ChainId.Cmp(chainId) == 0 && (left.Participants()[0] == address || left.Participants()[1] == address) {
 			return left, true
 		}
-		if right != nil && (right.Participants()[0] == address || right.Participants()[1] == address) {
+		if right != nil && right.FixedPart().ChainId.Cmp(chainId) == 0 && (right.Participants()[0] == address || right.Participants()[1] == address) {
 			return right, true
 		}
 		return &consensus_channel.ConsensusChannel{}, false
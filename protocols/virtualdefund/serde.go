@@ -15,6 +15,7 @@ import (
 // with the channel's respective IDs, making jsonObjective suitable for serialization
 type jsonObjective struct {
 	Status         protocols.ObjectiveStatus
+	Version        uint
 	VFixed         state.FixedPart
 	InitialOutcome outcome.SingleAssetExit
 	FinalOutcome   outcome.SingleAssetExit
@@ -54,6 +55,7 @@ func (o Objective) MarshalJSON() ([]byte, error) {
 
 	jsonVFO := jsonObjective{
 		Status:               o.Status,
+		Version:              o.Version,
 		VFixed:               o.VFixed,
 		Signatures:           o.Signatures,
 		FinalOutcome:         o.FinalOutcome,
@@ -88,6 +90,7 @@ func (o *Objective) UnmarshalJSON(data []byte) error {
 	}
 
 	o.Status = jsonVFO.Status
+	o.Version = jsonVFO.Version
 
 	o.MyRole = jsonVFO.MyRole
 	o.Signatures = jsonVFO.Signatures
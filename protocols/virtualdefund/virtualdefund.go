@@ -12,6 +12,7 @@ import (
 	"github.com/statechannels/go-nitro/channel/consensus_channel"
 	"github.com/statechannels/go-nitro/channel/state"
 	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/crypto"
 	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/types"
 )
@@ -36,7 +37,8 @@ const FinalTurnNum = 2
 
 // Objective contains relevant information for the defund objective
 type Objective struct {
-	Status protocols.ObjectiveStatus
+	Status  protocols.ObjectiveStatus
+	Version uint // the version of the objective's protocol negotiated by its participants, or zero if none has been recorded
 
 	// InitialOutcome is the initial outcome of the virtual channel
 	InitialOutcome outcome.SingleAssetExit
@@ -74,9 +76,9 @@ const ObjectivePrefix = "VirtualDefund-"
 // GetChannelByIdFunction specifies a function that can be used to retrieve channels from a store.
 type GetChannelByIdFunction func(id types.Destination) (channel *channel.Channel, ok bool)
 
-// GetTwoPartyConsensusLedgerFuncion describes functions which return a ConsensusChannel ledger channel between
+// GetTwoPartyConsensusLedgerFuncion describes functions which return a ConsensusChannel ledger channel on the given chain between
 // the calling client and the given counterparty, if such a channel exists.
-type GetTwoPartyConsensusLedgerFunction func(counterparty types.Address) (ledger *consensus_channel.ConsensusChannel, ok bool)
+type GetTwoPartyConsensusLedgerFunction func(counterparty types.Address, chainId *big.Int) (ledger *consensus_channel.ConsensusChannel, ok bool)
 
 // NewObjective constructs a new virtual defund objective
 func NewObjective(request ObjectiveRequest,
@@ -108,13 +110,13 @@ func NewObjective(request ObjectiveRequest,
 
 	if myAddress == alice {
 		rightOfAlice := V.Participants[1]
-		rightLedger, ok = getConsensusChannel(rightOfAlice)
+		rightLedger, ok = getConsensusChannel(rightOfAlice, V.ChainId)
 		if !ok {
 			return Objective{}, fmt.Errorf("could not find a ledger channel between %v and %v", alice, rightOfAlice)
 		}
 	} else if myAddress == bob {
 		leftOfBob := V.Participants[len(V.Participants)-2]
-		leftLedger, ok = getConsensusChannel(leftOfBob)
+		leftLedger, ok = getConsensusChannel(leftOfBob, V.ChainId)
 		if !ok {
 			return Objective{}, fmt.Errorf("could not find a ledger channel between %v and %v", leftOfBob, bob)
 		}
@@ -131,11 +133,11 @@ func NewObjective(request ObjectiveRequest,
 				leftOfMe := V.Participants[p-1]
 				rightOfMe := V.Participants[p+1]
 
-				leftLedger, ok = getConsensusChannel(leftOfMe)
+				leftLedger, ok = getConsensusChannel(leftOfMe, V.ChainId)
 				if !ok {
 					return Objective{}, fmt.Errorf("could not find a ledger channel between %v and %v", leftOfMe, myAddress)
 				}
-				rightLedger, ok = getConsensusChannel(bob)
+				rightLedger, ok = getConsensusChannel(bob, V.ChainId)
 				if !ok {
 					return Objective{}, fmt.Errorf("could not find a ledger channel between %v and %v", myAddress, rightOfMe)
 				}
@@ -279,7 +281,7 @@ func (o *Objective) Approve() protocols.Objective {
 }
 
 // Reject returns a rejected copy of the objective.
-func (o *Objective) Reject() (protocols.Objective, protocols.SideEffects) {
+func (o *Objective) Reject(reason protocols.RejectionReason) (protocols.Objective, protocols.SideEffects) {
 	updated := o.clone()
 	updated.Status = protocols.Rejected
 	peers := []common.Address{}
@@ -288,7 +290,7 @@ func (o *Objective) Reject() (protocols.Objective, protocols.SideEffects) {
 			peers = append(peers, peer)
 		}
 	}
-	messages := protocols.CreateRejectionNoticeMessage(o.Id(), peers...)
+	messages := protocols.CreateRejectionNoticeMessage(o.Id(), reason, peers...)
 
 	return &updated, protocols.SideEffects{MessagesToSend: messages}
 }
@@ -298,11 +300,28 @@ func (o *Objective) OwnsChannel() types.Destination {
 	return o.VId()
 }
 
+// Participants returns the participants in the virtual channel being defunded.
+func (o *Objective) Participants() []types.Address {
+	return o.VFixed.Participants
+}
+
 // GetStatus returns the status of the objective.
 func (o *Objective) GetStatus() protocols.ObjectiveStatus {
 	return o.Status
 }
 
+// GetVersion returns the version of the objective's protocol negotiated by its participants, or zero if none has been recorded.
+func (o *Objective) GetVersion() uint {
+	return o.Version
+}
+
+// WithVersion returns a copy of the objective which records the negotiated version of its protocol.
+func (o *Objective) WithVersion(version uint) protocols.Objective {
+	updated := o.clone()
+	updated.Version = version
+	return &updated
+}
+
 // Related returns channels that need to be stored along with the objective.
 func (o *Objective) Related() []protocols.Storable {
 	related := []protocols.Storable{}
@@ -321,6 +340,7 @@ func (o *Objective) Related() []protocols.Storable {
 func (o *Objective) clone() Objective {
 	clone := Objective{}
 	clone.Status = o.Status
+	clone.Version = o.Version
 
 	clone.VFixed = o.VFixed.Clone()
 	clone.InitialOutcome = o.InitialOutcome.Clone()
@@ -362,7 +382,7 @@ func (o *Objective) hasFinalStateFromAlice() bool {
 }
 
 // Crank inspects the extended state and declares a list of Effects to be executed.
-func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.SideEffects, protocols.WaitingFor, error) {
+func (o *Objective) Crank(signer crypto.Signer) (protocols.Objective, protocols.SideEffects, protocols.WaitingFor, error) {
 	updated := o.clone()
 	sideEffects := protocols.SideEffects{}
 
@@ -382,7 +402,7 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 	// Signing of the final state
 	if !updated.signedByMe() {
 
-		sig, err := o.finalState().Sign(*secretKey)
+		sig, err := o.finalState().SignWith(signer)
 		if err != nil {
 			return &updated, sideEffects, WaitingForNothing, fmt.Errorf("could not sign final state: %w", err)
 		}
@@ -403,7 +423,7 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 	}
 
 	if !updated.isAlice() && !updated.leftHasDefunded() {
-		ledgerSideEffects, err := updated.updateLedgerToRemoveGuarantee(updated.ToMyLeft, secretKey)
+		ledgerSideEffects, err := updated.updateLedgerToRemoveGuarantee(updated.ToMyLeft, signer)
 		if err != nil {
 			return o, protocols.SideEffects{}, WaitingForNothing, fmt.Errorf("error updating ledger funding: %w", err)
 		}
@@ -411,7 +431,7 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 	}
 
 	if !updated.isBob() && !updated.rightHasDefunded() {
-		ledgerSideEffects, err := updated.updateLedgerToRemoveGuarantee(updated.ToMyRight, secretKey)
+		ledgerSideEffects, err := updated.updateLedgerToRemoveGuarantee(updated.ToMyRight, signer)
 		if err != nil {
 			return o, protocols.SideEffects{}, WaitingForNothing, fmt.Errorf("error updating ledger funding: %w", err)
 		}
@@ -460,7 +480,7 @@ func (o *Objective) ledgerProposal(ledger *consensus_channel.ConsensusChannel) c
 }
 
 // updateLedgerToRemoveGuarantee updates the ledger channel to remove the guarantee that funds V.
-func (o *Objective) updateLedgerToRemoveGuarantee(ledger *consensus_channel.ConsensusChannel, sk *[]byte) (protocols.SideEffects, error) {
+func (o *Objective) updateLedgerToRemoveGuarantee(ledger *consensus_channel.ConsensusChannel, signer crypto.Signer) (protocols.SideEffects, error) {
 
 	var sideEffects protocols.SideEffects
 
@@ -471,7 +491,7 @@ func (o *Objective) updateLedgerToRemoveGuarantee(ledger *consensus_channel.Cons
 			return protocols.SideEffects{}, nil
 		}
 
-		_, err := ledger.Propose(o.ledgerProposal(ledger), *sk)
+		_, err := ledger.Propose(o.ledgerProposal(ledger), signer)
 		if err != nil {
 			return protocols.SideEffects{}, fmt.Errorf("error proposing ledger update: %w", err)
 		}
@@ -483,7 +503,7 @@ func (o *Objective) updateLedgerToRemoveGuarantee(ledger *consensus_channel.Cons
 		// If the proposal is next in the queue we accept it
 		proposedNext := ledger.HasRemovalBeenProposedNext(o.VId())
 		if proposedNext {
-			sp, err := ledger.SignNextProposal(o.ledgerProposal(ledger), *sk)
+			sp, err := ledger.SignNextProposal(o.ledgerProposal(ledger), signer)
 
 			if err != nil {
 				return protocols.SideEffects{}, fmt.Errorf("could not sign proposal: %w", err)
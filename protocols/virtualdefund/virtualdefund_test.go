@@ -117,7 +117,7 @@ func testCrankAs(my ta.Actor) func(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		updatedObj, se, waitingFor, err := virtualDefund.Crank(&my.PrivateKey)
+		updatedObj, se, waitingFor, err := virtualDefund.Crank(my.Signer())
 		testhelpers.Ok(t, err)
 		updated := updatedObj.(*Objective)
 
@@ -127,7 +127,7 @@ func testCrankAs(my ta.Actor) func(t *testing.T) {
 
 			// mimic Alice sending the final state by setting PaidToBob to the paid value
 			updated.FinalOutcome = data.vFinal.Outcome[0]
-			updatedObj, se, waitingFor, err = updated.Crank(&my.PrivateKey)
+			updatedObj, se, waitingFor, err = updated.Crank(my.Signer())
 			testhelpers.Ok(t, err)
 			updated = updatedObj.(*Objective)
 		}
@@ -154,7 +154,7 @@ func testCrankAs(my ta.Actor) func(t *testing.T) {
 			}
 		}
 
-		updatedObj, se, waitingFor, err = updated.Crank(&my.PrivateKey)
+		updatedObj, se, waitingFor, err = updated.Crank(my.Signer())
 		updated = updatedObj.(*Objective)
 		testhelpers.Ok(t, err)
 
@@ -170,7 +170,7 @@ func testCrankAs(my ta.Actor) func(t *testing.T) {
 			updated = updatedObj.(*Objective)
 		}
 
-		updatedObj, se, waitingFor, err = updated.Crank(&my.PrivateKey)
+		updatedObj, se, waitingFor, err = updated.Crank(my.Signer())
 		updated = updatedObj.(*Objective)
 		testhelpers.Ok(t, err)
 
@@ -229,11 +229,17 @@ func TestApproveReject(t *testing.T) {
 	if approved.GetStatus() != protocols.Approved {
 		t.Errorf("Expected approved status, got %v", approved.GetStatus())
 	}
-	rejected, sideEffects := virtualDefund.Reject()
+	reason := protocols.RejectionReason{Code: protocols.CounterpartyNotAllowed, Message: "test"}
+	rejected, sideEffects := virtualDefund.Reject(reason)
 	if rejected.GetStatus() != protocols.Rejected {
 		t.Errorf("Expected rejceted status, got %v", approved.GetStatus())
 	}
 	if len(sideEffects.MessagesToSend) != 2 {
 		t.Errorf("Expected to send 2 messages")
 	}
+	for _, msg := range sideEffects.MessagesToSend {
+		if len(msg.RejectedObjectives) != 1 || msg.RejectedObjectives[0].Reason != reason {
+			t.Errorf("Expected rejection notice with reason %v, got %v", reason, msg.RejectedObjectives)
+		}
+	}
 }
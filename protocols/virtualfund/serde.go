@@ -62,8 +62,9 @@ func (c *Connection) UnmarshalJSON(data []byte) error {
 // jsonObjective replaces the virtualfund Objective's channel pointers
 // with the channel's respective IDs, making jsonObjective suitable for serialization
 type jsonObjective struct {
-	Status protocols.ObjectiveStatus
-	V      types.Destination
+	Status  protocols.ObjectiveStatus
+	Version uint
+	V       types.Destination
 
 	ToMyLeft  []byte
 	ToMyRight []byte
@@ -105,6 +106,7 @@ func (o Objective) MarshalJSON() ([]byte, error) {
 
 	jsonVFO := jsonObjective{
 		o.Status,
+		o.Version,
 		o.V.Id,
 		left,
 		right,
@@ -156,6 +158,7 @@ func (o *Objective) UnmarshalJSON(data []byte) error {
 	}
 
 	o.Status = jsonVFO.Status
+	o.Version = jsonVFO.Version
 	o.n = jsonVFO.N
 	o.MyRole = jsonVFO.MyRole
 	o.a0 = jsonVFO.A0
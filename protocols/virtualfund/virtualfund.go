@@ -14,6 +14,7 @@ import (
 	"github.com/statechannels/go-nitro/channel/state"
 	"github.com/statechannels/go-nitro/channel/state/outcome"
 
+	"github.com/statechannels/go-nitro/crypto"
 	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/types"
 )
@@ -121,8 +122,9 @@ func (c *Connection) getExpectedGuarantee() consensus_channel.Guarantee {
 
 // Objective is a cache of data computed by reading from the store. It stores (potentially) infinite data.
 type Objective struct {
-	Status protocols.ObjectiveStatus
-	V      *channel.VirtualChannel
+	Status  protocols.ObjectiveStatus
+	Version uint // the version of the objective's protocol negotiated by its participants, or zero if none has been recorded
+	V       *channel.VirtualChannel
 
 	ToMyLeft  *Connection
 	ToMyRight *Connection
@@ -141,9 +143,9 @@ func NewObjective(request ObjectiveRequest, preApprove bool, myAddress types.Add
 	ok := false
 
 	if len(request.Intermediaries) > 0 {
-		rightCC, ok = getTwoPartyConsensusLedger(request.Intermediaries[0])
+		rightCC, ok = getTwoPartyConsensusLedger(request.Intermediaries[0], request.ChainId)
 	} else {
-		rightCC, ok = getTwoPartyConsensusLedger(request.CounterParty)
+		rightCC, ok = getTwoPartyConsensusLedger(request.CounterParty, request.ChainId)
 	}
 
 	if !ok {
@@ -157,7 +159,7 @@ func NewObjective(request ObjectiveRequest, preApprove bool, myAddress types.Add
 
 	objective, err := constructFromState(preApprove,
 		state.State{
-			ChainId:           big.NewInt(9001), // TODO https://github.com/statechannels/go-nitro/issues/601
+			ChainId:           request.ChainId,
 			Participants:      participants,
 			ChannelNonce:      request.Nonce,
 			ChallengeDuration: request.ChallengeDuration,
@@ -297,11 +299,11 @@ func (o *Objective) Approve() protocols.Objective {
 }
 
 // Reject returns a rejected copy of the objective.
-func (o *Objective) Reject() (protocols.Objective, protocols.SideEffects) {
+func (o *Objective) Reject(reason protocols.RejectionReason) (protocols.Objective, protocols.SideEffects) {
 	updated := o.clone()
 	updated.Status = protocols.Rejected
 
-	messages := protocols.CreateRejectionNoticeMessage(o.Id(), o.otherParticipants()...)
+	messages := protocols.CreateRejectionNoticeMessage(o.Id(), reason, o.otherParticipants()...)
 	sideEffects := protocols.SideEffects{MessagesToSend: messages}
 	return &updated, sideEffects
 }
@@ -311,11 +313,46 @@ func (o *Objective) OwnsChannel() types.Destination {
 	return o.V.Id
 }
 
+// Participants returns the participants in the virtual channel being funded.
+func (o *Objective) Participants() []types.Address {
+	return o.V.Participants
+}
+
 // GetStatus returns the status of the objective.
 func (o *Objective) GetStatus() protocols.ObjectiveStatus {
 	return o.Status
 }
 
+// GetVersion returns the version of the objective's protocol negotiated by its participants, or zero if none has been recorded.
+func (o *Objective) GetVersion() uint {
+	return o.Version
+}
+
+// WithVersion returns a copy of the objective which records the negotiated version of its protocol.
+func (o *Objective) WithVersion(version uint) protocols.Objective {
+	updated := o.clone()
+	updated.Version = version
+	return &updated
+}
+
+// SafeToAbandon returns true if none of our ledger channels includes, or has a proposal to include, the guarantee for V.
+func (o *Objective) SafeToAbandon() bool {
+	for _, c := range []*Connection{o.ToMyLeft, o.ToMyRight} {
+		if c == nil {
+			continue
+		}
+		g := c.getExpectedGuarantee()
+		if c.Channel.Includes(g) {
+			return false
+		}
+		proposed, err := c.Channel.IsProposed(g)
+		if err != nil || proposed {
+			return false
+		}
+	}
+	return true
+}
+
 func (o *Objective) otherParticipants() []types.Address {
 	otherParticipants := make([]types.Address, 0)
 	for i, p := range o.V.Participants {
@@ -399,7 +436,7 @@ func (o *Objective) Update(raw protocols.ObjectivePayload) (protocols.Objective,
 // Crank inspects the extended state and declares a list of Effects to be executed
 // It's like a state machine transition function where the finite / enumerable state is returned (computed from the extended state)
 // rather than being independent of the extended state; and where there is only one type of event ("the crank") with no data on it at all.
-func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.SideEffects, protocols.WaitingFor, error) {
+func (o *Objective) Crank(signer crypto.Signer) (protocols.Objective, protocols.SideEffects, protocols.WaitingFor, error) {
 	updated := o.clone()
 
 	sideEffects := protocols.SideEffects{}
@@ -411,7 +448,7 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 	// Prefunding
 
 	if !updated.V.PreFundSignedByMe() {
-		ss, err := updated.V.SignAndAddPrefund(secretKey)
+		ss, err := updated.V.SignAndAddPrefund(signer)
 		if err != nil {
 			return o, protocols.SideEffects{}, WaitingForNothing, err
 		}
@@ -428,7 +465,7 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 
 	if !updated.isAlice() && !updated.ToMyLeft.IsFundingTheTarget() {
 
-		ledgerSideEffects, err := updated.updateLedgerWithGuarantee(*updated.ToMyLeft, secretKey)
+		ledgerSideEffects, err := updated.updateLedgerWithGuarantee(*updated.ToMyLeft, signer)
 		if err != nil {
 			return o, protocols.SideEffects{}, WaitingForNothing, fmt.Errorf("error updating ledger funding: %w", err)
 		}
@@ -436,7 +473,7 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 	}
 
 	if !updated.isBob() && !updated.ToMyRight.IsFundingTheTarget() {
-		ledgerSideEffects, err := updated.updateLedgerWithGuarantee(*updated.ToMyRight, secretKey)
+		ledgerSideEffects, err := updated.updateLedgerWithGuarantee(*updated.ToMyRight, signer)
 		if err != nil {
 			return o, protocols.SideEffects{}, WaitingForNothing, fmt.Errorf("error updating ledger funding: %w", err)
 		}
@@ -449,7 +486,7 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 
 	// Postfunding
 	if !updated.V.PostFundSignedByMe() {
-		ss, err := updated.V.SignAndAddPostfund(secretKey)
+		ss, err := updated.V.SignAndAddPostfund(signer)
 		if err != nil {
 			return o, protocols.SideEffects{}, WaitingForNothing, err
 		}
@@ -505,6 +542,7 @@ func (o *Objective) fundingComplete() bool {
 func (o *Objective) clone() Objective {
 	clone := Objective{}
 	clone.Status = o.Status
+	clone.Version = o.Version
 	vClone := o.V.Clone()
 	clone.V = vClone
 
@@ -542,9 +580,9 @@ func (o *Objective) isBob() bool {
 	return o.MyRole == o.n+1
 }
 
-// GetTwoPartyConsensusLedgerFuncion describes functions which return a ConsensusChannel ledger channel between
+// GetTwoPartyConsensusLedgerFuncion describes functions which return a ConsensusChannel ledger channel on the given chain between
 // the calling client and the given counterparty, if such a channel exists.
-type GetTwoPartyConsensusLedgerFunction func(counterparty types.Address) (ledger *consensus_channel.ConsensusChannel, ok bool)
+type GetTwoPartyConsensusLedgerFunction func(counterparty types.Address, chainId *big.Int) (ledger *consensus_channel.ConsensusChannel, ok bool)
 
 // ConstructObjectiveFromPayload takes in a message and constructs an objective from it.
 // It accepts the message, myAddress, and a function to to retrieve ledgers from a store.
@@ -560,6 +598,7 @@ func ConstructObjectiveFromPayload(
 	}
 
 	participants := initialState.State().Participants
+	chainId := initialState.State().ChainId
 
 	var leftC *consensus_channel.ConsensusChannel
 	var rightC *consensus_channel.ConsensusChannel
@@ -574,7 +613,7 @@ func ConstructObjectiveFromPayload(
 
 		// I am Bob
 		leftOfBob := participants[len(participants)-2]
-		leftC, ok = getTwoPartyConsensusLedger(leftOfBob)
+		leftC, ok = getTwoPartyConsensusLedger(leftOfBob, chainId)
 		if !ok {
 			return Objective{}, fmt.Errorf("could not find a left ledger channel between %v and %v", leftOfBob, myAddress)
 		}
@@ -592,12 +631,12 @@ func ConstructObjectiveFromPayload(
 				leftOfMe := participants[p-1]
 				rightOfMe := participants[p+1]
 
-				leftC, ok = getTwoPartyConsensusLedger(leftOfMe)
+				leftC, ok = getTwoPartyConsensusLedger(leftOfMe, chainId)
 				if !ok {
 					return Objective{}, fmt.Errorf("could not find a left ledger channel between %v and %v", leftOfMe, myAddress)
 				}
 
-				rightC, ok = getTwoPartyConsensusLedger(rightOfMe)
+				rightC, ok = getTwoPartyConsensusLedger(rightOfMe, chainId)
 				if !ok {
 					return Objective{}, fmt.Errorf("could not find a right ledger channel between %v and %v", myAddress, rightOfMe)
 				}
@@ -639,7 +678,7 @@ func (c *Connection) expectedProposal() consensus_channel.Proposal {
 }
 
 // proposeLedgerUpdate will propose a ledger update to the channel by crafting a new state
-func (o *Objective) proposeLedgerUpdate(connection Connection, sk *[]byte) (protocols.SideEffects, error) {
+func (o *Objective) proposeLedgerUpdate(connection Connection, signer crypto.Signer) (protocols.SideEffects, error) {
 	ledger := connection.Channel
 
 	if !ledger.IsLeader() {
@@ -648,7 +687,7 @@ func (o *Objective) proposeLedgerUpdate(connection Connection, sk *[]byte) (prot
 
 	sideEffects := protocols.SideEffects{}
 
-	_, err := ledger.Propose(connection.expectedProposal(), *sk)
+	_, err := ledger.Propose(connection.expectedProposal(), signer)
 	if err != nil {
 		return protocols.SideEffects{}, err
 	}
@@ -662,9 +701,9 @@ func (o *Objective) proposeLedgerUpdate(connection Connection, sk *[]byte) (prot
 }
 
 // acceptLedgerUpdate checks for a ledger state proposal and accepts that proposal if it satisfies the expected guarantee.
-func (o *Objective) acceptLedgerUpdate(c Connection, sk *[]byte) (protocols.SideEffects, error) {
+func (o *Objective) acceptLedgerUpdate(c Connection, signer crypto.Signer) (protocols.SideEffects, error) {
 	ledger := c.Channel
-	sp, err := ledger.SignNextProposal(c.expectedProposal(), *sk)
+	sp, err := ledger.SignNextProposal(c.expectedProposal(), signer)
 
 	if err != nil {
 		return protocols.SideEffects{}, fmt.Errorf("no proposed state found for ledger channel %w", err)
@@ -686,7 +725,7 @@ func (o *Objective) acceptLedgerUpdate(c Connection, sk *[]byte) (protocols.Side
 // updateLedgerWithGuarantee updates the ledger channel funding to include the guarantee.
 // If the user is the proposer a new ledger state will be created and signed.
 // If the user is the follower then they will sign a ledger state proposal if it satisfies their expected guarantees.
-func (o *Objective) updateLedgerWithGuarantee(ledgerConnection Connection, sk *[]byte) (protocols.SideEffects, error) {
+func (o *Objective) updateLedgerWithGuarantee(ledgerConnection Connection, signer crypto.Signer) (protocols.SideEffects, error) {
 
 	ledger := ledgerConnection.Channel
 
@@ -701,7 +740,7 @@ func (o *Objective) updateLedgerWithGuarantee(ledgerConnection Connection, sk *[
 		if proposed {
 			return protocols.SideEffects{}, nil
 		}
-		se, err := o.proposeLedgerUpdate(ledgerConnection, sk)
+		se, err := o.proposeLedgerUpdate(ledgerConnection, signer)
 		if err != nil {
 			return protocols.SideEffects{}, fmt.Errorf("error proposing ledger update: %w", err)
 		}
@@ -714,7 +753,7 @@ func (o *Objective) updateLedgerWithGuarantee(ledgerConnection Connection, sk *[
 		proposedNext, _ := ledger.IsProposedNext(g)
 		if proposedNext {
 
-			se, err := o.acceptLedgerUpdate(ledgerConnection, sk)
+			se, err := o.acceptLedgerUpdate(ledgerConnection, signer)
 			if err != nil {
 				return protocols.SideEffects{}, fmt.Errorf("error proposing ledger update: %w", err)
 			}
@@ -728,6 +767,7 @@ func (o *Objective) updateLedgerWithGuarantee(ledgerConnection Connection, sk *[
 
 // ObjectiveRequest represents a request to create a new virtual funding objective.
 type ObjectiveRequest struct {
+	ChainId           *big.Int // the chain of the ledger channels which fund the virtual channel
 	Intermediaries    []types.Address
 	CounterParty      types.Address
 	ChallengeDuration uint32
@@ -763,7 +803,7 @@ func (r ObjectiveRequest) channelID(myAddress types.Address) types.Destination {
 	participants = append(participants, r.Intermediaries...)
 	participants = append(participants, r.CounterParty)
 
-	fixedPart := state.FixedPart{ChainId: big.NewInt(9001), // TODO https://github.com/statechannels/go-nitro/issues/601
+	fixedPart := state.FixedPart{ChainId: r.ChainId,
 		Participants:      participants,
 		ChannelNonce:      r.Nonce,
 		ChallengeDuration: r.ChallengeDuration}
@@ -222,7 +222,7 @@ func TestCrankAsAlice(t *testing.T) {
 		s, _     = constructFromState(false, vPreFund, my.Address(), ledgers[my.Destination()].left, ledgers[my.Destination()].right)
 	)
 	// Assert that cranking an unapproved objective returns an error
-	_, _, _, err := s.Crank(&my.PrivateKey)
+	_, _, _, err := s.Crank(my.Signer())
 	Assert(t, err != nil, `Expected error when cranking unapproved objective, but got nil`)
 
 	// Approve the objective, so that the rest of the test cases can run.
@@ -234,7 +234,7 @@ func TestCrankAsAlice(t *testing.T) {
 	// need to remember to convert the result back to a virtualfund.Objective struct
 
 	// Initial Crank
-	oObj, effects, waitingFor, err := o.Crank(&my.PrivateKey)
+	oObj, effects, waitingFor, err := o.Crank(my.Signer())
 	o = oObj.(*Objective)
 
 	expectedSignedState := state.NewSignedState(o.V.PreFundState())
@@ -245,6 +245,7 @@ func TestCrankAsAlice(t *testing.T) {
 	Equals(t, waitingFor, WaitingForCompletePrefund)
 	assertStateSentTo(t, effects, expectedSignedState, bob)
 	assertStateSentTo(t, effects, expectedSignedState, p1)
+	Assert(t, o.SafeToAbandon(), "Expected objective to be safe to abandon before proposing a ledger update")
 
 	// Update the objective with prefund signatures
 	c := cloneAndSignSetupStateByPeers(*o.V, my.Role, true)
@@ -258,7 +259,7 @@ func TestCrankAsAlice(t *testing.T) {
 
 	// Cranking should move us to the next waiting point, update the ledger channel, and alter the extended state to reflect that
 	// TODO: Check that ledger channel is updated as expected
-	oObj, effects, waitingFor, err = o.Crank(&my.PrivateKey)
+	oObj, effects, waitingFor, err = o.Crank(my.Signer())
 	o = oObj.(*Objective)
 
 	p := consensus_channel.NewAddProposal(o.ToMyRight.Channel.Id, o.ToMyRight.getExpectedGuarantee(), big.NewInt(6))
@@ -266,10 +267,11 @@ func TestCrankAsAlice(t *testing.T) {
 	Ok(t, err)
 	assertOneProposalSent(t, effects, sp, p1)
 	Equals(t, waitingFor, WaitingForCompleteFunding)
+	Assert(t, !o.SafeToAbandon(), "Expected objective not to be safe to abandon after proposing a ledger update")
 
 	// Check idempotency
 	emptySideEffects := protocols.SideEffects{}
-	oObj, effects, waitingFor, err = o.Crank(&my.PrivateKey)
+	oObj, effects, waitingFor, err = o.Crank(my.Signer())
 	o = oObj.(*Objective)
 	Ok(t, err)
 	Equals(t, effects, emptySideEffects)
@@ -282,7 +284,7 @@ func TestCrankAsAlice(t *testing.T) {
 	o = oObj.(*Objective)
 	Ok(t, err)
 
-	oObj, effects, waitingFor, err = o.Crank(&my.PrivateKey)
+	oObj, effects, waitingFor, err = o.Crank(my.Signer())
 	o = oObj.(*Objective)
 
 	postFS := state.NewSignedState(o.V.PostFundState())
@@ -304,7 +306,7 @@ func TestCrankAsBob(t *testing.T) {
 		s, _     = constructFromState(false, vPreFund, my.Address(), ledgers[my.Destination()].left, ledgers[my.Destination()].right)
 	)
 	// Assert that cranking an unapproved objective returns an error
-	_, _, _, err := s.Crank(&my.PrivateKey)
+	_, _, _, err := s.Crank(my.Signer())
 	Assert(t, err != nil, `Expected error when cranking unapproved objective, but got nil`)
 
 	// Approve the objective, so that the rest of the test cases can run.
@@ -316,7 +318,7 @@ func TestCrankAsBob(t *testing.T) {
 	// need to remember to convert the result back to a virtualfund.Objective struct
 
 	// Initial Crank
-	oObj, effects, waitingFor, err := o.Crank(&my.PrivateKey)
+	oObj, effects, waitingFor, err := o.Crank(my.Signer())
 	o = oObj.(*Objective)
 
 	expectedSignedState := state.NewSignedState(o.V.PreFundState())
@@ -340,7 +342,7 @@ func TestCrankAsBob(t *testing.T) {
 
 	// Cranking should move us to the next waiting point, update the ledger channel, and alter the extended state to reflect that
 	// TODO: Check that ledger channel is updated as expected
-	oObj, effects, waitingFor, err = o.Crank(&my.PrivateKey)
+	oObj, effects, waitingFor, err = o.Crank(my.Signer())
 	o = oObj.(*Objective)
 
 	emptySideEffects := protocols.SideEffects{}
@@ -349,7 +351,7 @@ func TestCrankAsBob(t *testing.T) {
 	Equals(t, waitingFor, WaitingForCompleteFunding)
 
 	// Check idempotency
-	oObj, effects, waitingFor, err = o.Crank(&my.PrivateKey)
+	oObj, effects, waitingFor, err = o.Crank(my.Signer())
 	o = oObj.(*Objective)
 	Ok(t, err)
 	Equals(t, effects, emptySideEffects)
@@ -363,7 +365,7 @@ func TestCrankAsBob(t *testing.T) {
 	o = oObj.(*Objective)
 	Ok(t, err)
 
-	oObj, effects, waitingFor, err = o.Crank(&my.PrivateKey)
+	oObj, effects, waitingFor, err = o.Crank(my.Signer())
 	o = oObj.(*Objective)
 
 	postFS := state.NewSignedState(o.V.PostFundState())
@@ -388,7 +390,7 @@ func TestCrankAsP1(t *testing.T) {
 		s, _     = constructFromState(false, vPreFund, my.Address(), left, right)
 	)
 	// Assert that cranking an unapproved objective returns an error
-	_, _, _, err := s.Crank(&my.PrivateKey)
+	_, _, _, err := s.Crank(my.Signer())
 	Assert(t, err != nil, `Expected error when cranking unapproved objective, but got nil`)
 
 	// Approve the objective, so that the rest of the test cases can run.
@@ -400,7 +402,7 @@ func TestCrankAsP1(t *testing.T) {
 	// need to remember to convert the result back to a virtualfund.Objective struct
 
 	// Initial Crank
-	oObj, effects, waitingFor, err := o.Crank(&my.PrivateKey)
+	oObj, effects, waitingFor, err := o.Crank(my.Signer())
 	o = oObj.(*Objective)
 
 	expectedSignedState := state.NewSignedState(o.V.PreFundState())
@@ -422,7 +424,7 @@ func TestCrankAsP1(t *testing.T) {
 	assertSupportedPrefund(o, t)
 
 	// Cranking should move us to the next waiting point, update the ledger channel, and alter the extended state to reflect that
-	oObj, effects, waitingFor, err = o.Crank(&my.PrivateKey)
+	oObj, effects, waitingFor, err = o.Crank(my.Signer())
 	o = oObj.(*Objective)
 
 	p := consensus_channel.NewAddProposal(o.ToMyLeft.Channel.Id, o.ToMyLeft.getExpectedGuarantee(), big.NewInt(6))
@@ -433,7 +435,7 @@ func TestCrankAsP1(t *testing.T) {
 
 	// Check idempotency
 	emptySideEffects := protocols.SideEffects{}
-	oObj, effects, waitingFor, err = o.Crank(&my.PrivateKey)
+	oObj, effects, waitingFor, err = o.Crank(my.Signer())
 	o = oObj.(*Objective)
 	Ok(t, err)
 	Equals(t, effects, emptySideEffects)
@@ -447,7 +449,7 @@ func TestCrankAsP1(t *testing.T) {
 	o = oObj.(*Objective)
 	Ok(t, err)
 
-	oObj, effects, waitingFor, err = o.Crank(&my.PrivateKey)
+	oObj, effects, waitingFor, err = o.Crank(my.Signer())
 	o = oObj.(*Objective)
 
 	postFS := state.NewSignedState(o.V.PostFundState())
@@ -137,11 +137,17 @@ func TestApproveReject(t *testing.T) {
 	if approved.GetStatus() != protocols.Approved {
 		t.Errorf("Expected approved status, got %v", approved.GetStatus())
 	}
-	rejected, sideEffects := o.Reject()
+	reason := protocols.RejectionReason{Code: protocols.CounterpartyNotAllowed, Message: "test"}
+	rejected, sideEffects := o.Reject(reason)
 	if rejected.GetStatus() != protocols.Rejected {
 		t.Errorf("Expected rejceted status, got %v", approved.GetStatus())
 	}
 	if len(sideEffects.MessagesToSend) != 2 {
 		t.Errorf("Expected to send two messages")
 	}
+	for _, msg := range sideEffects.MessagesToSend {
+		if len(msg.RejectedObjectives) != 1 || msg.RejectedObjectives[0].Reason != reason {
+			t.Errorf("Expected rejection notice with reason %v, got %v", reason, msg.RejectedObjectives)
+		}
+	}
 }